@@ -2,21 +2,40 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/joho/godotenv"
+	"google.golang.org/adk/session"
+	"google.golang.org/grpc"
 
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/agent"
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/config"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/dispatch"
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/handler"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/logging"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/metrics"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/monitor"
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/registry"
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/server"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/sessionstore"
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/telemetry"
 	"github.com/lunarr-ai/lunarr/agent-broker/pkg/embedding"
+	"github.com/lunarr-ai/lunarr/agent-broker/pkg/llm"
 )
 
+// embeddingProbeTimeout bounds the startup embedding dimension check.
+const embeddingProbeTimeout = 30 * time.Second
+
+// tracingShutdownTimeout bounds flushing pending spans on exit.
+const tracingShutdownTimeout = 5 * time.Second
+
 func main() {
 	if err := run(); err != nil {
 		os.Exit(1)
@@ -32,60 +51,252 @@ func run() error {
 	logger.Info("starting agent-broker",
 		"port", cfg.Port,
 		"log_level", cfg.LogLevel.String(),
+		"store_backend", cfg.StoreBackend,
 		"qdrant_host", cfg.QdrantHost,
 		"qdrant_port", cfg.QdrantPort,
 		"embedding_url", cfg.EmbeddingURL,
 		"embedding_dim", cfg.EmbeddingDim,
+		"embedding_model", cfg.EmbeddingModel,
+		"embedding_cache_size", cfg.EmbeddingCacheSize,
+		"llm_provider", cfg.LLMProvider,
+		"forward_enabled", cfg.ForwardEnabled,
+		"health_check_enabled", cfg.HealthCheckEnabled,
+		"health_policy", cfg.HealthPolicy,
+		"discover_fusion", cfg.DiscoverFusion,
+		"discover_min_score", cfg.DiscoverMinScore,
+		"discover_min_relative_score", cfg.DiscoverMinRelativeScore,
+		"session_store", cfg.SessionStore,
+		"metrics_enabled", cfg.MetricsEnabled,
+		"tracing_exporter", cfg.TracingExporter,
 	)
 
 	ctx := context.Background()
 
+	shutdownTracing, err := telemetry.Setup(ctx,
+		telemetry.WithServiceName(cfg.TracingServiceName),
+		telemetry.WithExporter(telemetry.Exporter(cfg.TracingExporter)),
+		telemetry.WithProtocol(telemetry.Protocol(cfg.TracingProtocol)),
+		telemetry.WithEndpoint(cfg.TracingEndpoint),
+		telemetry.WithInsecure(cfg.TracingInsecure),
+		telemetry.WithFile(cfg.TracingFile),
+		telemetry.WithSampleRatio(cfg.TracingSampleRatio),
+	)
+	if err != nil {
+		logger.Error("failed to set up tracing", "error", err)
+		return err
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			logger.Error("failed to flush traces", "error", err)
+		}
+	}()
+	tracingEnabled := telemetry.Exporter(cfg.TracingExporter) != telemetry.ExporterNone
+
+	// A nil *metrics.Metrics records nothing.
+	var brokerMetrics *metrics.Metrics
+	if cfg.MetricsEnabled {
+		brokerMetrics = metrics.New(metrics.WithLogger(logger))
+	}
+
 	// Create embedder with configured dimension
-	embedder := embedding.NewClient(cfg.EmbeddingURL, cfg.EmbeddingDim)
-
-	// Create Qdrant store with configured dimension
-	qdrantStore, err := store.NewQdrantStore(ctx,
-		store.WithHost(cfg.QdrantHost),
-		store.WithPort(cfg.QdrantPort),
-		store.WithAPIKey(cfg.QdrantAPIKey),
-		store.WithTLS(cfg.QdrantUseTLS),
-		store.WithVectorDimension(uint64(cfg.EmbeddingDim)),
+	embedder := embedding.NewCachedEmbedder(
+		brokerMetrics.InstrumentEmbedder(embedding.NewClient(cfg.EmbeddingURL, cfg.EmbeddingDim,
+			embedding.WithModel(cfg.EmbeddingModel),
+			embedding.WithRetries(cfg.EmbeddingMaxRetries),
+			embedding.WithBackoff(cfg.EmbeddingRetryBackoff, cfg.EmbeddingRetryMaxBackoff),
+			embedding.WithCircuitBreaker(cfg.EmbeddingBreakerThreshold, cfg.EmbeddingBreakerCooldown),
+		)),
+		embedding.WithCacheModel(cfg.EmbeddingModel),
+		embedding.WithCacheSize(cfg.EmbeddingCacheSize),
+		embedding.WithCacheTTL(cfg.EmbeddingCacheTTL),
+		embedding.WithBatchWindow(cfg.EmbeddingBatchWindow),
+		embedding.WithBatchTimeout(cfg.EmbeddingBatchTimeout),
+		embedding.WithMaxBatch(cfg.EmbeddingMaxBatch),
 	)
+	defer func() {
+		stats := embedder.Stats()
+		logger.Info("embedding cache stats",
+			"hits", stats.Hits,
+			"misses", stats.Misses,
+			"calls", stats.Calls,
+			"entries", stats.Entries,
+		)
+	}()
+
+	tracedEmbedder := telemetry.TraceEmbedder(embedder)
+
+	// Refuse to start if the embedder returns vectors of another size than the
+	// store is configured for; an unreachable embedder only skips the check.
+	probeCtx, cancelProbe := context.WithTimeout(ctx, embeddingProbeTimeout)
+	err = embedding.CheckDimension(probeCtx, tracedEmbedder)
+	cancelProbe()
+	switch {
+	case errors.Is(err, embedding.ErrDimensionMismatch):
+		logger.Error("embedding dimension check failed", "error", err)
+		return err
+	case err != nil:
+		logger.Warn("could not probe embedder, skipping dimension check", "error", err)
+	}
+
+	qdrantDialOpts := brokerMetrics.QdrantDialOptions()
+	if tracingEnabled {
+		qdrantDialOpts = append(qdrantDialOpts, telemetry.GRPCDialOptions()...)
+	}
+	agentStore, err := openStore(ctx, cfg, qdrantDialOpts...)
 	if err != nil {
-		logger.Error("failed to connect to qdrant", "error", err)
+		logger.Error("failed to open agent store", "backend", cfg.StoreBackend, "error", err)
 		return err
 	}
 	defer func() {
-		if err := qdrantStore.Close(); err != nil {
-			logger.Error("failed to close qdrant connection", "error", err)
+		if err := agentStore.Close(); err != nil {
+			logger.Error("failed to close agent store", "error", err)
 		}
 	}()
-	logger.Info("connected to qdrant")
+	logger.Info("opened agent store", "backend", cfg.StoreBackend)
+
+	fusion := registry.Fusion{
+		Method:         registry.FusionMethod(cfg.DiscoverFusion),
+		SemanticWeight: float32(cfg.DiscoverSemanticWeight),
+		LexicalWeight:  float32(cfg.DiscoverLexicalWeight),
+	}
+	if err := fusion.Validate(); err != nil {
+		logger.Error("invalid discovery ranking config", "error", err)
+		return err
+	}
+	thresholds := registry.Thresholds{
+		MinScore:         float32(cfg.DiscoverMinScore),
+		MinRelativeScore: float32(cfg.DiscoverMinRelativeScore),
+	}
+	if err := thresholds.Validate(); err != nil {
+		logger.Error("invalid discovery threshold config", "error", err)
+		return err
+	}
+
+	healthPolicy := registry.HealthPolicy(cfg.HealthPolicy)
+	if err := healthPolicy.Validate(); err != nil {
+		logger.Error("invalid health policy config", "error", err)
+		return err
+	}
 
-	registryService := registry.NewRegistryService(qdrantStore, registry.WithEmbedder(embedder))
+	registryService := registry.NewRegistryService(agentStore,
+		registry.WithEmbedder(tracedEmbedder),
+		registry.WithHealthPolicy(healthPolicy),
+		registry.WithFusion(fusion),
+		registry.WithThresholds(thresholds),
+		registry.WithEmbeddingModel(cfg.EmbeddingModel),
+		registry.WithMetrics(brokerMetrics),
+	)
+	brokerMetrics.RegisterAgentCounts(registryService.CountAgents)
 
-	brokerAgent, err := agent.NewBrokerAgent(ctx, registryService,
+	// The health monitor and lease reaper write to the store, so they are
+	// stopped and awaited before the store is closed.
+	backgroundCtx, stopBackground := context.WithCancel(ctx)
+	var background sync.WaitGroup
+	defer func() {
+		stopBackground()
+		background.Wait()
+	}()
+
+	if cfg.HealthCheckEnabled {
+		healthMonitor := monitor.NewMonitor(agentStore,
+			monitor.WithLogger(logger),
+			monitor.WithInterval(cfg.HealthCheckInterval),
+			monitor.WithTimeout(cfg.HealthCheckTimeout),
+			monitor.WithFailureThreshold(cfg.HealthCheckFailureThreshold),
+		)
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			healthMonitor.Run(backgroundCtx)
+		}()
+	}
+
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		registryService.RunReaper(backgroundCtx, cfg.LeaseReapInterval, logger)
+	}()
+
+	agentOpts := []agent.Option{
 		agent.WithGeminiAPIKey(cfg.GeminiAPIKey),
 		agent.WithGeminiModel(cfg.GeminiModel),
-	)
+		agent.WithMetrics(brokerMetrics),
+	}
+	switch cfg.LLMProvider {
+	case "gemini":
+	case "openai":
+		agentOpts = append(agentOpts, agent.WithModel(llm.NewOpenAIModel(cfg.LLMURL, cfg.LLMModel,
+			llm.WithAPIKey(cfg.LLMAPIKey),
+		)))
+	default:
+		err := fmt.Errorf("unknown llm provider %q", cfg.LLMProvider)
+		logger.Error("invalid config", "error", err)
+		return err
+	}
+	if cfg.ForwardEnabled {
+		agentOpts = append(agentOpts, agent.WithDispatcher(dispatch.NewDispatcher(
+			dispatch.WithTimeout(cfg.ForwardTimeout),
+			dispatch.WithBroadcastTimeout(cfg.BroadcastTimeout),
+		)))
+	}
+
+	brokerAgent, err := agent.NewBrokerAgent(ctx, registryService, agentOpts...)
 	if err != nil {
 		logger.Error("failed to create broker agent", "error", err)
 		return err
 	}
 
-	sessionService := agent.NewSessionService()
+	var sessionService session.Service
+	switch cfg.SessionStore {
+	case "memory":
+		sessionService = agent.NewSessionService()
+	case "bolt":
+		boltSessions, err := sessionstore.NewBoltService(cfg.SessionPath,
+			sessionstore.WithMaxEvents(cfg.SessionMaxEvents),
+		)
+		if err != nil {
+			logger.Error("failed to open session store", "error", err)
+			return err
+		}
+		defer func() {
+			if err := boltSessions.Close(); err != nil {
+				logger.Error("failed to close session store", "error", err)
+			}
+		}()
+		sessionService = boltSessions
+	default:
+		err := fmt.Errorf("unknown session store %q", cfg.SessionStore)
+		logger.Error("invalid config", "error", err)
+		return err
+	}
 
 	mux := http.NewServeMux()
 
 	handler.NewBrokerHandler(brokerAgent, sessionService).RegisterRoutes(mux)
-	handler.NewHealthHandler(qdrantStore).RegisterRoutes(mux)
+	handler.NewHealthHandler(agentStore).RegisterRoutes(mux)
 	handler.NewAdminHandler(registryService).RegisterRoutes(mux)
 	handler.NewAgentsHandler(registryService).RegisterRoutes(mux)
+	if brokerMetrics != nil {
+		mux.Handle("GET /metrics", brokerMetrics.Handler())
+	}
 
-	srv := server.New(mux,
+	serverOpts := []server.Option{
 		server.WithPort(cfg.Port),
 		server.WithLogger(logger),
-	)
+		server.WithMetrics(brokerMetrics),
+		server.WithTracing(tracingEnabled),
+	}
+	if authenticators := adminAuthenticators(cfg); len(authenticators) > 0 {
+		serverOpts = append(serverOpts, server.WithMiddleware(
+			server.AuthMiddleware(server.AdminScopePolicy, authenticators...),
+		))
+	} else {
+		logger.Warn("admin API authentication disabled, set ADMIN_API_KEYS or ADMIN_JWT_SECRET to enable")
+	}
+
+	srv := server.New(mux, serverOpts...)
 
 	if err := srv.Run(ctx); err != nil {
 		logger.Error("server error", "error", err)
@@ -95,11 +306,80 @@ func run() error {
 	return nil
 }
 
+// openStore opens the agent store selected by cfg.StoreBackend. dialOpts
+// instrument the Qdrant connection.
+func openStore(ctx context.Context, cfg *config.Config, dialOpts ...grpc.DialOption) (store.Store, error) {
+	switch cfg.StoreBackend {
+	case "qdrant":
+		// Create Qdrant store with configured dimension
+		return store.NewQdrantStore(ctx,
+			store.WithHost(cfg.QdrantHost),
+			store.WithPort(cfg.QdrantPort),
+			store.WithAPIKey(cfg.QdrantAPIKey),
+			store.WithTLS(cfg.QdrantUseTLS),
+			store.WithVectorDimension(uint64(cfg.EmbeddingDim)),
+			store.WithEmbeddingModel(cfg.EmbeddingModel),
+			store.WithEmbeddingCheck(cfg.EmbeddingCheckEnabled),
+			store.WithDialOptions(dialOpts...),
+		)
+	case "bolt":
+		return store.NewBoltStore(cfg.StorePath,
+			store.WithBoltVectorDimension(cfg.EmbeddingDim),
+			store.WithBoltEmbeddingModel(cfg.EmbeddingModel),
+		)
+	case "memory":
+		return store.NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown store backend %q", cfg.StoreBackend)
+	}
+}
+
+// adminAuthenticators returns the authenticators configured for the admin API.
+func adminAuthenticators(cfg *config.Config) []server.Authenticator {
+	var authenticators []server.Authenticator
+
+	var keys []server.APIKey
+	for i, key := range cfg.AdminAPIKeys {
+		keys = append(keys, server.APIKey{
+			Name:   fmt.Sprintf("api-key-%d", i+1),
+			Key:    key,
+			Scopes: []string{server.ScopeRegistryRead, server.ScopeRegistryWrite},
+		})
+	}
+	for i, key := range cfg.AdminReadOnlyAPIKeys {
+		keys = append(keys, server.APIKey{
+			Name:   fmt.Sprintf("readonly-api-key-%d", i+1),
+			Key:    key,
+			Scopes: []string{server.ScopeRegistryRead},
+		})
+	}
+	for i, key := range cfg.AdminLeaseAPIKeys {
+		keys = append(keys, server.APIKey{
+			Name:   fmt.Sprintf("lease-api-key-%d", i+1),
+			Key:    key,
+			Scopes: []string{server.ScopeRegistryLease},
+		})
+	}
+	if len(keys) > 0 {
+		authenticators = append(authenticators, server.NewAPIKeyAuthenticator(keys...))
+	}
+
+	if cfg.AdminJWTSecret != "" {
+		authenticators = append(authenticators, server.NewJWTAuthenticator([]byte(cfg.AdminJWTSecret),
+			server.WithJWTIssuer(cfg.AdminJWTIssuer),
+			server.WithJWTAudience(cfg.AdminJWTAudience),
+		))
+	}
+
+	return authenticators
+}
+
 func setupLogger(level slog.Level) *slog.Logger {
 	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 		Level: level,
 	})
-	logger := slog.New(handler)
+	// Attach request and A2A task IDs from the context to every record.
+	logger := slog.New(logging.NewContextHandler(handler))
 	slog.SetDefault(logger)
 	return logger
 }
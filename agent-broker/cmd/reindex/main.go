@@ -0,0 +1,117 @@
+// Command reindex re-embeds every registered agent with the configured
+// embedding model into a new Qdrant collection and switches the collection
+// alias to it. Run it after changing the embedding model or dimension; an
+// interrupted run resumes from its last checkpoint when started again.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joho/godotenv"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/config"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/registry"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+	"github.com/lunarr-ai/lunarr/agent-broker/pkg/embedding"
+)
+
+func main() {
+	if err := run(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	batchSize := flag.Int("batch-size", 32, "number of agents embedded per request")
+	flag.Parse()
+
+	_ = godotenv.Load()
+
+	cfg := config.Load()
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: cfg.LogLevel}))
+
+	if cfg.StoreBackend != "qdrant" {
+		err := errors.New("reindex requires the qdrant store backend")
+		logger.Error("invalid config", "store_backend", cfg.StoreBackend, "error", err)
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	embedder := embedding.NewClient(cfg.EmbeddingURL, cfg.EmbeddingDim,
+		embedding.WithModel(cfg.EmbeddingModel),
+		embedding.WithRetries(cfg.EmbeddingMaxRetries),
+		embedding.WithBackoff(cfg.EmbeddingRetryBackoff, cfg.EmbeddingRetryMaxBackoff),
+		embedding.WithCircuitBreaker(cfg.EmbeddingBreakerThreshold, cfg.EmbeddingBreakerCooldown),
+	)
+	if err := embedding.CheckDimension(ctx, embedder); err != nil {
+		logger.Error("embedding dimension check failed", "error", err)
+		return err
+	}
+
+	// The collection is expected to have been built with another model, so
+	// it is opened without checking its embedding.
+	agentStore, err := store.NewQdrantStore(ctx,
+		store.WithHost(cfg.QdrantHost),
+		store.WithPort(cfg.QdrantPort),
+		store.WithAPIKey(cfg.QdrantAPIKey),
+		store.WithTLS(cfg.QdrantUseTLS),
+		store.WithVectorDimension(uint64(cfg.EmbeddingDim)),
+		store.WithEmbeddingModel(cfg.EmbeddingModel),
+		store.WithEmbeddingCheck(false),
+	)
+	if err != nil {
+		logger.Error("failed to open agent store", "error", err)
+		return err
+	}
+	defer func() {
+		if err := agentStore.Close(); err != nil {
+			logger.Error("failed to close agent store", "error", err)
+		}
+	}()
+
+	registryService := registry.NewRegistryService(agentStore,
+		registry.WithEmbedder(embedder),
+		registry.WithEmbeddingModel(cfg.EmbeddingModel),
+	)
+
+	logger.Info("starting reindex",
+		"embedding_model", cfg.EmbeddingModel,
+		"embedding_dim", cfg.EmbeddingDim,
+		"batch_size", *batchSize,
+	)
+	status, err := registryService.Reindex(ctx, registry.ReindexInput{
+		BatchSize: *batchSize,
+		Progress: func(status registry.ReindexStatus) {
+			logger.Info("reindex progress",
+				"target", status.Target,
+				"copied", status.Copied,
+				"total", status.Total,
+				"resumed", status.Resumed,
+			)
+		},
+	})
+	if err != nil {
+		logger.Error("reindex failed, run again to resume",
+			"target", status.Target,
+			"copied", status.Copied,
+			"error", err,
+		)
+		return err
+	}
+
+	logger.Info("reindex completed",
+		"collection", status.Target,
+		"previous", status.Previous,
+		"agents", status.Copied,
+		"duration", status.FinishedAt.Sub(status.StartedAt),
+	)
+	return nil
+}
@@ -6,12 +6,15 @@ import (
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
 	"google.golang.org/adk/model/gemini"
 	"google.golang.org/adk/session"
 	"google.golang.org/adk/tool"
 	"google.golang.org/genai"
 
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/agent/tools"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/dispatch"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/metrics"
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/registry"
 )
 
@@ -25,10 +28,12 @@ const brokerInstruction = `You are the Lunarr Agent Broker, a meta-agent that he
 
 You have three capabilities:
 1. **discover**: Find agents matching a query. Use this to show users available agents for a topic.
-2. **route**: Find the single best agent for a specific task. Use this when a user needs to be directed to one agent.
-3. **broadcast**: Find multiple agents to send a request to. Use this when a task should go to several agents.
+2. **route**: Find the single best agent for a specific task. When forwarding is enabled, the request is sent to that agent and its answer is returned. Use this when a user needs to be directed to one agent.
+3. **broadcast**: Find multiple agents to send a request to. When forwarding is enabled, the request is sent to all of them concurrently and each agent's response, error or timeout is returned; summarize the answers and mention any agents that failed. Use this when a task should go to several agents.
 
-When users describe what they need, use the appropriate tool to find matching agents. Be helpful and explain the results clearly.`
+When users describe what they need, use the appropriate tool to find matching agents. Be helpful and explain the results clearly.
+
+If a tool returns no agents but lists near_misses, no agent matched confidently: do not present a near miss as the answer. Tell the user no suitable agent was found and mention the near misses as possible alternatives.`
 
 // Options configures the broker agent.
 type Options struct {
@@ -36,6 +41,14 @@ type Options struct {
 	GeminiAPIKey string
 	// GeminiModel is the model name to use.
 	GeminiModel string
+	// Model is the LLM backing the broker. If nil, a Gemini model is created
+	// from GeminiAPIKey and GeminiModel.
+	Model model.LLM
+	// Dispatcher forwards routed and broadcast requests to agents.
+	// If nil, route and broadcast only return agent cards.
+	Dispatcher *dispatch.Dispatcher
+	// Metrics counts tool invocations, nil to disable.
+	Metrics *metrics.Metrics
 }
 
 // DefaultOptions returns sensible defaults for broker options.
@@ -64,6 +77,27 @@ func WithGeminiModel(model string) Option {
 	}
 }
 
+// WithModel sets the LLM backing the broker, replacing the default Gemini model.
+func WithModel(m model.LLM) Option {
+	return func(o *Options) {
+		o.Model = m
+	}
+}
+
+// WithDispatcher sets the dispatcher used to forward routed and broadcast requests.
+func WithDispatcher(d *dispatch.Dispatcher) Option {
+	return func(o *Options) {
+		o.Dispatcher = d
+	}
+}
+
+// WithMetrics sets the metrics counting tool invocations.
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(o *Options) {
+		o.Metrics = m
+	}
+}
+
 // NewBrokerAgent creates a new ADK LLM agent for the broker.
 func NewBrokerAgent(ctx context.Context, reg *registry.RegistryService, opts ...Option) (agent.Agent, error) {
 	options := DefaultOptions()
@@ -71,24 +105,28 @@ func NewBrokerAgent(ctx context.Context, reg *registry.RegistryService, opts ...
 		opt(&options)
 	}
 
-	model, err := gemini.NewModel(ctx, options.GeminiModel, &genai.ClientConfig{
-		APIKey: options.GeminiAPIKey,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("create gemini model: %w", err)
+	llm := options.Model
+	if llm == nil {
+		geminiModel, err := gemini.NewModel(ctx, options.GeminiModel, &genai.ClientConfig{
+			APIKey: options.GeminiAPIKey,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create gemini model: %w", err)
+		}
+		llm = geminiModel
 	}
 
-	discoverTool, err := tools.NewDiscoverTool(reg)
+	discoverTool, err := tools.NewDiscoverTool(reg, options.Metrics)
 	if err != nil {
 		return nil, fmt.Errorf("create discover tool: %w", err)
 	}
 
-	routeTool, err := tools.NewRouteTool(reg)
+	routeTool, err := tools.NewRouteTool(reg, options.Dispatcher, options.Metrics)
 	if err != nil {
 		return nil, fmt.Errorf("create route tool: %w", err)
 	}
 
-	broadcastTool, err := tools.NewBroadcastTool(reg)
+	broadcastTool, err := tools.NewBroadcastTool(reg, options.Dispatcher, options.Metrics)
 	if err != nil {
 		return nil, fmt.Errorf("create broadcast tool: %w", err)
 	}
@@ -96,7 +134,7 @@ func NewBrokerAgent(ctx context.Context, reg *registry.RegistryService, opts ...
 	return llmagent.New(llmagent.Config{
 		Name:        brokerName,
 		Description: brokerDescription,
-		Model:       model,
+		Model:       llm,
 		Instruction: brokerInstruction,
 		Tools:       []tool.Tool{discoverTool, routeTool, broadcastTool},
 	})
@@ -4,15 +4,25 @@ import (
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/functiontool"
 
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/dispatch"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/metrics"
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/registry"
 )
 
 // NewBroadcastTool creates a tool for broadcasting to multiple agents.
-func NewBroadcastTool(reg *registry.RegistryService) (tool.Tool, error) {
-	return functiontool.New(
+// If disp is non-nil, the message is sent to every selected agent concurrently
+// and their responses are collected; otherwise only the agent cards are returned.
+// Invocations are counted in m if non-nil.
+func NewBroadcastTool(reg *registry.RegistryService, disp *dispatch.Dispatcher, m *metrics.Metrics) (tool.Tool, error) {
+	description := "Find multiple agents to broadcast a request to. Use this when a task should be sent to several relevant agents."
+	if disp != nil {
+		description = "Send a request to several relevant agents at once and collect each agent's response. Use this when a task should be handled by multiple agents."
+	}
+
+	return newTool(m,
 		functiontool.Config{
 			Name:        "broadcast",
-			Description: "Find multiple agents to broadcast a request to. Use this when a task should be sent to several relevant agents.",
+			Description: description,
 		},
 		func(ctx tool.Context, args BroadcastArgs) (BroadcastResult, error) {
 			limit := args.Limit
@@ -21,27 +31,76 @@ func NewBroadcastTool(reg *registry.RegistryService) (tool.Tool, error) {
 			}
 
 			result, err := reg.Discover(ctx, registry.DiscoverInput{
-				Query:  args.Query,
-				Limit:  limit,
-				Tags:   args.Tags,
-				Skills: args.Skills,
+				Query:            args.Query,
+				Limit:            limit,
+				Tags:             args.Tags,
+				Skills:           args.Skills,
+				MinScore:         args.MinScore,
+				MinRelativeScore: args.MinRelativeScore,
 			})
 			if err != nil {
 				return BroadcastResult{}, err
 			}
 
-			agents := make([]ScoredAgent, 0, len(result.Agents))
-			for _, scored := range result.Agents {
-				agents = append(agents, ScoredAgent{
-					Card:  scored.Agent.Card,
-					Score: scored.Score,
-				})
+			agents := newScoredAgents(result.Agents)
+			broadcastResult := BroadcastResult{
+				Agents:     agents,
+				Total:      len(agents),
+				NearMisses: newScoredAgents(result.NearMisses),
+			}
+			if disp == nil || len(agents) == 0 {
+				return broadcastResult, nil
+			}
+
+			message := args.Message
+			if message == "" {
+				message = userText(ctx)
+			}
+			if message == "" {
+				message = args.Query
+			}
+
+			targets := make([]dispatch.Target, len(agents))
+			for i := range agents {
+				targets[i] = dispatch.Target{
+					AgentID: agents[i].AgentID,
+					Card:    &agents[i].Card,
+				}
+			}
+
+			outcomes := disp.Broadcast(ctx, targets, message)
+			broadcastResult.Responses = make([]BroadcastResponse, len(outcomes))
+			for i, outcome := range outcomes {
+				response := BroadcastResponse{
+					AgentID:    outcome.AgentID,
+					AgentName:  agents[i].Card.Name,
+					Status:     string(outcome.Status),
+					DurationMs: outcome.Duration.Milliseconds(),
+				}
+
+				switch outcome.Status {
+				case dispatch.OutcomeOK:
+					broadcastResult.Succeeded++
+					response.Response = &AgentResponse{
+						TaskID: string(outcome.Response.TaskID),
+						State:  string(outcome.Response.State),
+						Text:   outcome.Response.Text,
+					}
+				case dispatch.OutcomeTimeout:
+					broadcastResult.TimedOut++
+					response.Error = outcome.Err.Error()
+				case dispatch.OutcomeCanceled:
+					broadcastResult.Canceled++
+					response.Error = outcome.Err.Error()
+				default:
+					broadcastResult.Failed++
+					response.Error = outcome.Err.Error()
+				}
+
+				broadcastResult.Responses[i] = response
 			}
 
-			return BroadcastResult{
-				Agents: agents,
-				Total:  len(agents),
-			}, nil
+			return broadcastResult, nil
 		},
 	)
 }
@@ -4,12 +4,14 @@ import (
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/functiontool"
 
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/metrics"
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/registry"
 )
 
 // NewDiscoverTool creates a tool for discovering agents by semantic search.
-func NewDiscoverTool(reg *registry.RegistryService) (tool.Tool, error) {
-	return functiontool.New(
+// Invocations are counted in m if non-nil.
+func NewDiscoverTool(reg *registry.RegistryService, m *metrics.Metrics) (tool.Tool, error) {
+	return newTool(m,
 		functiontool.Config{
 			Name:        "discover",
 			Description: "Find agents matching a natural language query. Returns a list of agents ranked by relevance.",
@@ -21,26 +23,22 @@ func NewDiscoverTool(reg *registry.RegistryService) (tool.Tool, error) {
 			}
 
 			result, err := reg.Discover(ctx, registry.DiscoverInput{
-				Query:  args.Query,
-				Limit:  limit,
-				Tags:   args.Tags,
-				Skills: args.Skills,
+				Query:            args.Query,
+				Limit:            limit,
+				Tags:             args.Tags,
+				Skills:           args.Skills,
+				MinScore:         args.MinScore,
+				MinRelativeScore: args.MinRelativeScore,
 			})
 			if err != nil {
 				return DiscoverResult{}, err
 			}
 
-			agents := make([]ScoredAgent, 0, len(result.Agents))
-			for _, scored := range result.Agents {
-				agents = append(agents, ScoredAgent{
-					Card:  scored.Agent.Card,
-					Score: scored.Score,
-				})
-			}
-
+			agents := newScoredAgents(result.Agents)
 			return DiscoverResult{
-				Agents: agents,
-				Total:  len(agents),
+				Agents:     agents,
+				Total:      len(agents),
+				NearMisses: newScoredAgents(result.NearMisses),
 			}, nil
 		},
 	)
@@ -1,42 +1,101 @@
 package tools
 
 import (
+	"strings"
+
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/functiontool"
 
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/dispatch"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/metrics"
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/registry"
 )
 
+// routeCandidates is how many agents the route tool ranks, so that near
+// misses can be reported when none is confident.
+const routeCandidates = 3
+
 // NewRouteTool creates a tool for routing to the best matching agent.
-func NewRouteTool(reg *registry.RegistryService) (tool.Tool, error) {
-	return functiontool.New(
+// If disp is non-nil, the message is forwarded to the selected agent and its
+// answer is returned; otherwise only the agent's card is returned.
+// Invocations are counted in m if non-nil.
+func NewRouteTool(reg *registry.RegistryService, disp *dispatch.Dispatcher, m *metrics.Metrics) (tool.Tool, error) {
+	description := "Find the single best agent for a task. Use this when you need to forward a request to the most relevant agent."
+	if disp != nil {
+		description = "Forward a request to the single best agent for a task and return its answer. Use this when one agent should handle the request."
+	}
+
+	return newTool(m,
 		functiontool.Config{
 			Name:        "route",
-			Description: "Find the single best agent for a task. Use this when you need to forward a request to the most relevant agent.",
+			Description: description,
 		},
 		func(ctx tool.Context, args RouteArgs) (RouteResult, error) {
 			result, err := reg.Discover(ctx, registry.DiscoverInput{
-				Query:  args.Query,
-				Limit:  1,
-				Tags:   args.Tags,
-				Skills: args.Skills,
+				Query:            args.Query,
+				Limit:            routeCandidates,
+				Tags:             args.Tags,
+				Skills:           args.Skills,
+				MinScore:         args.MinScore,
+				MinRelativeScore: args.MinRelativeScore,
 			})
 			if err != nil {
 				return RouteResult{}, err
 			}
 
 			if len(result.Agents) == 0 {
-				return RouteResult{Found: false}, nil
+				return RouteResult{
+					Found:      false,
+					Reason:     result.Reason(),
+					NearMisses: newScoredAgents(result.NearMisses),
+				}, nil
 			}
 
-			agent := result.Agents[0]
-			return RouteResult{
-				Agent: &ScoredAgent{
-					Card:  agent.Agent.Card,
-					Score: agent.Score,
-				},
+			agent := newScoredAgent(result.Agents[0])
+			routeResult := RouteResult{
+				Agent: &agent,
 				Found: true,
-			}, nil
+			}
+			if disp == nil {
+				return routeResult, nil
+			}
+
+			message := args.Message
+			if message == "" {
+				message = userText(ctx)
+			}
+			if message == "" {
+				message = args.Query
+			}
+
+			resp, err := disp.Send(ctx, dispatch.Target{AgentID: agent.AgentID, Card: &agent.Card}, message)
+			if err != nil {
+				routeResult.Error = err.Error()
+				return routeResult, nil
+			}
+
+			routeResult.Response = &AgentResponse{
+				TaskID: string(resp.TaskID),
+				State:  string(resp.State),
+				Text:   resp.Text,
+			}
+			return routeResult, nil
 		},
 	)
 }
+
+// userText returns the text of the user message that started the invocation.
+func userText(ctx tool.Context) string {
+	content := ctx.UserContent()
+	if content == nil {
+		return ""
+	}
+
+	var texts []string
+	for _, part := range content.Parts {
+		if part != nil && part.Text != "" {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
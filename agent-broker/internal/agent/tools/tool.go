@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/metrics"
+)
+
+// tracer creates the spans of tool invocations.
+var tracer = otel.Tracer("github.com/lunarr-ai/lunarr/agent-broker/internal/agent/tools")
+
+// newTool creates a function tool whose invocations are counted in m and
+// traced, so that discovery and forwarding spans nest under the tool call.
+func newTool[TArgs, TResults any](m *metrics.Metrics, cfg functiontool.Config, handler functiontool.Func[TArgs, TResults]) (tool.Tool, error) {
+	return functiontool.New(cfg, func(ctx tool.Context, args TArgs) (TResults, error) {
+		spanCtx, span := tracer.Start(ctx, "tool "+cfg.Name,
+			trace.WithAttributes(attribute.String("tool.name", cfg.Name)),
+		)
+		defer span.End()
+
+		result, err := handler(tracedContext{Context: ctx, ctx: spanCtx}, args)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		m.ObserveTool(cfg.Name, err)
+		return result, err
+	})
+}
+
+// tracedContext is a tool.Context carrying the span of the tool call. The
+// span context only adds a value, so deadline and cancellation are unchanged.
+type tracedContext struct {
+	tool.Context
+	// ctx is the tool context with the span.
+	ctx context.Context
+}
+
+// Value returns the value for key, including the tool call span.
+func (c tracedContext) Value(key any) any {
+	return c.ctx.Value(key)
+}
@@ -1,6 +1,10 @@
 package tools
 
-import "github.com/a2aproject/a2a-go/a2a"
+import (
+	"github.com/a2aproject/a2a-go/a2a"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+)
 
 // DiscoverArgs are the arguments for the discover tool.
 type DiscoverArgs struct {
@@ -12,36 +16,64 @@ type DiscoverArgs struct {
 	Tags []string `json:"tags,omitempty"`
 	// Skills filters by skill IDs.
 	Skills []string `json:"skills,omitempty"`
+	// MinScore overrides the minimum embedding similarity (0-1) an agent needs.
+	MinScore *float32 `json:"min_score,omitempty"`
+	// MinRelativeScore overrides the fraction (0-1) of the best score an agent needs.
+	MinRelativeScore *float32 `json:"min_relative_score,omitempty"`
 }
 
 // RouteArgs are the arguments for the route tool.
 type RouteArgs struct {
 	// Query is the natural language search query.
 	Query string `json:"query"`
+	// Message is the message to forward to the selected agent.
+	// Defaults to the user's latest message.
+	Message string `json:"message,omitempty"`
 	// Tags filters by classification tags.
 	Tags []string `json:"tags,omitempty"`
 	// Skills filters by skill IDs.
 	Skills []string `json:"skills,omitempty"`
+	// MinScore overrides the minimum embedding similarity (0-1) an agent needs.
+	MinScore *float32 `json:"min_score,omitempty"`
+	// MinRelativeScore overrides the fraction (0-1) of the best score an agent needs.
+	MinRelativeScore *float32 `json:"min_relative_score,omitempty"`
 }
 
 // BroadcastArgs are the arguments for the broadcast tool.
 type BroadcastArgs struct {
 	// Query is the natural language search query.
 	Query string `json:"query"`
+	// Message is the message to send to the selected agents.
+	// Defaults to the user's latest message.
+	Message string `json:"message,omitempty"`
 	// Limit is the maximum number of agents to broadcast to.
 	Limit int `json:"limit,omitempty"`
 	// Tags filters by classification tags.
 	Tags []string `json:"tags,omitempty"`
 	// Skills filters by skill IDs.
 	Skills []string `json:"skills,omitempty"`
+	// MinScore overrides the minimum embedding similarity (0-1) an agent needs.
+	MinScore *float32 `json:"min_score,omitempty"`
+	// MinRelativeScore overrides the fraction (0-1) of the best score an agent needs.
+	MinRelativeScore *float32 `json:"min_relative_score,omitempty"`
 }
 
 // ScoredAgent represents an agent with a relevance score.
 type ScoredAgent struct {
+	// AgentID is the agent's registry ID.
+	AgentID string `json:"agent_id"`
 	// Card is the agent's A2A card.
 	Card a2a.AgentCard `json:"card"`
 	// Score is the relevance score.
 	Score float32 `json:"score"`
+	// Similarity is the embedding similarity (0-1) to the query that
+	// min_score is compared against.
+	Similarity float32 `json:"similarity"`
+	// Health is the agent's liveness status, empty if not probed yet.
+	Health string `json:"health,omitempty"`
+	// MatchedSkill is the ID of the skill that best matched the query, empty
+	// if the agent as a whole matched best.
+	MatchedSkill string `json:"matched_skill,omitempty"`
 }
 
 // DiscoverResult is the result of the discover tool.
@@ -50,6 +82,8 @@ type DiscoverResult struct {
 	Agents []ScoredAgent `json:"agents"`
 	// Total is the total number of results.
 	Total int `json:"total"`
+	// NearMisses are the best agents scoring below the thresholds.
+	NearMisses []ScoredAgent `json:"near_misses,omitempty"`
 }
 
 // RouteResult is the result of the route tool.
@@ -58,6 +92,24 @@ type RouteResult struct {
 	Agent *ScoredAgent `json:"agent,omitempty"`
 	// Found indicates whether a matching agent was found.
 	Found bool `json:"found"`
+	// Reason explains why no agent was found.
+	Reason string `json:"reason,omitempty"`
+	// NearMisses are the best agents scoring below the thresholds.
+	NearMisses []ScoredAgent `json:"near_misses,omitempty"`
+	// Response is the selected agent's answer when the message was forwarded.
+	Response *AgentResponse `json:"response,omitempty"`
+	// Error describes why forwarding to the selected agent failed.
+	Error string `json:"error,omitempty"`
+}
+
+// AgentResponse is the answer of a downstream agent to a forwarded message.
+type AgentResponse struct {
+	// TaskID is the downstream task ID, empty if the agent replied with a message.
+	TaskID string `json:"task_id,omitempty"`
+	// State is the downstream task state.
+	State string `json:"state,omitempty"`
+	// Text is the text content of the agent's answer.
+	Text string `json:"text"`
 }
 
 // BroadcastResult is the result of the broadcast tool.
@@ -66,4 +118,76 @@ type BroadcastResult struct {
 	Agents []ScoredAgent `json:"agents"`
 	// Total is the total number of agents.
 	Total int `json:"total"`
+	// NearMisses are the best agents scoring below the thresholds, which
+	// were not sent the message.
+	NearMisses []ScoredAgent `json:"near_misses,omitempty"`
+	// Responses holds one entry per agent when the message was sent.
+	Responses []BroadcastResponse `json:"responses,omitempty"`
+	// Succeeded is the number of agents that answered.
+	Succeeded int `json:"succeeded"`
+	// Failed is the number of agents that returned an error.
+	Failed int `json:"failed"`
+	// TimedOut is the number of agents that did not answer in time.
+	TimedOut int `json:"timed_out"`
+	// Canceled is the number of deliveries abandoned because the broadcast
+	// was canceled.
+	Canceled int `json:"canceled"`
+}
+
+// BroadcastResponse is the outcome of sending a broadcast to one agent.
+type BroadcastResponse struct {
+	// AgentID is the agent's registry ID.
+	AgentID string `json:"agent_id"`
+	// AgentName is the agent's display name.
+	AgentName string `json:"agent_name"`
+	// Status is "ok", "error", "timeout" or "canceled".
+	Status string `json:"status"`
+	// Response is the agent's answer when Status is "ok".
+	Response *AgentResponse `json:"response,omitempty"`
+	// Error describes the failure when Status is not "ok".
+	Error string `json:"error,omitempty"`
+	// DurationMs is how long the agent took, in milliseconds.
+	DurationMs int64 `json:"duration_ms"`
+}
+
+// newScoredAgents converts store search hits to tool result agents.
+func newScoredAgents(hits []store.ScoredAgent) []ScoredAgent {
+	agents := make([]ScoredAgent, 0, len(hits))
+	for _, scored := range hits {
+		agents = append(agents, newScoredAgent(scored))
+	}
+	return agents
+}
+
+// newScoredAgent converts a store search hit to a tool result agent.
+func newScoredAgent(scored store.ScoredAgent) ScoredAgent {
+	return ScoredAgent{
+		AgentID:      scored.Agent.ID,
+		Card:         withEmptySlices(scored.Agent.Card),
+		Score:        scored.Score,
+		Similarity:   scored.Similarity,
+		Health:       string(scored.Agent.Health.Status),
+		MatchedSkill: scored.MatchedSkill,
+	}
+}
+
+// withEmptySlices returns a copy of card whose required list fields are empty
+// instead of nil. The tool output schema requires them to be arrays, and
+// agents may register cards without them.
+func withEmptySlices(card a2a.AgentCard) a2a.AgentCard {
+	if card.DefaultInputModes == nil {
+		card.DefaultInputModes = []string{}
+	}
+	if card.DefaultOutputModes == nil {
+		card.DefaultOutputModes = []string{}
+	}
+	skills := make([]a2a.AgentSkill, len(card.Skills))
+	for i, skill := range card.Skills {
+		if skill.Tags == nil {
+			skill.Tags = []string{}
+		}
+		skills[i] = skill
+	}
+	card.Skills = skills
+	return card
 }
@@ -4,6 +4,8 @@ import (
 	"log/slog"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds application configuration from environment variables.
@@ -13,6 +15,10 @@ type Config struct {
 	// LogLevel is the minimum log level for logging.
 	LogLevel slog.Level
 
+	// Store config
+	StoreBackend string
+	StorePath    string
+
 	// Qdrant config
 	QdrantHost   string
 	QdrantPort   int
@@ -20,27 +26,158 @@ type Config struct {
 	QdrantUseTLS bool
 
 	// Embedding config
-	EmbeddingURL string
-	EmbeddingDim int
+	EmbeddingURL          string
+	EmbeddingDim          int
+	EmbeddingModel        string
+	EmbeddingCacheSize    int
+	EmbeddingCacheTTL     time.Duration
+	EmbeddingBatchWindow  time.Duration
+	EmbeddingBatchTimeout time.Duration
+	EmbeddingMaxBatch     int
+
+	// Embedding retry config
+	EmbeddingMaxRetries       int
+	EmbeddingRetryBackoff     time.Duration
+	EmbeddingRetryMaxBackoff  time.Duration
+	EmbeddingBreakerThreshold int
+	EmbeddingBreakerCooldown  time.Duration
+
+	// Embedding check config (disable to serve a store built with another model while reindexing)
+	EmbeddingCheckEnabled bool
+
+	// LLM config
+	LLMProvider string
+	LLMURL      string
+	LLMModel    string
+	LLMAPIKey   string
 
 	// Gemini config
 	GeminiAPIKey string
 	GeminiModel  string
+
+	// Forwarding config
+	ForwardEnabled   bool
+	ForwardTimeout   time.Duration
+	BroadcastTimeout time.Duration
+
+	// Health check config
+	HealthCheckEnabled          bool
+	HealthCheckInterval         time.Duration
+	HealthCheckTimeout          time.Duration
+	HealthCheckFailureThreshold int
+	HealthPolicy                string
+
+	// Discovery ranking config
+	DiscoverFusion         string
+	DiscoverSemanticWeight float64
+	DiscoverLexicalWeight  float64
+
+	// Discovery score thresholds
+	DiscoverMinScore         float64
+	DiscoverMinRelativeScore float64
+
+	// Lease config
+	LeaseReapInterval time.Duration
+
+	// Session config
+	SessionStore     string
+	SessionPath      string
+	SessionMaxEvents int
+
+	// Admin auth config
+	AdminAPIKeys         []string
+	AdminReadOnlyAPIKeys []string
+	AdminLeaseAPIKeys    []string
+	AdminJWTSecret       string
+	AdminJWTIssuer       string
+	AdminJWTAudience     string
+
+	// Metrics config
+	MetricsEnabled bool
+
+	// Tracing config (TracingExporter: none, otlp, stdout; TracingProtocol: grpc, http)
+	TracingExporter    string
+	TracingProtocol    string
+	TracingEndpoint    string
+	TracingInsecure    bool
+	TracingFile        string
+	TracingSampleRatio float64
+	TracingServiceName string
 }
 
 // Load reads configuration from environment variables with sensible defaults.
 func Load() *Config {
 	return &Config{
-		Port:         getEnvInt("PORT", 8080),
-		LogLevel:     getEnvLogLevel("LOG_LEVEL", slog.LevelInfo),
-		QdrantHost:   getEnv("QDRANT_HOST", "localhost"),
-		QdrantPort:   getEnvInt("QDRANT_PORT", 6334),
-		QdrantAPIKey: getEnv("QDRANT_API_KEY", ""),
-		QdrantUseTLS: getEnvBool("QDRANT_USE_TLS", false),
-		EmbeddingURL: getEnv("EMBEDDING_URL", "http://localhost:8081"),
-		EmbeddingDim: getEnvInt("EMBEDDING_DIM", 384),
-		GeminiAPIKey: getEnv("GEMINI_API_KEY", ""),
-		GeminiModel:  getEnv("GEMINI_MODEL", "gemini-3-flash-preview"),
+		Port:             getEnvInt("PORT", 8080),
+		LogLevel:         getEnvLogLevel("LOG_LEVEL", slog.LevelInfo),
+		StoreBackend:     getEnv("STORE_BACKEND", "qdrant"),
+		StorePath:        getEnv("STORE_PATH", "data/agents.db"),
+		QdrantHost:       getEnv("QDRANT_HOST", "localhost"),
+		QdrantPort:       getEnvInt("QDRANT_PORT", 6334),
+		QdrantAPIKey:     getEnv("QDRANT_API_KEY", ""),
+		QdrantUseTLS:     getEnvBool("QDRANT_USE_TLS", false),
+		EmbeddingURL:     getEnv("EMBEDDING_URL", "http://localhost:8081"),
+		EmbeddingDim:     getEnvInt("EMBEDDING_DIM", 384),
+		EmbeddingModel:   getEnv("EMBEDDING_MODEL", ""),
+		LLMProvider:      getEnv("LLM_PROVIDER", "gemini"),
+		LLMURL:           getEnv("LLM_URL", "http://localhost:8000"),
+		LLMModel:         getEnv("LLM_MODEL", ""),
+		LLMAPIKey:        getEnv("LLM_API_KEY", ""),
+		GeminiAPIKey:     getEnv("GEMINI_API_KEY", ""),
+		GeminiModel:      getEnv("GEMINI_MODEL", "gemini-3-flash-preview"),
+		ForwardEnabled:   getEnvBool("FORWARD_ENABLED", true),
+		ForwardTimeout:   getEnvDuration("FORWARD_TIMEOUT", 60*time.Second),
+		BroadcastTimeout: getEnvDuration("BROADCAST_TIMEOUT", 90*time.Second),
+
+		EmbeddingCacheSize:    getEnvInt("EMBEDDING_CACHE_SIZE", 1024),
+		EmbeddingCacheTTL:     getEnvDuration("EMBEDDING_CACHE_TTL", time.Hour),
+		EmbeddingBatchWindow:  getEnvDuration("EMBEDDING_BATCH_WINDOW", 5*time.Millisecond),
+		EmbeddingBatchTimeout: getEnvDuration("EMBEDDING_BATCH_TIMEOUT", 30*time.Second),
+		EmbeddingMaxBatch:     getEnvInt("EMBEDDING_MAX_BATCH", 32),
+
+		EmbeddingMaxRetries:       getEnvInt("EMBEDDING_MAX_RETRIES", 3),
+		EmbeddingRetryBackoff:     getEnvDuration("EMBEDDING_RETRY_BACKOFF", 200*time.Millisecond),
+		EmbeddingRetryMaxBackoff:  getEnvDuration("EMBEDDING_RETRY_MAX_BACKOFF", 5*time.Second),
+		EmbeddingBreakerThreshold: getEnvInt("EMBEDDING_BREAKER_THRESHOLD", 5),
+		EmbeddingBreakerCooldown:  getEnvDuration("EMBEDDING_BREAKER_COOLDOWN", 30*time.Second),
+
+		EmbeddingCheckEnabled: getEnvBool("EMBEDDING_CHECK_ENABLED", true),
+
+		HealthCheckEnabled:          getEnvBool("HEALTH_CHECK_ENABLED", true),
+		HealthCheckInterval:         getEnvDuration("HEALTH_CHECK_INTERVAL", 30*time.Second),
+		HealthCheckTimeout:          getEnvDuration("HEALTH_CHECK_TIMEOUT", 5*time.Second),
+		HealthCheckFailureThreshold: getEnvInt("HEALTH_CHECK_FAILURE_THRESHOLD", 3),
+		HealthPolicy:                getEnv("HEALTH_POLICY", "downrank"),
+
+		DiscoverFusion:         getEnv("DISCOVER_FUSION", "rrf"),
+		DiscoverSemanticWeight: getEnvFloat("DISCOVER_SEMANTIC_WEIGHT", 1),
+		DiscoverLexicalWeight:  getEnvFloat("DISCOVER_LEXICAL_WEIGHT", 1),
+
+		DiscoverMinScore:         getEnvFloat("DISCOVER_MIN_SCORE", 0),
+		DiscoverMinRelativeScore: getEnvFloat("DISCOVER_MIN_RELATIVE_SCORE", 0),
+
+		LeaseReapInterval: getEnvPositiveDuration("LEASE_REAP_INTERVAL", 15*time.Second),
+
+		SessionStore:     getEnv("SESSION_STORE", "memory"),
+		SessionPath:      getEnv("SESSION_PATH", "data/sessions.db"),
+		SessionMaxEvents: getEnvInt("SESSION_MAX_EVENTS", 200),
+
+		AdminAPIKeys:         getEnvList("ADMIN_API_KEYS"),
+		AdminReadOnlyAPIKeys: getEnvList("ADMIN_READONLY_API_KEYS"),
+		AdminLeaseAPIKeys:    getEnvList("ADMIN_LEASE_API_KEYS"),
+		AdminJWTSecret:       getEnv("ADMIN_JWT_SECRET", ""),
+		AdminJWTIssuer:       getEnv("ADMIN_JWT_ISSUER", ""),
+		AdminJWTAudience:     getEnv("ADMIN_JWT_AUDIENCE", ""),
+
+		MetricsEnabled: getEnvBool("METRICS_ENABLED", true),
+
+		TracingExporter:    getEnv("TRACING_EXPORTER", "none"),
+		TracingProtocol:    getEnv("TRACING_OTLP_PROTOCOL", "grpc"),
+		TracingEndpoint:    getEnv("TRACING_OTLP_ENDPOINT", ""),
+		TracingInsecure:    getEnvBool("TRACING_OTLP_INSECURE", false),
+		TracingFile:        getEnv("TRACING_FILE", ""),
+		TracingSampleRatio: getEnvFloat("TRACING_SAMPLE_RATIO", 1),
+		TracingServiceName: getEnv("TRACING_SERVICE_NAME", "agent-broker"),
 	}
 }
 
@@ -51,6 +188,16 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func getEnvInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		if parsed, err := strconv.Atoi(value); err == nil {
@@ -60,6 +207,15 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
 func getEnvBool(key string, defaultValue bool) bool {
 	value := os.Getenv(key)
 	switch value {
@@ -72,6 +228,24 @@ func getEnvBool(key string, defaultValue bool) bool {
 	}
 }
 
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getEnvPositiveDuration is getEnvDuration for intervals, which must be
+// positive; zero and negative values fall back to defaultValue.
+func getEnvPositiveDuration(key string, defaultValue time.Duration) time.Duration {
+	if d := getEnvDuration(key, defaultValue); d > 0 {
+		return d
+	}
+	return defaultValue
+}
+
 func getEnvLogLevel(key string, defaultValue slog.Level) slog.Level {
 	value := getEnv(key, "")
 	switch value {
@@ -0,0 +1,86 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// OutcomeStatus is the result status of a single broadcast delivery.
+type OutcomeStatus string
+
+// Outcome status values.
+const (
+	OutcomeOK       OutcomeStatus = "ok"
+	OutcomeError    OutcomeStatus = "error"
+	OutcomeTimeout  OutcomeStatus = "timeout"
+	OutcomeCanceled OutcomeStatus = "canceled"
+)
+
+// Outcome is the result of delivering a broadcast to one agent.
+type Outcome struct {
+	// AgentID is the agent's registry ID.
+	AgentID string
+	// Status is the delivery status.
+	Status OutcomeStatus
+	// Response is the agent's answer, set when Status is OutcomeOK.
+	Response *Response
+	// Err is the delivery error, set when Status is not OutcomeOK.
+	Err error
+	// Duration is how long the delivery took.
+	Duration time.Duration
+}
+
+// Broadcast sends text to every target concurrently and collects one Outcome
+// per target, in target order. Each delivery is bounded by the per-call
+// timeout and the whole broadcast by the broadcast timeout; failures are
+// reported per target and never abort the other deliveries.
+func (d *Dispatcher) Broadcast(ctx context.Context, targets []Target, text string) []Outcome {
+	ctx, cancel := context.WithTimeout(ctx, d.broadcastTimeout)
+	defer cancel()
+
+	outcomes := make([]Outcome, len(targets))
+
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			outcomes[i] = d.deliver(ctx, target, text)
+		}()
+	}
+	wg.Wait()
+
+	return outcomes
+}
+
+// deliver sends text to a single broadcast target.
+func (d *Dispatcher) deliver(ctx context.Context, target Target, text string) Outcome {
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	start := time.Now()
+	resp, err := d.send(ctx, target, text)
+	outcome := Outcome{
+		AgentID:  target.AgentID,
+		Duration: time.Since(start),
+	}
+
+	switch {
+	case err == nil:
+		outcome.Status = OutcomeOK
+		outcome.Response = resp
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		outcome.Status = OutcomeTimeout
+		outcome.Err = ctx.Err()
+	case ctx.Err() != nil:
+		outcome.Status = OutcomeCanceled
+		outcome.Err = ctx.Err()
+	default:
+		outcome.Status = OutcomeError
+		outcome.Err = err
+	}
+
+	return outcome
+}
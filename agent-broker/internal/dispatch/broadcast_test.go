@@ -0,0 +1,80 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+)
+
+// stallExecutor never answers until the request is cancelled.
+type stallExecutor struct{}
+
+func (stallExecutor) Execute(ctx context.Context, _ *a2asrv.RequestContext, _ eventqueue.Queue) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (stallExecutor) Cancel(_ context.Context, _ *a2asrv.RequestContext, _ eventqueue.Queue) error {
+	return nil
+}
+
+func TestDispatcher_Broadcast(t *testing.T) {
+	t.Parallel()
+
+	echo := startAgent(t)
+
+	stall := httptest.NewServer(a2asrv.NewJSONRPCHandler(a2asrv.NewHandler(stallExecutor{})))
+	t.Cleanup(stall.Close)
+
+	targets := []Target{
+		{AgentID: "echo", Card: echo},
+		{AgentID: "down", Card: &a2a.AgentCard{Name: "Down", URL: "http://127.0.0.1:1"}},
+		{AgentID: "stall", Card: &a2a.AgentCard{Name: "Stall", URL: stall.URL}},
+	}
+
+	d := NewDispatcher(WithTimeout(200 * time.Millisecond))
+	outcomes := d.Broadcast(context.Background(), targets, "hello")
+
+	if len(outcomes) != len(targets) {
+		t.Fatalf("Broadcast() returned %d outcomes, want %d", len(outcomes), len(targets))
+	}
+
+	want := []OutcomeStatus{OutcomeOK, OutcomeError, OutcomeTimeout}
+	for i, outcome := range outcomes {
+		if outcome.AgentID != targets[i].AgentID {
+			t.Errorf("outcome[%d].AgentID = %v, want %v", i, outcome.AgentID, targets[i].AgentID)
+		}
+		if outcome.Status != want[i] {
+			t.Errorf("outcome[%d].Status = %v, want %v (err: %v)", i, outcome.Status, want[i], outcome.Err)
+		}
+	}
+	if outcomes[0].Response == nil || outcomes[0].Response.Text != "echo: hello" {
+		t.Errorf("outcome[0].Response = %+v, want echo: hello", outcomes[0].Response)
+	}
+}
+
+func TestDispatcher_Broadcast_Canceled(t *testing.T) {
+	t.Parallel()
+
+	stall := httptest.NewServer(a2asrv.NewJSONRPCHandler(a2asrv.NewHandler(stallExecutor{})))
+	t.Cleanup(stall.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	d := NewDispatcher(WithTimeout(time.Minute))
+	outcomes := d.Broadcast(ctx, []Target{{AgentID: "stall", Card: &a2a.AgentCard{Name: "Stall", URL: stall.URL}}}, "hello")
+
+	if len(outcomes) != 1 {
+		t.Fatalf("Broadcast() returned %d outcomes, want 1", len(outcomes))
+	}
+	if outcomes[0].Status != OutcomeCanceled || !errors.Is(outcomes[0].Err, context.Canceled) {
+		t.Errorf("outcome.Status = %v (err: %v), want %v", outcomes[0].Status, outcomes[0].Err, OutcomeCanceled)
+	}
+}
@@ -0,0 +1,228 @@
+package dispatch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2aclient"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates the spans of forwarded messages.
+var tracer = otel.Tracer("github.com/lunarr-ai/lunarr/agent-broker/internal/dispatch")
+
+// Dispatcher forwards messages to registered agents over A2A.
+type Dispatcher struct {
+	// httpClient is the HTTP client used by the A2A transports.
+	httpClient *http.Client
+	// timeout bounds a single forwarded call.
+	timeout time.Duration
+	// broadcastTimeout bounds a whole broadcast.
+	broadcastTimeout time.Duration
+}
+
+// Options configures the Dispatcher.
+type Options struct {
+	// HTTPClient is the HTTP client used by the A2A transports. The default
+	// client propagates the W3C trace context of forwarded calls.
+	HTTPClient *http.Client
+	// Timeout is the max duration of a single forwarded call.
+	Timeout time.Duration
+	// BroadcastTimeout is the overall deadline of a broadcast.
+	BroadcastTimeout time.Duration
+}
+
+// DefaultOptions returns Options with sensible defaults.
+func DefaultOptions() Options {
+	return Options{
+		HTTPClient:       &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)},
+		Timeout:          60 * time.Second,
+		BroadcastTimeout: 90 * time.Second,
+	}
+}
+
+// Option is a functional option for configuring Dispatcher.
+type Option func(*Options)
+
+// WithHTTPClient sets the HTTP client.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *Options) {
+		o.HTTPClient = client
+	}
+}
+
+// WithTimeout sets the timeout of a single forwarded call.
+func WithTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		if d > 0 {
+			o.Timeout = d
+		}
+	}
+}
+
+// WithBroadcastTimeout sets the overall deadline of a broadcast.
+func WithBroadcastTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		if d > 0 {
+			o.BroadcastTimeout = d
+		}
+	}
+}
+
+// NewDispatcher creates a Dispatcher with the given options.
+func NewDispatcher(opts ...Option) *Dispatcher {
+	options := DefaultOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &Dispatcher{
+		httpClient:       options.HTTPClient,
+		timeout:          options.Timeout,
+		broadcastTimeout: options.BroadcastTimeout,
+	}
+}
+
+// Response is the answer of a downstream agent to a forwarded message.
+type Response struct {
+	// TaskID is the downstream task ID, empty if the agent replied with a message.
+	TaskID a2a.TaskID
+	// ContextID is the downstream context ID.
+	ContextID string
+	// State is the downstream task state, empty if the agent replied with a message.
+	State a2a.TaskState
+	// Text is the text content of the agent's answer.
+	Text string
+}
+
+// Target is an agent a message is forwarded to.
+type Target struct {
+	// AgentID is the agent's registry ID.
+	AgentID string
+	// Card is the agent's A2A card.
+	Card *a2a.AgentCard
+}
+
+// Send forwards text as a user message to the target agent and waits for the
+// resulting task or message. If ctx carries an EventSink, the message is
+// streamed and the agent's status and artifact updates are relayed to it.
+func (d *Dispatcher) Send(ctx context.Context, target Target, text string) (*Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	return d.send(ctx, target, text)
+}
+
+// send forwards text to the target agent without applying a timeout.
+func (d *Dispatcher) send(ctx context.Context, target Target, text string) (_ *Response, err error) {
+	ctx, span := tracer.Start(ctx, "dispatch.send",
+		trace.WithAttributes(
+			attribute.String("agent.id", target.AgentID),
+			attribute.String("agent.url", target.Card.URL),
+		),
+	)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	client, err := d.newClient(ctx, target.Card)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = client.Destroy() }()
+
+	params := &a2a.MessageSendParams{
+		Message: a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: text}),
+	}
+
+	if sink := eventSinkFrom(ctx); sink != nil {
+		return d.stream(ctx, client, target, params, sink)
+	}
+
+	result, err := client.SendMessage(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("send message: %w", err)
+	}
+
+	return responseFromResult(result), nil
+}
+
+// newClient creates an A2A client for the agent described by card.
+func (d *Dispatcher) newClient(ctx context.Context, card *a2a.AgentCard) (*a2aclient.Client, error) {
+	// Cards without a preferred transport default to JSON-RPC per the A2A spec.
+	target := *card
+	if target.PreferredTransport == "" {
+		target.PreferredTransport = a2a.TransportProtocolJSONRPC
+	}
+
+	client, err := a2aclient.NewFromCard(ctx, &target, a2aclient.WithJSONRPCTransport(d.httpClient))
+	if err != nil {
+		return nil, fmt.Errorf("create a2a client: %w", err)
+	}
+	return client, nil
+}
+
+// responseFromResult converts a SendMessage result to a Response.
+func responseFromResult(result a2a.SendMessageResult) *Response {
+	switch v := result.(type) {
+	case *a2a.Message:
+		return &Response{
+			TaskID:    v.TaskID,
+			ContextID: v.ContextID,
+			Text:      partsText(v.Parts),
+		}
+	case *a2a.Task:
+		return &Response{
+			TaskID:    v.ID,
+			ContextID: v.ContextID,
+			State:     v.Status.State,
+			Text:      taskText(v),
+		}
+	default:
+		return &Response{}
+	}
+}
+
+// taskText extracts the answer from a task, preferring artifacts over the status message.
+func taskText(task *a2a.Task) string {
+	var parts []string
+	for _, artifact := range task.Artifacts {
+		if text := partsText(artifact.Parts); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	if len(parts) > 0 {
+		return strings.Join(parts, "\n")
+	}
+
+	if task.Status.Message != nil {
+		return partsText(task.Status.Message.Parts)
+	}
+	return ""
+}
+
+// partsText concatenates the text parts of a message or artifact.
+func partsText(parts a2a.ContentParts) string {
+	var texts []string
+	for _, part := range parts {
+		switch p := part.(type) {
+		case a2a.TextPart:
+			texts = append(texts, p.Text)
+		case *a2a.TextPart:
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
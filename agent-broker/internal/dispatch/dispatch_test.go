@@ -0,0 +1,155 @@
+package dispatch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// echoExecutor replies to every message with a fixed prefix and the message text.
+type echoExecutor struct{}
+
+func (echoExecutor) Execute(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue) error {
+	reply := a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: "echo: " + partsText(reqCtx.Message.Parts)})
+	return queue.Write(ctx, reply)
+}
+
+func (echoExecutor) Cancel(_ context.Context, _ *a2asrv.RequestContext, _ eventqueue.Queue) error {
+	return nil
+}
+
+func startAgent(t *testing.T) *a2a.AgentCard {
+	t.Helper()
+	server := httptest.NewServer(a2asrv.NewJSONRPCHandler(a2asrv.NewHandler(echoExecutor{})))
+	t.Cleanup(server.Close)
+
+	return &a2a.AgentCard{
+		Name:    "Echo Agent",
+		URL:     server.URL,
+		Version: "1.0.0",
+	}
+}
+
+func TestDispatcher_Send(t *testing.T) {
+	t.Parallel()
+
+	t.Run("returns agent answer", func(t *testing.T) {
+		t.Parallel()
+		card := startAgent(t)
+		d := NewDispatcher()
+
+		resp, err := d.Send(context.Background(), Target{AgentID: "echo", Card: card}, "hello")
+
+		if err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		if resp.Text != "echo: hello" {
+			t.Errorf("Send() Text = %q, want %q", resp.Text, "echo: hello")
+		}
+	})
+
+	t.Run("unreachable agent returns error", func(t *testing.T) {
+		t.Parallel()
+		card := startAgent(t)
+		card.URL = "http://127.0.0.1:1"
+		d := NewDispatcher()
+
+		_, err := d.Send(context.Background(), Target{AgentID: "echo", Card: card}, "hello")
+
+		if err == nil {
+			t.Error("Send() error = nil, want error")
+		}
+	})
+}
+
+// TestDispatcher_SendPropagatesTraceContext sets the global propagator and is
+// therefore not parallel.
+func TestDispatcher_SendPropagatesTraceContext(t *testing.T) {
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+
+	var mu sync.Mutex
+	var traceparent string
+	agentHandler := a2asrv.NewJSONRPCHandler(a2asrv.NewHandler(echoExecutor{}))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		traceparent = r.Header.Get("traceparent")
+		mu.Unlock()
+		agentHandler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	traceID := trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	}))
+	card := &a2a.AgentCard{Name: "Echo Agent", URL: server.URL, Version: "1.0.0"}
+
+	if _, err := NewDispatcher().Send(ctx, Target{AgentID: "echo", Card: card}, "hello"); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !strings.Contains(traceparent, traceID.String()) {
+		t.Errorf("traceparent = %q, want trace ID %s", traceparent, traceID)
+	}
+}
+
+func TestResponseFromResult(t *testing.T) {
+	t.Parallel()
+
+	t.Run("task prefers artifacts", func(t *testing.T) {
+		t.Parallel()
+		task := &a2a.Task{
+			ID: "task-1",
+			Status: a2a.TaskStatus{
+				State:   a2a.TaskStateCompleted,
+				Message: a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: "status"}),
+			},
+			Artifacts: []*a2a.Artifact{
+				{ID: "a-1", Parts: a2a.ContentParts{a2a.TextPart{Text: "artifact"}}},
+			},
+		}
+
+		resp := responseFromResult(task)
+
+		if resp.Text != "artifact" {
+			t.Errorf("Text = %q, want artifact", resp.Text)
+		}
+		if resp.State != a2a.TaskStateCompleted {
+			t.Errorf("State = %v, want completed", resp.State)
+		}
+	})
+
+	t.Run("task falls back to status message", func(t *testing.T) {
+		t.Parallel()
+		task := &a2a.Task{
+			ID: "task-1",
+			Status: a2a.TaskStatus{
+				State:   a2a.TaskStateFailed,
+				Message: a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: "status"}),
+			},
+		}
+
+		resp := responseFromResult(task)
+
+		if resp.Text != "status" {
+			t.Errorf("Text = %q, want status", resp.Text)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package dispatch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2aclient"
+)
+
+// EventSink receives the status and artifact updates of a downstream agent
+// while a forwarded message is being processed.
+type EventSink func(ctx context.Context, source Target, event a2a.Event) error
+
+type eventSinkKey struct{}
+
+// WithEventSink returns a context that makes the Dispatcher stream forwarded
+// messages and relay downstream events to sink.
+func WithEventSink(ctx context.Context, sink EventSink) context.Context {
+	return context.WithValue(ctx, eventSinkKey{}, sink)
+}
+
+// eventSinkFrom returns the EventSink stored in ctx, if any.
+func eventSinkFrom(ctx context.Context) EventSink {
+	sink, _ := ctx.Value(eventSinkKey{}).(EventSink)
+	return sink
+}
+
+// stream sends params with message/stream, relays status and artifact updates
+// to sink and assembles the final Response from the received events. Agents
+// that do not advertise streaming are sent a blocking message instead.
+func (d *Dispatcher) stream(ctx context.Context, client *a2aclient.Client, target Target, params *a2a.MessageSendParams, sink EventSink) (*Response, error) {
+	var (
+		task    a2a.Task
+		message *a2a.Message
+	)
+
+	for event, err := range client.SendStreamingMessage(ctx, params) {
+		if err != nil {
+			return nil, fmt.Errorf("stream message: %w", err)
+		}
+
+		switch v := event.(type) {
+		case *a2a.Message:
+			message = v
+		case *a2a.Task:
+			task = *v
+		case *a2a.TaskStatusUpdateEvent:
+			task.ID = v.TaskID
+			task.ContextID = v.ContextID
+			task.Status = v.Status
+			if err := sink(ctx, target, v); err != nil {
+				return nil, fmt.Errorf("relay status update: %w", err)
+			}
+		case *a2a.TaskArtifactUpdateEvent:
+			task.ID = v.TaskID
+			task.ContextID = v.ContextID
+			applyArtifactUpdate(&task, v)
+			if err := sink(ctx, target, v); err != nil {
+				return nil, fmt.Errorf("relay artifact update: %w", err)
+			}
+		}
+	}
+
+	if message != nil {
+		return responseFromResult(message), nil
+	}
+	return responseFromResult(&task), nil
+}
+
+// applyArtifactUpdate merges an artifact update into the task's artifacts.
+func applyArtifactUpdate(task *a2a.Task, event *a2a.TaskArtifactUpdateEvent) {
+	if event.Artifact == nil {
+		return
+	}
+
+	for i, artifact := range task.Artifacts {
+		if artifact.ID != event.Artifact.ID {
+			continue
+		}
+		if event.Append {
+			merged := *artifact
+			merged.Parts = append(append(a2a.ContentParts{}, artifact.Parts...), event.Artifact.Parts...)
+			task.Artifacts[i] = &merged
+		} else {
+			task.Artifacts[i] = event.Artifact
+		}
+		return
+	}
+
+	task.Artifacts = append(task.Artifacts, event.Artifact)
+}
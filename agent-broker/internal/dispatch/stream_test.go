@@ -0,0 +1,100 @@
+package dispatch
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+)
+
+// taskExecutor answers with a task that produces one artifact in two chunks.
+type taskExecutor struct{}
+
+func (taskExecutor) Execute(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue) error {
+	events := []a2a.Event{
+		a2a.NewSubmittedTask(reqCtx, reqCtx.Message),
+		a2a.NewStatusUpdateEvent(reqCtx, a2a.TaskStateWorking, nil),
+		func() a2a.Event {
+			event := a2a.NewArtifactUpdateEvent(reqCtx, "result", a2a.TextPart{Text: "hello "})
+			event.Append = false
+			return event
+		}(),
+		a2a.NewArtifactUpdateEvent(reqCtx, "result", a2a.TextPart{Text: "world"}),
+		func() a2a.Event {
+			event := a2a.NewStatusUpdateEvent(reqCtx, a2a.TaskStateCompleted, nil)
+			event.Final = true
+			return event
+		}(),
+	}
+	for _, event := range events {
+		if err := queue.Write(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (taskExecutor) Cancel(_ context.Context, _ *a2asrv.RequestContext, _ eventqueue.Queue) error {
+	return nil
+}
+
+func TestDispatcher_Send_Streaming(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(a2asrv.NewJSONRPCHandler(a2asrv.NewHandler(taskExecutor{})))
+	t.Cleanup(server.Close)
+
+	target := Target{
+		AgentID: "task-agent",
+		Card: &a2a.AgentCard{
+			Name:         "Task Agent",
+			URL:          server.URL,
+			Capabilities: a2a.AgentCapabilities{Streaming: true},
+		},
+	}
+
+	var (
+		mu       sync.Mutex
+		received []a2a.Event
+	)
+	ctx := WithEventSink(context.Background(), func(_ context.Context, source Target, event a2a.Event) error {
+		if source.AgentID != "task-agent" {
+			t.Errorf("source.AgentID = %v, want task-agent", source.AgentID)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, event)
+		return nil
+	})
+
+	resp, err := NewDispatcher().Send(ctx, target, "hi")
+
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if resp.Text != "hello \nworld" && resp.Text != "hello world" {
+		t.Errorf("Send() Text = %q, want hello world", resp.Text)
+	}
+	if resp.State != a2a.TaskStateCompleted {
+		t.Errorf("Send() State = %v, want completed", resp.State)
+	}
+
+	var statuses, artifacts int
+	for _, event := range received {
+		switch event.(type) {
+		case *a2a.TaskStatusUpdateEvent:
+			statuses++
+		case *a2a.TaskArtifactUpdateEvent:
+			artifacts++
+		default:
+			t.Errorf("unexpected relayed event %T", event)
+		}
+	}
+	if statuses != 2 || artifacts != 2 {
+		t.Errorf("relayed %d status and %d artifact events, want 2 and 2", statuses, artifacts)
+	}
+}
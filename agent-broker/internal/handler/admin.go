@@ -3,6 +3,7 @@ package handler
 import (
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/a2aproject/a2a-go/a2a"
 
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/logging"
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/registry"
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
 )
@@ -29,9 +31,14 @@ func NewAdminHandler(registry *registry.RegistryService) *AdminHandler {
 func (h *AdminHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /v1/admin/agents", h.handleList)
 	mux.HandleFunc("POST /v1/admin/agents", h.handleCreate)
+	mux.HandleFunc("POST /v1/admin/agents/from-url", h.handleRegisterFromURL)
 	mux.HandleFunc("GET /v1/admin/agents/{id}", h.handleGet)
 	mux.HandleFunc("PUT /v1/admin/agents/{id}", h.handleUpdate)
 	mux.HandleFunc("DELETE /v1/admin/agents/{id}", h.handleDelete)
+	mux.HandleFunc("POST /v1/admin/agents/{id}/refresh", h.handleRefresh)
+	mux.HandleFunc("POST /v1/admin/agents/{id}/heartbeat", h.handleHeartbeat)
+	mux.HandleFunc("POST /v1/admin/reindex", h.handleStartReindex)
+	mux.HandleFunc("GET /v1/admin/reindex", h.handleReindexStatus)
 }
 
 // RegisterAgentRequest is the JSON request for registering an agent.
@@ -42,6 +49,20 @@ type RegisterAgentRequest struct {
 	AgentCard a2a.AgentCard `json:"agent_card"`
 	// Tags are classification tags.
 	Tags []string `json:"tags"`
+	// TTLSeconds is the registration lease renewed by heartbeats, 0 for no expiry.
+	TTLSeconds int `json:"ttl_seconds,omitempty"`
+}
+
+// RegisterFromURLRequest is the JSON request for registering an agent by its card URL.
+type RegisterFromURLRequest struct {
+	// URL is the agent's base URL serving /.well-known/agent-card.json.
+	URL string `json:"url"`
+	// AgentID is the unique agent identifier, derived from the card name if empty.
+	AgentID string `json:"agent_id,omitempty"`
+	// Tags are classification tags.
+	Tags []string `json:"tags"`
+	// TTLSeconds is the registration lease renewed by heartbeats, 0 for no expiry.
+	TTLSeconds int `json:"ttl_seconds,omitempty"`
 }
 
 // UpdateAgentRequest is the JSON request for updating an agent.
@@ -64,6 +85,14 @@ type AgentRecordResponse struct {
 	Skills []string `json:"skills"`
 	// Tags are classification tags.
 	Tags []string `json:"tags"`
+	// SourceURL is the URL the card was fetched from, if registered by URL.
+	SourceURL string `json:"source_url,omitempty"`
+	// Health is the agent's liveness state.
+	Health AgentHealthResponse `json:"health"`
+	// TTLSeconds is the registration lease, omitted if the registration never expires.
+	TTLSeconds int64 `json:"ttl_seconds,omitempty"`
+	// ExpiresAt is when the lease expires unless renewed by a heartbeat.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 	// RegisteredAt is the registration timestamp.
 	RegisteredAt time.Time `json:"registered_at"`
 	// UpdatedAt is the last update timestamp.
@@ -71,6 +100,38 @@ type AgentRecordResponse struct {
 	// TODO: Add RegisteredBy field to track admin user who registered the agent.
 }
 
+// AgentHealthResponse is the JSON representation of an agent's liveness state.
+type AgentHealthResponse struct {
+	// Status is "healthy", "unhealthy" or "unknown" if not probed yet.
+	Status string `json:"status"`
+	// LastSeen is when the agent last answered a probe.
+	LastSeen *time.Time `json:"last_seen,omitempty"`
+	// LastChecked is when the agent was last probed.
+	LastChecked *time.Time `json:"last_checked,omitempty"`
+	// ConsecutiveFailures is the number of failed probes since the last success.
+	ConsecutiveFailures int `json:"consecutive_failures"`
+	// LatencyMs is the duration of the last successful probe in milliseconds.
+	LatencyMs int64 `json:"latency_ms"`
+	// LastError is the error of the last failed probe.
+	LastError string `json:"last_error,omitempty"`
+}
+
+// HeartbeatResponse is the JSON response for renewing an agent lease.
+type HeartbeatResponse struct {
+	// AgentID is the unique identifier.
+	AgentID string `json:"agent_id"`
+	// ExpiresAt is the new lease expiry.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// RefreshAgentResponse is the JSON response for refreshing an agent card.
+type RefreshAgentResponse struct {
+	// Agent is the agent record after the refresh.
+	Agent AgentRecordResponse `json:"agent"`
+	// Changed indicates if the card changed and the agent was re-embedded.
+	Changed bool `json:"changed"`
+}
+
 // AgentListResponse is the JSON response for listing agents.
 type AgentListResponse struct {
 	// Agents is the list of agent records.
@@ -89,6 +150,36 @@ type PaginationResponse struct {
 	Limit int `json:"limit"`
 	// HasMore indicates if there are more items.
 	HasMore bool `json:"has_more"`
+	// NextCursor fetches the next page when passed as the cursor parameter.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ReindexRequest is the JSON request for starting a reindex.
+type ReindexRequest struct {
+	// BatchSize is the number of agents embedded per request, the default if 0.
+	BatchSize int `json:"batch_size,omitempty"`
+}
+
+// ReindexStatusResponse is the JSON response describing a reindex.
+type ReindexStatusResponse struct {
+	// State is "idle", "running", "completed" or "failed".
+	State string `json:"state"`
+	// Target is the index being filled.
+	Target string `json:"target,omitempty"`
+	// Previous is the index replaced by the switch, kept for rollback.
+	Previous string `json:"previous,omitempty"`
+	// Total is the number of agents when the reindex started.
+	Total int `json:"total"`
+	// Copied is the number of agents re-embedded so far.
+	Copied int `json:"copied"`
+	// Resumed indicates the reindex continued from a checkpoint.
+	Resumed bool `json:"resumed"`
+	// StartedAt is when the reindex started.
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	// FinishedAt is when the reindex completed or failed.
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	// Error is the failure of a failed reindex.
+	Error string `json:"error,omitempty"`
 }
 
 // ErrorResponse is the JSON response for errors.
@@ -99,6 +190,9 @@ type ErrorResponse struct {
 	Message string `json:"message"`
 	// Details contains additional error details.
 	Details map[string]any `json:"details,omitempty"`
+	// RequestID is the ID of the failed request, also sent in the
+	// X-Request-ID response header.
+	RequestID string `json:"request_id,omitempty"`
 }
 
 func (h *AdminHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
@@ -109,9 +203,10 @@ func (h *AdminHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	agent, err := h.registry.Create(r.Context(), registry.CreateInput{
-		ID:   req.AgentID,
-		Card: req.AgentCard,
-		Tags: req.Tags,
+		ID:       req.AgentID,
+		Card:     req.AgentCard,
+		Tags:     req.Tags,
+		LeaseTTL: time.Duration(req.TTLSeconds) * time.Second,
 	})
 	if err != nil {
 		if errors.Is(err, store.ErrAlreadyExists) {
@@ -128,6 +223,36 @@ func (h *AdminHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(toAgentResponse(agent))
 }
 
+func (h *AdminHandler) handleRegisterFromURL(w http.ResponseWriter, r *http.Request) {
+	var req RegisterFromURLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_JSON", "invalid JSON body")
+		return
+	}
+
+	agent, err := h.registry.RegisterFromURL(r.Context(), registry.RegisterFromURLInput{
+		URL:      req.URL,
+		ID:       req.AgentID,
+		Tags:     req.Tags,
+		LeaseTTL: time.Duration(req.TTLSeconds) * time.Second,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrAlreadyExists):
+			writeError(w, http.StatusConflict, "AGENT_EXISTS", "agent already exists")
+		case errors.Is(err, registry.ErrCardFetch):
+			writeError(w, http.StatusBadGateway, "CARD_FETCH_FAILED", err.Error())
+		default:
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(toAgentResponse(agent))
+}
+
 func (h *AdminHandler) handleGet(w http.ResponseWriter, r *http.Request) {
 	agentID := r.PathValue("id")
 
@@ -155,6 +280,22 @@ func (h *AdminHandler) handleList(w http.ResponseWriter, r *http.Request) {
 		limit = 20
 	}
 
+	sortField := store.SortField(query.Get("sort"))
+	switch sortField {
+	case "", store.SortByCreatedAt, store.SortByUpdatedAt, store.SortByName:
+	default:
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR",
+			"sort must be one of name, created_at, updated_at")
+		return
+	}
+	order := store.SortOrder(query.Get("order"))
+	switch order {
+	case "", store.SortAsc, store.SortDesc:
+	default:
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "order must be asc or desc")
+		return
+	}
+
 	var tags, skills []string
 	if t := query.Get("tags"); t != "" {
 		tags = strings.Split(t, ",")
@@ -166,11 +307,18 @@ func (h *AdminHandler) handleList(w http.ResponseWriter, r *http.Request) {
 	result, err := h.registry.List(r.Context(), registry.ListInput{
 		Offset: offset,
 		Limit:  limit,
+		Cursor: query.Get("cursor"),
+		Sort:   sortField,
+		Order:  order,
 		Tags:   tags,
 		Skills: skills,
 		Query:  query.Get("q"),
 	})
 	if err != nil {
+		if errors.Is(err, store.ErrInvalidCursor) {
+			writeError(w, http.StatusBadRequest, "INVALID_CURSOR", err.Error())
+			return
+		}
 		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
 		return
 	}
@@ -184,10 +332,11 @@ func (h *AdminHandler) handleList(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(AgentListResponse{
 		Agents: agents,
 		Pagination: PaginationResponse{
-			Total:   result.Total,
-			Offset:  offset,
-			Limit:   limit,
-			HasMore: offset+len(agents) < result.Total,
+			Total:      result.Total,
+			Offset:     offset,
+			Limit:      limit,
+			HasMore:    result.NextCursor != "",
+			NextCursor: result.NextCursor,
 		},
 	})
 }
@@ -236,6 +385,114 @@ func (h *AdminHandler) handleDelete(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *AdminHandler) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	agentID := r.PathValue("id")
+
+	result, err := h.registry.Refresh(r.Context(), agentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			writeError(w, http.StatusNotFound, "AGENT_NOT_FOUND",
+				"agent with ID '"+agentID+"' not found")
+		case errors.Is(err, registry.ErrNoSourceURL):
+			writeError(w, http.StatusConflict, "NO_SOURCE_URL",
+				"agent with ID '"+agentID+"' was not registered by URL")
+		case errors.Is(err, registry.ErrCardFetch):
+			writeError(w, http.StatusBadGateway, "CARD_FETCH_FAILED", err.Error())
+		default:
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(RefreshAgentResponse{
+		Agent:   toAgentResponse(result.Agent),
+		Changed: result.Changed,
+	})
+}
+
+func (h *AdminHandler) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
+	agentID := r.PathValue("id")
+
+	agent, err := h.registry.Heartbeat(r.Context(), agentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			writeError(w, http.StatusNotFound, "AGENT_NOT_FOUND",
+				"agent with ID '"+agentID+"' not found")
+		case errors.Is(err, registry.ErrNoLease):
+			writeError(w, http.StatusConflict, "NO_LEASE",
+				"agent with ID '"+agentID+"' was registered without a TTL")
+		default:
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(HeartbeatResponse{
+		AgentID:   agent.ID,
+		ExpiresAt: agent.ExpiresAt,
+	})
+}
+
+func (h *AdminHandler) handleStartReindex(w http.ResponseWriter, r *http.Request) {
+	var req ReindexRequest
+	// The body is optional.
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "INVALID_JSON", "invalid JSON body")
+		return
+	}
+	if req.BatchSize < 0 {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "batch_size must not be negative")
+		return
+	}
+
+	status, err := h.registry.StartReindex(r.Context(), registry.ReindexInput{BatchSize: req.BatchSize})
+	if err != nil {
+		switch {
+		case errors.Is(err, registry.ErrReindexRunning):
+			writeError(w, http.StatusConflict, "REINDEX_RUNNING", "a reindex is already running")
+		case errors.Is(err, registry.ErrNoEmbedder):
+			writeError(w, http.StatusConflict, "NO_EMBEDDER", "no embedder is configured")
+		case errors.Is(err, registry.ErrReindexUnsupported):
+			writeError(w, http.StatusNotImplemented, "REINDEX_UNSUPPORTED", "the store does not support reindexing")
+		default:
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(toReindexResponse(status))
+}
+
+func (h *AdminHandler) handleReindexStatus(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(toReindexResponse(h.registry.ReindexStatus()))
+}
+
+func toReindexResponse(status registry.ReindexStatus) ReindexStatusResponse {
+	resp := ReindexStatusResponse{
+		State:    string(status.State),
+		Target:   status.Target,
+		Previous: status.Previous,
+		Total:    status.Total,
+		Copied:   status.Copied,
+		Resumed:  status.Resumed,
+		Error:    status.Error,
+	}
+	if !status.StartedAt.IsZero() {
+		resp.StartedAt = &status.StartedAt
+	}
+	if !status.FinishedAt.IsZero() {
+		resp.FinishedAt = &status.FinishedAt
+	}
+	return resp
+}
+
 func toAgentResponse(agent *store.RegisteredAgent) AgentRecordResponse {
 	skills := make([]string, len(agent.Card.Skills))
 	for i, s := range agent.Card.Skills {
@@ -247,22 +504,53 @@ func toAgentResponse(agent *store.RegisteredAgent) AgentRecordResponse {
 		tags = []string{}
 	}
 
+	var expiresAt *time.Time
+	if !agent.ExpiresAt.IsZero() {
+		expiresAt = &agent.ExpiresAt
+	}
+
 	return AgentRecordResponse{
 		AgentID:      agent.ID,
 		AgentCard:    agent.Card,
 		Endpoint:     agent.Card.URL,
 		Skills:       skills,
 		Tags:         tags,
+		SourceURL:    agent.SourceURL,
+		Health:       toHealthResponse(agent.Health),
+		TTLSeconds:   int64(agent.LeaseTTL / time.Second),
+		ExpiresAt:    expiresAt,
 		RegisteredAt: agent.CreatedAt,
 		UpdatedAt:    agent.UpdatedAt,
 	}
 }
 
+func toHealthResponse(health store.AgentHealth) AgentHealthResponse {
+	status := string(health.Status)
+	if health.Status == store.HealthUnknown {
+		status = "unknown"
+	}
+
+	resp := AgentHealthResponse{
+		Status:              status,
+		ConsecutiveFailures: health.ConsecutiveFailures,
+		LatencyMs:           health.Latency.Milliseconds(),
+		LastError:           health.LastError,
+	}
+	if !health.LastSeen.IsZero() {
+		resp.LastSeen = &health.LastSeen
+	}
+	if !health.LastChecked.IsZero() {
+		resp.LastChecked = &health.LastChecked
+	}
+	return resp
+}
+
 func writeError(w http.ResponseWriter, status int, code, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	_ = json.NewEncoder(w).Encode(ErrorResponse{
-		Code:    code,
-		Message: message,
+		Code:      code,
+		Message:   message,
+		RequestID: w.Header().Get(logging.RequestIDHeader),
 	})
 }
@@ -6,9 +6,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/a2aproject/a2a-go/a2a"
 
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/logging"
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/registry"
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
 )
@@ -237,6 +239,58 @@ func TestAdminHandler_List(t *testing.T) {
 			t.Errorf("limit = %d, want 10", resp.Pagination.Limit)
 		}
 	})
+
+	t.Run("next cursor fetches the following page", func(t *testing.T) {
+		t.Parallel()
+		_, mux := setupHandler()
+		for _, id := range []string{"agent-a", "agent-b", "agent-c"} {
+			body := validRegisterRequest()
+			body.AgentID = id
+			mux.ServeHTTP(httptest.NewRecorder(), makeJSONRequest(http.MethodPost, "/v1/admin/agents", body))
+		}
+
+		var seen []string
+		path := "/v1/admin/agents?sort=name&limit=2"
+		for range 3 {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+			var resp AgentListResponse
+			_ = json.NewDecoder(rec.Body).Decode(&resp)
+			for _, agent := range resp.Agents {
+				seen = append(seen, agent.AgentID)
+			}
+			if resp.Pagination.HasMore != (resp.Pagination.NextCursor != "") {
+				t.Errorf("has_more = %v with next_cursor %q", resp.Pagination.HasMore, resp.Pagination.NextCursor)
+			}
+			if resp.Pagination.NextCursor == "" {
+				break
+			}
+			path = "/v1/admin/agents?sort=name&limit=2&cursor=" + resp.Pagination.NextCursor
+		}
+
+		if len(seen) != 3 || seen[0] != "agent-a" || seen[2] != "agent-c" {
+			t.Errorf("agents = %v, want [agent-a agent-b agent-c]", seen)
+		}
+	})
+
+	t.Run("invalid parameters return 400", func(t *testing.T) {
+		t.Parallel()
+		_, mux := setupHandler()
+		for path, code := range map[string]string{
+			"/v1/admin/agents?sort=tags":     "VALIDATION_ERROR",
+			"/v1/admin/agents?order=up":      "VALIDATION_ERROR",
+			"/v1/admin/agents?cursor=bogus!": "INVALID_CURSOR",
+		} {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+			var resp ErrorResponse
+			_ = json.NewDecoder(rec.Body).Decode(&resp)
+			if rec.Code != http.StatusBadRequest || resp.Code != code {
+				t.Errorf("GET %s = %d %s, want 400 %s", path, rec.Code, resp.Code, code)
+			}
+		}
+	})
 }
 
 func TestAdminHandler_Update(t *testing.T) {
@@ -319,6 +373,241 @@ func TestAdminHandler_Delete(t *testing.T) {
 	})
 }
 
+func TestAdminHandler_RegisterFromURL(t *testing.T) {
+	t.Parallel()
+
+	t.Run("fetched card returns 201", func(t *testing.T) {
+		t.Parallel()
+		cardServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(validAgentCard())
+		}))
+		t.Cleanup(cardServer.Close)
+		_, mux := setupHandler()
+
+		body := RegisterFromURLRequest{URL: cardServer.URL, Tags: []string{"remote"}}
+		req := makeJSONRequest(http.MethodPost, "/v1/admin/agents/from-url", body)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusCreated)
+		}
+		var resp AgentRecordResponse
+		_ = json.NewDecoder(rec.Body).Decode(&resp)
+		if resp.AgentID != "test-agent" {
+			t.Errorf("AgentID = %v, want test-agent", resp.AgentID)
+		}
+		if resp.SourceURL != cardServer.URL {
+			t.Errorf("SourceURL = %v, want %v", resp.SourceURL, cardServer.URL)
+		}
+
+		refreshReq := httptest.NewRequest(http.MethodPost, "/v1/admin/agents/test-agent/refresh", nil)
+		refreshRec := httptest.NewRecorder()
+		mux.ServeHTTP(refreshRec, refreshReq)
+
+		if refreshRec.Code != http.StatusOK {
+			t.Fatalf("refresh status = %d, want %d", refreshRec.Code, http.StatusOK)
+		}
+		var refreshResp RefreshAgentResponse
+		_ = json.NewDecoder(refreshRec.Body).Decode(&refreshResp)
+		if refreshResp.Changed {
+			t.Error("Changed = true, want false")
+		}
+	})
+
+	t.Run("unreachable card returns 502", func(t *testing.T) {
+		t.Parallel()
+		cardServer := httptest.NewServer(http.NotFoundHandler())
+		t.Cleanup(cardServer.Close)
+		_, mux := setupHandler()
+
+		body := RegisterFromURLRequest{URL: cardServer.URL}
+		req := makeJSONRequest(http.MethodPost, "/v1/admin/agents/from-url", body)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadGateway {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusBadGateway)
+		}
+	})
+
+	t.Run("refresh of agent not registered by URL returns 409", func(t *testing.T) {
+		t.Parallel()
+		_, mux := setupHandler()
+		createReq := makeJSONRequest(http.MethodPost, "/v1/admin/agents", validRegisterRequest())
+		mux.ServeHTTP(httptest.NewRecorder(), createReq)
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/admin/agents/test-agent/refresh", nil)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusConflict {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusConflict)
+		}
+	})
+}
+
+func TestAdminHandler_Heartbeat(t *testing.T) {
+	t.Parallel()
+
+	t.Run("leased agent returns 200", func(t *testing.T) {
+		t.Parallel()
+		_, mux := setupHandler()
+		body := validRegisterRequest()
+		body.TTLSeconds = 30
+		createRec := httptest.NewRecorder()
+		mux.ServeHTTP(createRec, makeJSONRequest(http.MethodPost, "/v1/admin/agents", body))
+		var created AgentRecordResponse
+		_ = json.NewDecoder(createRec.Body).Decode(&created)
+		if created.ExpiresAt == nil || created.TTLSeconds != 30 {
+			t.Fatalf("created lease = %v/%d, want expiry with 30s TTL", created.ExpiresAt, created.TTLSeconds)
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/admin/agents/test-agent/heartbeat", nil)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+		var resp HeartbeatResponse
+		_ = json.NewDecoder(rec.Body).Decode(&resp)
+		if resp.ExpiresAt.Before(*created.ExpiresAt) {
+			t.Errorf("ExpiresAt = %v, want not before %v", resp.ExpiresAt, *created.ExpiresAt)
+		}
+	})
+
+	t.Run("agent without lease returns 409", func(t *testing.T) {
+		t.Parallel()
+		_, mux := setupHandler()
+		mux.ServeHTTP(httptest.NewRecorder(), makeJSONRequest(http.MethodPost, "/v1/admin/agents", validRegisterRequest()))
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/admin/agents/test-agent/heartbeat", nil)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusConflict {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusConflict)
+		}
+	})
+
+	t.Run("non-existent returns 404", func(t *testing.T) {
+		t.Parallel()
+		_, mux := setupHandler()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/admin/agents/not-exists/heartbeat", nil)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+		}
+	})
+}
+
+func TestAdminHandler_Reindex(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reindex runs in the background", func(t *testing.T) {
+		t.Parallel()
+		svc := registry.NewRegistryService(store.NewMemoryStore(), registry.WithEmbedder(keywordEmbedder{}))
+		mux := http.NewServeMux()
+		NewAdminHandler(svc).RegisterRoutes(mux)
+		mux.ServeHTTP(httptest.NewRecorder(), makeJSONRequest(http.MethodPost, "/v1/admin/agents", validRegisterRequest()))
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, makeJSONRequest(http.MethodPost, "/v1/admin/reindex", ReindexRequest{BatchSize: 10}))
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusAccepted)
+		}
+
+		var status ReindexStatusResponse
+		deadline := time.Now().Add(5 * time.Second)
+		for status.State != string(registry.ReindexCompleted) {
+			if time.Now().After(deadline) {
+				t.Fatalf("reindex state = %q, want completed", status.State)
+			}
+			time.Sleep(10 * time.Millisecond)
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/reindex", nil))
+			status = ReindexStatusResponse{}
+			_ = json.NewDecoder(rec.Body).Decode(&status)
+		}
+		if status.Total != 1 || status.Copied != 1 || status.FinishedAt == nil {
+			t.Errorf("status = %+v, want 1/1 copied and finished", status)
+		}
+	})
+
+	t.Run("idle status", func(t *testing.T) {
+		t.Parallel()
+		_, mux := setupHandler()
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/reindex", nil))
+
+		var status ReindexStatusResponse
+		_ = json.NewDecoder(rec.Body).Decode(&status)
+		if rec.Code != http.StatusOK || status.State != string(registry.ReindexIdle) {
+			t.Errorf("GET = %d %q, want 200 idle", rec.Code, status.State)
+		}
+	})
+
+	t.Run("without embedder returns 409", func(t *testing.T) {
+		t.Parallel()
+		_, mux := setupHandler()
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/admin/reindex", nil))
+
+		if rec.Code != http.StatusConflict {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusConflict)
+		}
+	})
+
+	t.Run("negative batch size returns 400", func(t *testing.T) {
+		t.Parallel()
+		_, mux := setupHandler()
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, makeJSONRequest(http.MethodPost, "/v1/admin/reindex", ReindexRequest{BatchSize: -1}))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestWriteError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("includes request ID from response header", func(t *testing.T) {
+		t.Parallel()
+		rec := httptest.NewRecorder()
+		rec.Header().Set(logging.RequestIDHeader, "req-1")
+
+		writeError(rec, http.StatusNotFound, "AGENT_NOT_FOUND", "agent not found")
+
+		var body ErrorResponse
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("decode error body: %v", err)
+		}
+		if body.RequestID != "req-1" {
+			t.Errorf("RequestID = %q, want %q", body.RequestID, "req-1")
+		}
+	})
+
+	t.Run("omits missing request ID", func(t *testing.T) {
+		t.Parallel()
+		rec := httptest.NewRecorder()
+
+		writeError(rec, http.StatusNotFound, "AGENT_NOT_FOUND", "agent not found")
+
+		if bytes.Contains(rec.Body.Bytes(), []byte("request_id")) {
+			t.Errorf("body = %s, want no request_id", rec.Body.String())
+		}
+	})
+}
+
 func TestToAgentResponse(t *testing.T) {
 	t.Parallel()
 
@@ -23,6 +23,8 @@ func NewAgentsHandler(reg *registry.RegistryService) *AgentsHandler {
 // RegisterRoutes registers agent routes on the given ServeMux.
 func (h *AgentsHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /v1/agents/{id}/card", h.handleGetCard)
+	mux.HandleFunc("POST /v1/discover", h.handleDiscover)
+	mux.HandleFunc("POST /v1/route", h.handleRoute)
 }
 
 func (h *AgentsHandler) handleGetCard(w http.ResponseWriter, r *http.Request) {
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/a2aproject/a2a-go/a2a"
 	"github.com/a2aproject/a2a-go/a2asrv"
@@ -21,13 +22,15 @@ type BrokerHandler struct {
 
 // NewBrokerHandler creates a new A2A handler with the given agent and session service.
 func NewBrokerHandler(brokerAgent agent.Agent, sessionService session.Service) *BrokerHandler {
-	executor := adka2a.NewExecutor(adka2a.ExecutorConfig{
-		RunnerConfig: runner.Config{
-			AppName:        brokerAgent.Name(),
-			Agent:          brokerAgent,
-			SessionService: sessionService,
-		},
-	})
+	executor := &relayExecutor{
+		AgentExecutor: adka2a.NewExecutor(adka2a.ExecutorConfig{
+			RunnerConfig: runner.Config{
+				AppName:        brokerAgent.Name(),
+				Agent:          brokerAgent,
+				SessionService: sessionService,
+			},
+		}),
+	}
 
 	handler := a2asrv.NewHandler(executor)
 
@@ -37,7 +40,10 @@ func NewBrokerHandler(brokerAgent agent.Agent, sessionService session.Service) *
 		Name:        brokerAgent.Name(),
 		Description: brokerAgent.Description(),
 		Version:     "1.0.0",
-		Skills:      skills,
+		Capabilities: a2a.AgentCapabilities{
+			Streaming: true,
+		},
+		Skills: skills,
 	}
 
 	return &BrokerHandler{
@@ -48,6 +54,16 @@ func NewBrokerHandler(brokerAgent agent.Agent, sessionService session.Service) *
 
 // RegisterRoutes registers A2A routes on the given ServeMux.
 func (h *BrokerHandler) RegisterRoutes(mux *http.ServeMux) {
-	mux.Handle("POST /", a2asrv.NewJSONRPCHandler(h.handler))
+	mux.Handle("POST /", withoutWriteDeadline(a2asrv.NewJSONRPCHandler(h.handler)))
 	mux.Handle("GET /.well-known/agent-card.json", a2asrv.NewStaticAgentCardHandler(h.agentCard))
 }
+
+// withoutWriteDeadline clears the server's write deadline before serving
+// broker calls, which last as long as the agents they forward to and are
+// bounded by the dispatcher timeouts instead.
+func withoutWriteDeadline(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+		next.ServeHTTP(w, r)
+	})
+}
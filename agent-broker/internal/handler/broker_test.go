@@ -0,0 +1,374 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2aclient"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+	"google.golang.org/adk/model"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/agent"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/agent/tools"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/dispatch"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/logging"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/registry"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/server"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+	"github.com/lunarr-ai/lunarr/agent-broker/pkg/llm"
+)
+
+// keywords are the dimensions of keywordEmbedder vectors.
+var keywords = []string{"weather", "forecast", "translate", "language"}
+
+// keywordEmbedder embeds texts as keyword counts so that searches are deterministic.
+type keywordEmbedder struct{}
+
+func (keywordEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		text = strings.ToLower(text)
+		vector := make([]float32, len(keywords))
+		for j, keyword := range keywords {
+			vector[j] = float32(strings.Count(text, keyword)) + 0.01
+		}
+		embeddings[i] = vector
+	}
+	return embeddings, nil
+}
+
+func (keywordEmbedder) Dimensions() int {
+	return len(keywords)
+}
+
+// echoExecutor replies to every message with the agent name and the message
+// text, after delay.
+type echoExecutor struct {
+	name  string
+	delay time.Duration
+}
+
+func (e echoExecutor) Execute(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue) error {
+	select {
+	case <-time.After(e.delay):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	reply := a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: e.name + ": " + messageText(reqCtx.Message.Parts)})
+	return queue.Write(ctx, reply)
+}
+
+func (echoExecutor) Cancel(_ context.Context, _ *a2asrv.RequestContext, _ eventqueue.Queue) error {
+	return nil
+}
+
+// brokerSetup tunes the broker served by startBrokerWith.
+type brokerSetup struct {
+	// agentDelay is how long the downstream agents take to answer.
+	agentDelay time.Duration
+	// writeTimeout is the broker server's write timeout, 0 for none.
+	writeTimeout time.Duration
+	// logger, if set, serves the broker through the server middleware
+	// logging to it.
+	logger *slog.Logger
+}
+
+// startBroker serves the broker agent driven by m over a MemoryStore holding a
+// weather agent and a translation agent, and returns a client for it.
+func startBroker(t *testing.T, m model.LLM) *a2aclient.Client {
+	t.Helper()
+	return startBrokerWith(t, m, brokerSetup{})
+}
+
+// startBrokerWith is startBroker with the agents and server tuned by setup.
+func startBrokerWith(t *testing.T, m model.LLM, setup brokerSetup) *a2aclient.Client {
+	t.Helper()
+	ctx := context.Background()
+
+	reg := registry.NewRegistryService(store.NewMemoryStore(), registry.WithEmbedder(keywordEmbedder{}))
+	agents := []struct {
+		id, name, description string
+	}{
+		{"weather", "Weather Agent", "Answers weather and forecast questions"},
+		{"translator", "Translator Agent", "Can translate text to any language"},
+	}
+	for _, a := range agents {
+		agentServer := httptest.NewServer(a2asrv.NewJSONRPCHandler(a2asrv.NewHandler(echoExecutor{name: a.id, delay: setup.agentDelay})))
+		t.Cleanup(agentServer.Close)
+
+		_, err := reg.Create(ctx, registry.CreateInput{
+			ID: a.id,
+			Card: a2a.AgentCard{
+				Name:        a.name,
+				Description: a.description,
+				URL:         agentServer.URL,
+				Version:     "1.0.0",
+				Skills:      []a2a.AgentSkill{{ID: a.id, Name: a.name}},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Create(%s) error = %v", a.id, err)
+		}
+	}
+
+	brokerAgent, err := agent.NewBrokerAgent(ctx, reg,
+		agent.WithModel(m),
+		agent.WithDispatcher(dispatch.NewDispatcher()),
+	)
+	if err != nil {
+		t.Fatalf("NewBrokerAgent() error = %v", err)
+	}
+
+	mux := http.NewServeMux()
+	NewBrokerHandler(brokerAgent, agent.NewSessionService()).RegisterRoutes(mux)
+	var brokerHandler http.Handler = mux
+	if setup.logger != nil {
+		brokerHandler = server.New(mux, server.WithLogger(setup.logger)).Handler()
+	}
+	brokerServer := httptest.NewUnstartedServer(brokerHandler)
+	brokerServer.Config.WriteTimeout = setup.writeTimeout
+	brokerServer.Start()
+	t.Cleanup(brokerServer.Close)
+
+	client, err := a2aclient.NewFromCard(ctx,
+		&a2a.AgentCard{Name: "Broker", URL: brokerServer.URL, PreferredTransport: a2a.TransportProtocolJSONRPC},
+		a2aclient.WithJSONRPCTransport(brokerServer.Client()),
+	)
+	if err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Destroy() })
+	return client
+}
+
+// ask sends text to the broker and returns the text of its completed task.
+func ask(t *testing.T, client *a2aclient.Client, text string) string {
+	t.Helper()
+	result, err := client.SendMessage(context.Background(), &a2a.MessageSendParams{
+		Message: a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: text}),
+	})
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	switch v := result.(type) {
+	case *a2a.Message:
+		return messageText(v.Parts)
+	case *a2a.Task:
+		var texts []string
+		for _, artifact := range v.Artifacts {
+			texts = append(texts, messageText(artifact.Parts))
+		}
+		if v.Status.Message != nil {
+			texts = append(texts, messageText(v.Status.Message.Parts))
+		}
+		if v.Status.State != a2a.TaskStateCompleted {
+			t.Fatalf("task state = %v, want completed: %s", v.Status.State, strings.Join(texts, "\n"))
+		}
+		return strings.Join(texts, "\n")
+	default:
+		t.Fatalf("SendMessage() result = %T, want task or message", result)
+		return ""
+	}
+}
+
+// messageText concatenates the text parts of a message or artifact.
+func messageText(parts a2a.ContentParts) string {
+	var texts []string
+	for _, part := range parts {
+		switch p := part.(type) {
+		case a2a.TextPart:
+			texts = append(texts, p.Text)
+		case *a2a.TextPart:
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// toolResult decodes the response of the named tool from the last request sent to m.
+func toolResult(t *testing.T, m *llm.ScriptedModel, name string, out any) {
+	t.Helper()
+	requests := m.Requests()
+	if len(requests) == 0 {
+		t.Fatal("model received no requests")
+	}
+
+	for _, content := range requests[len(requests)-1].Contents {
+		for _, part := range content.Parts {
+			if part.FunctionResponse == nil || part.FunctionResponse.Name != name {
+				continue
+			}
+			response := part.FunctionResponse.Response
+			if errMsg, ok := response["error"]; ok {
+				t.Fatalf("%s tool error = %v", name, errMsg)
+			}
+			var payload any = response
+			if wrapped, ok := response["result"]; ok && len(response) == 1 {
+				payload = wrapped
+			}
+			data, _ := json.Marshal(payload)
+			if err := json.Unmarshal(data, out); err != nil {
+				t.Fatalf("decode %s result: %v", name, err)
+			}
+			return
+		}
+	}
+	t.Fatalf("no %s tool response in last model request", name)
+}
+
+func TestBrokerHandler_EndToEnd(t *testing.T) {
+	t.Parallel()
+
+	t.Run("discover returns ranked agents", func(t *testing.T) {
+		t.Parallel()
+		m := llm.NewScriptedModel(
+			llm.CallTurn("discover", map[string]any{"query": "weather forecast"}),
+			llm.TextTurn("The Weather Agent can help."),
+		)
+		client := startBroker(t, m)
+
+		answer := ask(t, client, "Who can tell me the weather?")
+
+		if !strings.Contains(answer, "The Weather Agent can help.") {
+			t.Errorf("answer = %q, want scripted reply", answer)
+		}
+		var result tools.DiscoverResult
+		toolResult(t, m, "discover", &result)
+		if result.Total != 2 || result.Agents[0].AgentID != "weather" {
+			t.Errorf("discover result = %+v, want weather ranked first of 2", result)
+		}
+		if m.Remaining() != 0 {
+			t.Errorf("Remaining() = %d, want 0", m.Remaining())
+		}
+	})
+
+	t.Run("route forwards to best agent", func(t *testing.T) {
+		t.Parallel()
+		m := llm.NewScriptedModel(
+			llm.CallTurn("route", map[string]any{"query": "translate language"}),
+			llm.TextTurn("Translated."),
+		)
+		client := startBroker(t, m)
+
+		answer := ask(t, client, "Translate hello to French")
+
+		if !strings.Contains(answer, "Translated.") {
+			t.Errorf("answer = %q, want scripted reply", answer)
+		}
+		var result tools.RouteResult
+		toolResult(t, m, "route", &result)
+		if !result.Found || result.Agent.AgentID != "translator" {
+			t.Fatalf("route result = %+v, want translator", result)
+		}
+		if result.Response == nil || result.Response.Text != "translator: Translate hello to French" {
+			t.Errorf("route response = %+v, want forwarded user message", result.Response)
+		}
+	})
+
+	t.Run("broadcast collects every answer", func(t *testing.T) {
+		t.Parallel()
+		m := llm.NewScriptedModel(
+			llm.CallTurn("broadcast", map[string]any{"query": "agents", "message": "ping", "limit": 2}),
+			llm.TextTurn("Both agents answered."),
+		)
+		client := startBroker(t, m)
+
+		ask(t, client, "Ping everyone")
+
+		var result tools.BroadcastResult
+		toolResult(t, m, "broadcast", &result)
+		if result.Succeeded != 2 || result.Failed != 0 || result.TimedOut != 0 {
+			t.Errorf("broadcast result = %+v, want 2 succeeded", result)
+		}
+		for _, resp := range result.Responses {
+			if resp.Response == nil || resp.Response.Text != resp.AgentID+": ping" {
+				t.Errorf("response of %s = %+v, want echo of ping", resp.AgentID, resp.Response)
+			}
+		}
+	})
+}
+
+func TestBrokerHandler_SlowAgent(t *testing.T) {
+	t.Parallel()
+	m := llm.NewScriptedModel(
+		llm.CallTurn("route", map[string]any{"query": "translate language"}),
+		llm.TextTurn("Translated."),
+	)
+	client := startBrokerWith(t, m, brokerSetup{
+		agentDelay:   500 * time.Millisecond,
+		writeTimeout: 100 * time.Millisecond,
+	})
+
+	answer := ask(t, client, "Translate hello to French")
+
+	if !strings.Contains(answer, "Translated.") {
+		t.Errorf("answer = %q, want scripted reply", answer)
+	}
+	var result tools.RouteResult
+	toolResult(t, m, "route", &result)
+	if result.Response == nil || result.Response.Text != "translator: Translate hello to French" {
+		t.Errorf("route response = %+v, want forwarded user message", result.Response)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestBrokerHandler_RequestLogCarriesTask(t *testing.T) {
+	t.Parallel()
+	var logs syncBuffer
+	m := llm.NewScriptedModel(llm.TextTurn("Hello."))
+	client := startBrokerWith(t, m, brokerSetup{
+		logger: slog.New(logging.NewContextHandler(slog.NewJSONHandler(&logs, nil))),
+	})
+
+	ask(t, client, "Hi")
+
+	// The request line is written after the response is sent.
+	deadline := time.Now().Add(time.Second)
+	for {
+		for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+			var record map[string]any
+			if json.Unmarshal([]byte(line), &record) != nil || record["msg"] != "request" {
+				continue
+			}
+			if id, _ := record["a2a_task_id"].(string); id == "" {
+				t.Fatalf("request log line %s has no a2a_task_id", line)
+			}
+			if id, _ := record["a2a_context_id"].(string); id == "" {
+				t.Errorf("request log line %s has no a2a_context_id", line)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("no request log line in %q", logs.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
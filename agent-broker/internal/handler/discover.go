@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/registry"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+)
+
+// routeCandidates is how many agents a route request ranks, so that near
+// misses can be reported when none is confident.
+const routeCandidates = 3
+
+// DiscoverRequest is the JSON request for discovering agents.
+type DiscoverRequest struct {
+	// Query is the natural language description of the task.
+	Query string `json:"query"`
+	// Tags filters by any matching tag.
+	Tags []string `json:"tags,omitempty"`
+	// Skills filters by any matching skill ID.
+	Skills []string `json:"skills,omitempty"`
+	// Limit is the maximum number of agents, 10 if 0 and at most 50.
+	Limit int `json:"limit,omitempty"`
+	// MinScore overrides the minimum embedding similarity (0-1) an agent needs.
+	MinScore *float32 `json:"min_score,omitempty"`
+	// MinRelativeScore overrides the fraction (0-1) of the best score an agent needs.
+	MinRelativeScore *float32 `json:"min_relative_score,omitempty"`
+}
+
+// RouteRequest is the JSON request for picking the best agent for a task.
+type RouteRequest struct {
+	// Query is the natural language description of the task.
+	Query string `json:"query"`
+	// Tags filters by any matching tag.
+	Tags []string `json:"tags,omitempty"`
+	// Skills filters by any matching skill ID.
+	Skills []string `json:"skills,omitempty"`
+	// MinScore overrides the minimum embedding similarity (0-1) the agent needs.
+	MinScore *float32 `json:"min_score,omitempty"`
+	// MinRelativeScore overrides the fraction (0-1) of the best score an agent needs.
+	MinRelativeScore *float32 `json:"min_relative_score,omitempty"`
+}
+
+// ScoredAgentResponse is the JSON representation of a discovered agent.
+type ScoredAgentResponse struct {
+	// Agent is the agent record.
+	Agent AgentRecordResponse `json:"agent"`
+	// Score is the relevance score, higher is better.
+	Score float32 `json:"score"`
+	// Similarity is the embedding similarity (0-1) to the query that
+	// min_score is compared against.
+	Similarity float32 `json:"similarity"`
+	// MatchedSkill is the ID of the skill that best matched the query.
+	MatchedSkill string `json:"matched_skill,omitempty"`
+}
+
+// DiscoverResponse is the JSON response for discovering agents.
+type DiscoverResponse struct {
+	// Agents is the list of matching agents, best first.
+	Agents []ScoredAgentResponse `json:"agents"`
+	// Total is the number of agents returned.
+	Total int `json:"total"`
+	// NearMisses are the best agents scoring below the thresholds.
+	NearMisses []ScoredAgentResponse `json:"near_misses,omitempty"`
+}
+
+// RouteResponse is the JSON response for picking the best agent for a task.
+type RouteResponse struct {
+	// Found indicates whether an agent matched.
+	Found bool `json:"found"`
+	// Agent is the best matching agent.
+	Agent *ScoredAgentResponse `json:"agent,omitempty"`
+	// Reason explains why no agent was found.
+	Reason string `json:"reason,omitempty"`
+	// NearMisses are the best agents scoring below the thresholds when none was found.
+	NearMisses []ScoredAgentResponse `json:"near_misses,omitempty"`
+}
+
+func (h *AgentsHandler) handleDiscover(w http.ResponseWriter, r *http.Request) {
+	var req DiscoverRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_JSON", "invalid JSON body")
+		return
+	}
+
+	result, ok := h.discover(w, r, registry.DiscoverInput{
+		Query:            req.Query,
+		Limit:            req.Limit,
+		Tags:             req.Tags,
+		Skills:           req.Skills,
+		MinScore:         req.MinScore,
+		MinRelativeScore: req.MinRelativeScore,
+	})
+	if !ok {
+		return
+	}
+
+	agents := toScoredAgentResponses(result.Agents)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(DiscoverResponse{
+		Agents:     agents,
+		Total:      len(agents),
+		NearMisses: toScoredAgentResponses(result.NearMisses),
+	})
+}
+
+func (h *AgentsHandler) handleRoute(w http.ResponseWriter, r *http.Request) {
+	var req RouteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_JSON", "invalid JSON body")
+		return
+	}
+
+	result, ok := h.discover(w, r, registry.DiscoverInput{
+		Query:            req.Query,
+		Limit:            routeCandidates,
+		Tags:             req.Tags,
+		Skills:           req.Skills,
+		MinScore:         req.MinScore,
+		MinRelativeScore: req.MinRelativeScore,
+	})
+	if !ok {
+		return
+	}
+
+	resp := RouteResponse{
+		Reason:     result.Reason(),
+		NearMisses: toScoredAgentResponses(result.NearMisses),
+	}
+	if len(result.Agents) > 0 {
+		agent := toScoredAgentResponse(result.Agents[0])
+		resp = RouteResponse{Found: true, Agent: &agent}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// discover validates input and runs it, writing the error response and
+// returning false if it fails.
+func (h *AgentsHandler) discover(w http.ResponseWriter, r *http.Request, input registry.DiscoverInput) (*registry.DiscoverResult, bool) {
+	if strings.TrimSpace(input.Query) == "" {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "query is required")
+		return nil, false
+	}
+	if input.Limit < 0 {
+		writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "limit must not be negative")
+		return nil, false
+	}
+
+	result, err := h.registry.Discover(r.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, registry.ErrNoEmbedder):
+			writeError(w, http.StatusServiceUnavailable, "NO_EMBEDDER", "semantic search is not configured")
+		case errors.Is(err, registry.ErrInvalidThresholds):
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+		}
+		return nil, false
+	}
+	return result, true
+}
+
+func toScoredAgentResponses(hits []store.ScoredAgent) []ScoredAgentResponse {
+	agents := make([]ScoredAgentResponse, len(hits))
+	for i, scored := range hits {
+		agents[i] = toScoredAgentResponse(scored)
+	}
+	return agents
+}
+
+func toScoredAgentResponse(scored store.ScoredAgent) ScoredAgentResponse {
+	return ScoredAgentResponse{
+		Agent:        toAgentResponse(scored.Agent),
+		Score:        scored.Score,
+		Similarity:   scored.Similarity,
+		MatchedSkill: scored.MatchedSkill,
+	}
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/registry"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+)
+
+func score(v float32) *float32 {
+	return &v
+}
+
+// setupDiscoverHandler serves the public routes over a weather agent and a
+// translation agent embedded with keywordEmbedder.
+func setupDiscoverHandler(t *testing.T) *http.ServeMux {
+	t.Helper()
+	reg := registry.NewRegistryService(store.NewMemoryStore(), registry.WithEmbedder(keywordEmbedder{}))
+	agents := []struct {
+		id, description, skill string
+	}{
+		{id: "weather", description: "Weather forecast for any city", skill: "forecast"},
+		{id: "translator", description: "Translate text to another language", skill: "translate"},
+	}
+	for _, a := range agents {
+		card := validAgentCard()
+		card.Name = a.id
+		card.Description = a.description
+		card.Skills = []a2a.AgentSkill{{ID: a.skill, Name: a.skill}}
+		if _, err := reg.Create(context.Background(), registry.CreateInput{ID: a.id, Card: card}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	mux := http.NewServeMux()
+	NewAgentsHandler(reg).RegisterRoutes(mux)
+	return mux
+}
+
+func TestAgentsHandler_Discover(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       any
+		wantStatus int
+		wantIDs    []string
+	}{
+		{name: "ranks by relevance", body: DiscoverRequest{Query: "weather forecast"}, wantStatus: http.StatusOK, wantIDs: []string{"weather", "translator"}},
+		{name: "limit", body: DiscoverRequest{Query: "translate", Limit: 1}, wantStatus: http.StatusOK, wantIDs: []string{"translator"}},
+		{name: "skill filter", body: DiscoverRequest{Query: "weather", Skills: []string{"translate"}}, wantStatus: http.StatusOK, wantIDs: []string{"translator"}},
+		{name: "min score drops weak matches", body: DiscoverRequest{Query: "weather forecast", MinScore: score(0.5)}, wantStatus: http.StatusOK, wantIDs: []string{"weather"}},
+		{name: "relative gap drops weak matches", body: DiscoverRequest{Query: "weather forecast", MinRelativeScore: score(0.8)}, wantStatus: http.StatusOK, wantIDs: []string{"weather"}},
+		{name: "missing query", body: DiscoverRequest{}, wantStatus: http.StatusBadRequest},
+		{name: "out of range threshold", body: DiscoverRequest{Query: "weather", MinScore: score(2)}, wantStatus: http.StatusBadRequest},
+		{name: "invalid JSON", body: "not json", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mux := setupDiscoverHandler(t)
+			rec := httptest.NewRecorder()
+
+			mux.ServeHTTP(rec, makeJSONRequest(http.MethodPost, "/v1/discover", tt.body))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp DiscoverResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			var gotIDs []string
+			for _, scored := range resp.Agents {
+				gotIDs = append(gotIDs, scored.Agent.AgentID)
+			}
+			if len(gotIDs) != len(tt.wantIDs) || resp.Total != len(tt.wantIDs) {
+				t.Fatalf("agents = %v (total %d), want %v", gotIDs, resp.Total, tt.wantIDs)
+			}
+			for i := range gotIDs {
+				if gotIDs[i] != tt.wantIDs[i] {
+					t.Errorf("agents = %v, want %v", gotIDs, tt.wantIDs)
+					break
+				}
+			}
+			for _, scored := range resp.Agents {
+				if scored.Similarity <= 0 || scored.Similarity > 1 {
+					t.Errorf("%s similarity = %v, want in (0, 1]", scored.Agent.AgentID, scored.Similarity)
+				}
+				if req, ok := tt.body.(DiscoverRequest); ok && req.MinScore != nil && scored.Similarity < *req.MinScore {
+					t.Errorf("%s similarity = %v, below min_score %v", scored.Agent.AgentID, scored.Similarity, *req.MinScore)
+				}
+			}
+		})
+	}
+}
+
+func TestAgentsHandler_Route(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		body           RouteRequest
+		wantFound      bool
+		wantID         string
+		wantSkill      string
+		wantReason     string
+		wantNearMisses int
+	}{
+		{name: "best agent", body: RouteRequest{Query: "translate this"}, wantFound: true, wantID: "translator", wantSkill: "translate"},
+		{name: "no confident match", body: RouteRequest{Query: "weather", MinScore: score(0.99)}, wantReason: registry.ReasonNoConfidentMatch, wantNearMisses: 2},
+		{name: "no match", body: RouteRequest{Query: "weather", Skills: []string{"unknown"}}, wantReason: registry.ReasonNoMatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mux := setupDiscoverHandler(t)
+			rec := httptest.NewRecorder()
+
+			mux.ServeHTTP(rec, makeJSONRequest(http.MethodPost, "/v1/route", tt.body))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+			}
+			var resp RouteResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.Found != tt.wantFound {
+				t.Fatalf("Found = %v, want %v", resp.Found, tt.wantFound)
+			}
+			if !tt.wantFound {
+				if resp.Agent != nil {
+					t.Errorf("Agent = %+v, want nil", resp.Agent)
+				}
+				if resp.Reason != tt.wantReason || len(resp.NearMisses) != tt.wantNearMisses {
+					t.Errorf("Reason = %q with %d near misses, want %q with %d",
+						resp.Reason, len(resp.NearMisses), tt.wantReason, tt.wantNearMisses)
+				}
+				return
+			}
+			if resp.Agent.Agent.AgentID != tt.wantID || resp.Agent.MatchedSkill != tt.wantSkill {
+				t.Errorf("Agent = %s/%s, want %s/%s", resp.Agent.Agent.AgentID, resp.Agent.MatchedSkill, tt.wantID, tt.wantSkill)
+			}
+		})
+	}
+
+	t.Run("without embedder returns 503", func(t *testing.T) {
+		t.Parallel()
+		mux := http.NewServeMux()
+		NewAgentsHandler(registry.NewRegistryService(store.NewMemoryStore())).RegisterRoutes(mux)
+		rec := httptest.NewRecorder()
+
+		mux.ServeHTTP(rec, makeJSONRequest(http.MethodPost, "/v1/route", RouteRequest{Query: "weather"}))
+
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/dispatch"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/logging"
+)
+
+// tracer creates the spans of broker task executions.
+var tracer = otel.Tracer("github.com/lunarr-ai/lunarr/agent-broker/internal/handler")
+
+// RelayMetadataKey is the event metadata key identifying the downstream agent
+// that produced a relayed event.
+const RelayMetadataKey = "broker"
+
+// relayExecutor wraps an AgentExecutor so that status and artifact updates of
+// downstream agents reached while handling a request are streamed to the
+// broker's caller as part of the broker task.
+type relayExecutor struct {
+	a2asrv.AgentExecutor
+}
+
+// Execute installs a dispatch event sink that writes relayed events to queue
+// and runs the broker agent in a span covering the whole task execution. Logs
+// written during the execution, and the request log line, carry the task and
+// context IDs.
+func (e *relayExecutor) Execute(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue) (err error) {
+	ctx, span := tracer.Start(ctx, "broker.execute", trace.WithAttributes(
+		attribute.String("a2a.task_id", string(reqCtx.TaskID)),
+		attribute.String("a2a.context_id", reqCtx.ContextID),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	ctx = logging.WithTask(ctx, string(reqCtx.TaskID), reqCtx.ContextID)
+	ctx = dispatch.WithEventSink(ctx, func(ctx context.Context, source dispatch.Target, event a2a.Event) error {
+		relayed := relayEvent(reqCtx, source, event)
+		if relayed == nil {
+			return nil
+		}
+		return queue.Write(ctx, relayed)
+	})
+	return e.AgentExecutor.Execute(ctx, reqCtx, queue)
+}
+
+// relayEvent rebinds a downstream event to the broker task and annotates it
+// with the agent that produced it. Downstream status updates are reported as
+// non-final working updates because the broker task outlives them.
+func relayEvent(info a2a.TaskInfoProvider, source dispatch.Target, event a2a.Event) a2a.Event {
+	taskInfo := info.TaskInfo()
+
+	switch v := event.(type) {
+	case *a2a.TaskStatusUpdateEvent:
+		status := a2a.TaskStatus{
+			State:     a2a.TaskStateWorking,
+			Timestamp: v.Status.Timestamp,
+		}
+		if v.Status.Message != nil {
+			msg := *v.Status.Message
+			msg.TaskID = taskInfo.TaskID
+			msg.ContextID = taskInfo.ContextID
+			status.Message = &msg
+		}
+		return &a2a.TaskStatusUpdateEvent{
+			TaskID:    taskInfo.TaskID,
+			ContextID: taskInfo.ContextID,
+			Status:    status,
+			Final:     false,
+			Metadata:  relayMetadata(v.Metadata, source, v.TaskID, v.Status.State),
+		}
+	case *a2a.TaskArtifactUpdateEvent:
+		if v.Artifact == nil {
+			return nil
+		}
+		artifact := *v.Artifact
+		// Prefix artifact IDs so that agents in a broadcast cannot collide.
+		artifact.ID = a2a.ArtifactID(source.AgentID + "/" + string(artifact.ID))
+		return &a2a.TaskArtifactUpdateEvent{
+			TaskID:    taskInfo.TaskID,
+			ContextID: taskInfo.ContextID,
+			Artifact:  &artifact,
+			Append:    v.Append,
+			LastChunk: v.LastChunk,
+			Metadata:  relayMetadata(v.Metadata, source, v.TaskID, ""),
+		}
+	default:
+		return nil
+	}
+}
+
+// relayMetadata copies downstream metadata and adds the broker annotation.
+func relayMetadata(src map[string]any, source dispatch.Target, taskID a2a.TaskID, state a2a.TaskState) map[string]any {
+	meta := make(map[string]any, len(src)+1)
+	for k, v := range src {
+		meta[k] = v
+	}
+
+	broker := map[string]any{
+		"agent_id": source.AgentID,
+		"task_id":  string(taskID),
+	}
+	if source.Card != nil {
+		broker["agent_name"] = source.Card.Name
+	}
+	if state != "" {
+		broker["state"] = string(state)
+	}
+	meta[RelayMetadataKey] = broker
+
+	return meta
+}
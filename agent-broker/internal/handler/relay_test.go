@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/dispatch"
+)
+
+func TestRelayEvent(t *testing.T) {
+	t.Parallel()
+
+	broker := &a2a.Task{ID: "broker-task", ContextID: "broker-ctx"}
+	downstream := &a2a.Task{ID: "remote-task", ContextID: "remote-ctx"}
+	source := dispatch.Target{
+		AgentID: "remote",
+		Card:    &a2a.AgentCard{Name: "Remote Agent"},
+	}
+
+	t.Run("status update is rebound and never final", func(t *testing.T) {
+		t.Parallel()
+		event := a2a.NewStatusUpdateEvent(downstream, a2a.TaskStateCompleted,
+			a2a.NewMessageForTask(a2a.MessageRoleAgent, downstream, a2a.TextPart{Text: "done"}))
+		event.Final = true
+
+		relayed, ok := relayEvent(broker, source, event).(*a2a.TaskStatusUpdateEvent)
+
+		if !ok {
+			t.Fatalf("relayEvent() type = %T, want *a2a.TaskStatusUpdateEvent", relayed)
+		}
+		if relayed.TaskID != "broker-task" || relayed.ContextID != "broker-ctx" {
+			t.Errorf("relayed task = %v/%v, want broker-task/broker-ctx", relayed.TaskID, relayed.ContextID)
+		}
+		if relayed.Final {
+			t.Error("relayed status update should not be final")
+		}
+		if relayed.Status.State != a2a.TaskStateWorking {
+			t.Errorf("relayed state = %v, want working", relayed.Status.State)
+		}
+		if relayed.Status.Message.TaskID != "broker-task" {
+			t.Errorf("relayed message task = %v, want broker-task", relayed.Status.Message.TaskID)
+		}
+		meta, _ := relayed.Metadata[RelayMetadataKey].(map[string]any)
+		if meta["agent_id"] != "remote" || meta["state"] != "completed" {
+			t.Errorf("relayed metadata = %v, want agent_id remote and state completed", meta)
+		}
+	})
+
+	t.Run("artifact update is rebound with prefixed ID", func(t *testing.T) {
+		t.Parallel()
+		event := a2a.NewArtifactUpdateEvent(downstream, "result", a2a.TextPart{Text: "chunk"})
+		event.Append = false
+
+		relayed, ok := relayEvent(broker, source, event).(*a2a.TaskArtifactUpdateEvent)
+
+		if !ok {
+			t.Fatalf("relayEvent() type = %T, want *a2a.TaskArtifactUpdateEvent", relayed)
+		}
+		if relayed.TaskID != "broker-task" {
+			t.Errorf("relayed task = %v, want broker-task", relayed.TaskID)
+		}
+		if relayed.Artifact.ID != "remote/result" {
+			t.Errorf("relayed artifact ID = %v, want remote/result", relayed.Artifact.ID)
+		}
+		if event.Artifact.ID != "result" {
+			t.Error("relayEvent() should not modify the downstream artifact")
+		}
+	})
+
+	t.Run("other events are dropped", func(t *testing.T) {
+		t.Parallel()
+		if relayed := relayEvent(broker, source, downstream); relayed != nil {
+			t.Errorf("relayEvent() = %T, want nil", relayed)
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// RequestIDHeader is the HTTP header carrying the request ID, accepted from
+// callers and echoed in responses.
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is the context key of the request ID.
+type requestIDKey struct{}
+
+// taskKey is the context key of the A2A task the request belongs to.
+type taskKey struct{}
+
+// task identifies an A2A task. It is shared by the contexts derived from the
+// one it was stored in, so that IDs recorded by a handler are seen by the
+// middleware that logs the request.
+type task struct {
+	// mu guards id and contextID.
+	mu sync.Mutex
+	// id is the A2A task ID.
+	id string
+	// contextID is the A2A context ID.
+	contextID string
+}
+
+// ids returns the task and context IDs.
+func (t *task) ids() (string, string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.id, t.contextID
+}
+
+// WithRequestID returns ctx carrying the request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx, or "" if none.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithTaskScope returns ctx in which a later WithTask also records the task
+// for ctx itself. Request middleware uses it to log the task a handler worked on.
+func WithTaskScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, taskKey{}, &task{})
+}
+
+// WithTask returns ctx carrying the A2A task and context IDs. Inside a
+// WithTaskScope the IDs are recorded in the scope.
+func WithTask(ctx context.Context, taskID, contextID string) context.Context {
+	if t, ok := ctx.Value(taskKey{}).(*task); ok {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.id, t.contextID = taskID, contextID
+		return ctx
+	}
+	return context.WithValue(ctx, taskKey{}, &task{id: taskID, contextID: contextID})
+}
+
+// ContextHandler is a slog.Handler that adds the request ID and A2A task and
+// context IDs stored in the record's context, so that every line logged with
+// a request context can be correlated.
+type ContextHandler struct {
+	// next is the handler records are passed to.
+	next slog.Handler
+}
+
+// NewContextHandler returns a ContextHandler passing records to next.
+func NewContextHandler(next slog.Handler) *ContextHandler {
+	return &ContextHandler{next: next}
+}
+
+// Enabled reports whether next handles records at level.
+func (h *ContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle adds the correlation IDs in ctx to r and passes it to next.
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestIDFromContext(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if t, ok := ctx.Value(taskKey{}).(*task); ok {
+		id, contextID := t.ids()
+		if id != "" {
+			r.AddAttrs(slog.String("a2a_task_id", id))
+		}
+		if contextID != "" {
+			r.AddAttrs(slog.String("a2a_context_id", contextID))
+		}
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs returns a ContextHandler whose next handler has attrs.
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{next: h.next.WithAttrs(attrs)}
+}
+
+// WithGroup returns a ContextHandler whose next handler opens group.
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{next: h.next.WithGroup(name)}
+}
@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestContextHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want map[string]any
+	}{
+		{
+			name: "no correlation IDs",
+			ctx:  context.Background(),
+			want: map[string]any{},
+		},
+		{
+			name: "request ID",
+			ctx:  WithRequestID(context.Background(), "req-1"),
+			want: map[string]any{"request_id": "req-1"},
+		},
+		{
+			name: "request and task IDs",
+			ctx:  WithTask(WithRequestID(context.Background(), "req-1"), "task-1", "ctx-1"),
+			want: map[string]any{"request_id": "req-1", "a2a_task_id": "task-1", "a2a_context_id": "ctx-1"},
+		},
+		{
+			name: "task without context ID",
+			ctx:  WithTask(context.Background(), "task-1", ""),
+			want: map[string]any{"a2a_task_id": "task-1"},
+		},
+		{
+			name: "task recorded in scope",
+			ctx: func() context.Context {
+				ctx := WithTaskScope(WithRequestID(context.Background(), "req-1"))
+				_ = WithTask(context.WithoutCancel(ctx), "task-1", "ctx-1")
+				return ctx
+			}(),
+			want: map[string]any{"request_id": "req-1", "a2a_task_id": "task-1", "a2a_context_id": "ctx-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			logger := slog.New(NewContextHandler(slog.NewJSONHandler(&buf, nil))).With("component", "test")
+
+			logger.InfoContext(tt.ctx, "hello")
+
+			var record map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatalf("log line %q is not JSON: %v", buf.String(), err)
+			}
+			if record["component"] != "test" {
+				t.Errorf("component = %v, want %q", record["component"], "test")
+			}
+			for _, key := range []string{"request_id", "a2a_task_id", "a2a_context_id"} {
+				want, ok := tt.want[key]
+				if got, present := record[key]; present != ok || got != want {
+					t.Errorf("%s = %v (present %t), want %v (present %t)", key, got, present, want, ok)
+				}
+			}
+		})
+	}
+}
+
+func TestRequestIDFromContext(t *testing.T) {
+	t.Parallel()
+
+	if got := RequestIDFromContext(context.Background()); got != "" {
+		t.Errorf("RequestIDFromContext() = %q, want empty", got)
+	}
+	if got := RequestIDFromContext(WithRequestID(context.Background(), "req-1")); got != "req-1" {
+		t.Errorf("RequestIDFromContext() = %q, want %q", got, "req-1")
+	}
+}
@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/pkg/embedding"
+)
+
+// agentCountTimeout bounds counting registered agents during a scrape.
+const agentCountTimeout = 10 * time.Second
+
+// embedder records the latency and errors of the calls to an Embedder.
+type embedder struct {
+	// next is the instrumented embedder.
+	next embedding.Embedder
+	// metrics records the calls.
+	metrics *Metrics
+}
+
+// InstrumentEmbedder returns e recording the latency and errors of its
+// calls, or e itself if m is nil. Wrap the embedding client rather than a
+// cache so that only calls to the embedding service are recorded.
+func (m *Metrics) InstrumentEmbedder(e embedding.Embedder) embedding.Embedder {
+	if m == nil {
+		return e
+	}
+	return &embedder{next: e, metrics: m}
+}
+
+// Embed embeds texts with the instrumented embedder.
+func (e *embedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	start := time.Now()
+	embeddings, err := e.next.Embed(ctx, texts)
+	e.metrics.ObserveEmbedding(time.Since(start), err)
+	return embeddings, err
+}
+
+// Dimensions returns the dimension of the instrumented embedder.
+func (e *embedder) Dimensions() int {
+	return e.next.Dimensions()
+}
+
+// QdrantDialOptions returns gRPC dial options recording the latency of
+// Qdrant operations, or nil if m is nil.
+func (m *Metrics) QdrantDialOptions() []grpc.DialOption {
+	if m == nil {
+		return nil
+	}
+	return []grpc.DialOption{grpc.WithChainUnaryInterceptor(m.observeQdrant)}
+}
+
+func (m *Metrics) observeQdrant(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	start := time.Now()
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	// Methods look like /qdrant.Points/Upsert; keep the service to tell
+	// Collections/Get from Points/Get.
+	operation := strings.TrimPrefix(method, "/qdrant.")
+	m.qdrantDuration.WithLabelValues(operation, status.Code(err).String()).Observe(time.Since(start).Seconds())
+	return err
+}
+
+// AgentCountFunc counts registered agents in total and per tag.
+type AgentCountFunc func(ctx context.Context) (total int, byTag map[string]int, err error)
+
+// RegisterAgentCounts exposes the registry size in total and per tag,
+// calling count on every scrape. It does nothing if m is nil.
+func (m *Metrics) RegisterAgentCounts(count AgentCountFunc) {
+	if m == nil {
+		return
+	}
+	m.registry.MustRegister(&agentCollector{
+		count: count,
+		total: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "registry", "agents"),
+			"Registered agents.",
+			nil, nil,
+		),
+		byTag: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "registry", "agents_by_tag"),
+			"Registered agents by tag.",
+			[]string{"tag"}, nil,
+		),
+	})
+}
+
+// agentCollector collects the registry size when scraped.
+type agentCollector struct {
+	// count counts the registered agents.
+	count AgentCountFunc
+	// total describes the number of registered agents.
+	total *prometheus.Desc
+	// byTag describes the number of registered agents per tag.
+	byTag *prometheus.Desc
+}
+
+// Describe sends the descriptors of the registry size metrics.
+func (c *agentCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.total
+	ch <- c.byTag
+}
+
+// Collect counts the registered agents and sends the registry size metrics.
+func (c *agentCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), agentCountTimeout)
+	defer cancel()
+
+	total, byTag, err := c.count(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.total, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(total))
+	for tag, n := range byTag {
+		ch <- prometheus.MustNewConstMetric(c.byTag, prometheus.GaugeValue, float64(n), tag)
+	}
+}
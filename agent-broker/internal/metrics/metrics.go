@@ -0,0 +1,220 @@
+package metrics
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes the names of all broker metrics.
+const namespace = "broker"
+
+// Outcome label values of operations that can fail.
+const (
+	outcomeOK    = "ok"
+	outcomeError = "error"
+)
+
+// Metrics holds the Prometheus collectors of the broker. All methods are safe
+// to call on a nil *Metrics, which records nothing, so components can take an
+// optional *Metrics without checking whether metrics are enabled.
+type Metrics struct {
+	// registry gathers the broker, Go runtime and process metrics.
+	registry *prometheus.Registry
+	// logger reports errors while gathering metrics.
+	logger *slog.Logger
+
+	// httpRequests counts HTTP requests by method, route pattern and status.
+	httpRequests *prometheus.CounterVec
+	// httpDuration observes HTTP request latency by method, route pattern and status.
+	httpDuration *prometheus.HistogramVec
+	// discoverDuration observes Discover latency by outcome.
+	discoverDuration *prometheus.HistogramVec
+	// discoverResults observes the number of agents returned by Discover.
+	discoverResults prometheus.Histogram
+	// embeddingDuration observes embedding call latency by outcome.
+	embeddingDuration *prometheus.HistogramVec
+	// embeddingErrors counts failed embedding calls.
+	embeddingErrors prometheus.Counter
+	// qdrantDuration observes Qdrant operation latency by operation and gRPC code.
+	qdrantDuration *prometheus.HistogramVec
+	// toolInvocations counts broker tool calls by tool and outcome.
+	toolInvocations *prometheus.CounterVec
+}
+
+// Options configures Metrics.
+type Options struct {
+	// Logger reports errors while gathering metrics.
+	Logger *slog.Logger
+}
+
+// DefaultOptions returns Options with sensible defaults.
+func DefaultOptions() Options {
+	return Options{
+		Logger: slog.Default(),
+	}
+}
+
+// Option is a functional option for configuring Metrics.
+type Option func(*Options)
+
+// WithLogger sets the logger reporting errors while gathering metrics.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *Options) {
+		o.Logger = logger
+	}
+}
+
+// New creates Metrics with its own registry, holding the broker metrics and
+// the Go runtime and process collectors.
+func New(opts ...Option) *Metrics {
+	options := DefaultOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		logger:   options.Logger,
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests by method, route pattern and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by method, route pattern and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		discoverDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "discover",
+			Name:      "duration_seconds",
+			Help:      "Agent discovery latency by outcome.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"outcome"}),
+		discoverResults: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "discover",
+			Name:      "results",
+			Help:      "Number of agents returned by a successful discovery.",
+			Buckets:   []float64{0, 1, 2, 3, 5, 10, 20, 50},
+		}),
+		embeddingDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "embedding",
+			Name:      "request_duration_seconds",
+			Help:      "Embedding service call latency by outcome.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"outcome"}),
+		embeddingErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "embedding",
+			Name:      "errors_total",
+			Help:      "Failed embedding service calls.",
+		}),
+		qdrantDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "qdrant",
+			Name:      "operation_duration_seconds",
+			Help:      "Qdrant operation latency by operation and gRPC status code.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation", "code"}),
+		toolInvocations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "tool",
+			Name:      "invocations_total",
+			Help:      "Broker tool invocations by tool and outcome.",
+		}, []string{"tool", "outcome"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.discoverDuration,
+		m.discoverResults,
+		m.embeddingDuration,
+		m.embeddingErrors,
+		m.qdrantDuration,
+		m.toolInvocations,
+	)
+
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format. Metrics
+// that fail to gather are logged and left out instead of failing the scrape.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{
+		ErrorLog:      slog.NewLogLogger(m.logger.Handler(), slog.LevelError),
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+}
+
+// ObserveHTTP records a served HTTP request. route is the pattern that
+// matched the request, empty if none did. Methods outside the standard set
+// are recorded as "other", so that callers cannot create unbounded series.
+func (m *Metrics) ObserveHTTP(method, route string, status int, d time.Duration) {
+	if m == nil {
+		return
+	}
+	if route == "" {
+		route = "unmatched"
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+	default:
+		method = "other"
+	}
+	code := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(method, route, code).Inc()
+	m.httpDuration.WithLabelValues(method, route, code).Observe(d.Seconds())
+}
+
+// ObserveDiscover records a discovery that returned results agents, or failed with err.
+func (m *Metrics) ObserveDiscover(d time.Duration, results int, err error) {
+	if m == nil {
+		return
+	}
+	m.discoverDuration.WithLabelValues(outcome(err)).Observe(d.Seconds())
+	if err == nil {
+		m.discoverResults.Observe(float64(results))
+	}
+}
+
+// ObserveEmbedding records an embedding service call.
+func (m *Metrics) ObserveEmbedding(d time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.embeddingDuration.WithLabelValues(outcome(err)).Observe(d.Seconds())
+	if err != nil {
+		m.embeddingErrors.Inc()
+	}
+}
+
+// ObserveTool records an invocation of the named broker tool.
+func (m *Metrics) ObserveTool(name string, err error) {
+	if m == nil {
+		return
+	}
+	m.toolInvocations.WithLabelValues(name, outcome(err)).Inc()
+}
+
+func outcome(err error) string {
+	if err != nil {
+		return outcomeError
+	}
+	return outcomeOK
+}
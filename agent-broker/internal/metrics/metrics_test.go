@@ -0,0 +1,190 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// stubEmbedder returns err or a single one-dimensional vector per text.
+type stubEmbedder struct {
+	err error
+}
+
+func (e stubEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	vectors := make([][]float32, len(texts))
+	for i := range vectors {
+		vectors[i] = []float32{1}
+	}
+	return vectors, nil
+}
+
+func (e stubEmbedder) Dimensions() int {
+	return 1
+}
+
+func newTestMetrics() *Metrics {
+	return New(WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+}
+
+// scrape returns the exposition served by m's handler.
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("scrape status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	return rec.Body.String()
+}
+
+func assertContains(t *testing.T, body string, want ...string) {
+	t.Helper()
+	for _, line := range want {
+		if !strings.Contains(body, line) {
+			t.Errorf("metrics missing %q", line)
+		}
+	}
+}
+
+func TestMetrics_Observe(t *testing.T) {
+	t.Parallel()
+
+	m := newTestMetrics()
+	m.ObserveHTTP(http.MethodGet, "GET /health", http.StatusOK, 10*time.Millisecond)
+	m.ObserveHTTP(http.MethodGet, "", http.StatusNotFound, time.Millisecond)
+	m.ObserveHTTP("FOO", "", http.StatusMethodNotAllowed, time.Millisecond)
+	m.ObserveHTTP("BAR", "", http.StatusMethodNotAllowed, time.Millisecond)
+	m.ObserveDiscover(20*time.Millisecond, 3, nil)
+	m.ObserveDiscover(time.Millisecond, 0, errors.New("boom"))
+	m.ObserveTool("route", nil)
+	m.ObserveTool("route", errors.New("boom"))
+	m.ObserveTool("discover", nil)
+
+	assertContains(t, scrape(t, m),
+		`broker_http_requests_total{method="GET",route="GET /health",status="200"} 1`,
+		`broker_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`broker_http_requests_total{method="other",route="unmatched",status="405"} 2`,
+		`broker_http_request_duration_seconds_count{method="GET",route="GET /health",status="200"} 1`,
+		`broker_discover_duration_seconds_count{outcome="ok"} 1`,
+		`broker_discover_duration_seconds_count{outcome="error"} 1`,
+		`broker_discover_results_sum 3`,
+		`broker_discover_results_count 1`,
+		`broker_tool_invocations_total{outcome="ok",tool="route"} 1`,
+		`broker_tool_invocations_total{outcome="error",tool="route"} 1`,
+		`broker_tool_invocations_total{outcome="ok",tool="discover"} 1`,
+		`go_goroutines`,
+	)
+}
+
+func TestMetrics_InstrumentEmbedder(t *testing.T) {
+	t.Parallel()
+
+	m := newTestMetrics()
+	ctx := context.Background()
+	if _, err := m.InstrumentEmbedder(stubEmbedder{}).Embed(ctx, []string{"a"}); err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	failing := m.InstrumentEmbedder(stubEmbedder{err: errors.New("unavailable")})
+	for range 2 {
+		if _, err := failing.Embed(ctx, []string{"a"}); err == nil {
+			t.Fatal("Embed() error = nil, want error")
+		}
+	}
+
+	assertContains(t, scrape(t, m),
+		`broker_embedding_request_duration_seconds_count{outcome="ok"} 1`,
+		`broker_embedding_request_duration_seconds_count{outcome="error"} 2`,
+		`broker_embedding_errors_total 2`,
+	)
+}
+
+func TestMetrics_QdrantDialOptions(t *testing.T) {
+	t.Parallel()
+
+	m := newTestMetrics()
+	if got := len(m.QdrantDialOptions()); got != 1 {
+		t.Fatalf("QdrantDialOptions() = %d options, want 1", got)
+	}
+
+	invoke := func(err error) grpc.UnaryInvoker {
+		return func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+			return err
+		}
+	}
+	ctx := context.Background()
+	_ = m.observeQdrant(ctx, "/qdrant.Points/Query", nil, nil, nil, invoke(nil))
+	_ = m.observeQdrant(ctx, "/qdrant.Points/Upsert", nil, nil, nil, invoke(status.Error(codes.Unavailable, "down")))
+
+	assertContains(t, scrape(t, m),
+		`broker_qdrant_operation_duration_seconds_count{code="OK",operation="Points/Query"} 1`,
+		`broker_qdrant_operation_duration_seconds_count{code="Unavailable",operation="Points/Upsert"} 1`,
+	)
+}
+
+func TestMetrics_RegisterAgentCounts(t *testing.T) {
+	t.Parallel()
+
+	t.Run("counts by tag", func(t *testing.T) {
+		t.Parallel()
+		m := newTestMetrics()
+		m.RegisterAgentCounts(func(context.Context) (int, map[string]int, error) {
+			return 3, map[string]int{"security": 2, "weather": 1}, nil
+		})
+
+		assertContains(t, scrape(t, m),
+			"broker_registry_agents 3",
+			`broker_registry_agents_by_tag{tag="security"} 2`,
+			`broker_registry_agents_by_tag{tag="weather"} 1`,
+		)
+	})
+
+	t.Run("count error keeps other metrics", func(t *testing.T) {
+		t.Parallel()
+		m := newTestMetrics()
+		m.RegisterAgentCounts(func(context.Context) (int, map[string]int, error) {
+			return 0, nil, errors.New("store unavailable")
+		})
+		m.ObserveTool("discover", nil)
+
+		body := scrape(t, m)
+		assertContains(t, body, `broker_tool_invocations_total{outcome="ok",tool="discover"} 1`)
+		if strings.Contains(body, "broker_registry_agents") {
+			t.Error("metrics contain broker_registry_agents after count error")
+		}
+	})
+}
+
+func TestMetrics_Nil(t *testing.T) {
+	t.Parallel()
+
+	var m *Metrics
+	m.ObserveHTTP(http.MethodGet, "GET /health", http.StatusOK, time.Millisecond)
+	m.ObserveDiscover(time.Millisecond, 1, nil)
+	m.ObserveEmbedding(time.Millisecond, nil)
+	m.ObserveTool("discover", nil)
+	m.RegisterAgentCounts(func(context.Context) (int, map[string]int, error) {
+		return 0, nil, nil
+	})
+
+	e := stubEmbedder{}
+	if got := m.InstrumentEmbedder(e); got != e {
+		t.Errorf("InstrumentEmbedder() = %v, want the embedder unchanged", got)
+	}
+	if got := m.QdrantDialOptions(); got != nil {
+		t.Errorf("QdrantDialOptions() = %v, want nil", got)
+	}
+}
@@ -0,0 +1,274 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2aclient/agentcard"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+)
+
+// listPageSize is the number of agents fetched per ListAgents call.
+const listPageSize = 100
+
+// Monitor periodically probes registered agents by fetching their agent card
+// and records the outcome on the agent record.
+type Monitor struct {
+	// store is the agent storage backend.
+	store store.Store
+	// resolver fetches agent cards.
+	resolver *agentcard.Resolver
+	// logger is the structured logger for probe failures.
+	logger *slog.Logger
+	// interval is the time between probe rounds.
+	interval time.Duration
+	// timeout bounds a single probe.
+	timeout time.Duration
+	// failureThreshold is the number of consecutive failures before an agent is unhealthy.
+	failureThreshold int
+	// concurrency is the max number of probes in flight.
+	concurrency int
+}
+
+// Options configures the Monitor.
+type Options struct {
+	// HTTPClient is the HTTP client used for probes.
+	HTTPClient *http.Client
+	// Logger is the structured logger for probe failures.
+	Logger *slog.Logger
+	// Interval is the time between probe rounds.
+	Interval time.Duration
+	// Timeout is the max duration of a single probe.
+	Timeout time.Duration
+	// FailureThreshold is the number of consecutive failures before an agent is unhealthy.
+	FailureThreshold int
+	// Concurrency is the max number of probes in flight.
+	Concurrency int
+}
+
+// DefaultOptions returns Options with sensible defaults.
+func DefaultOptions() Options {
+	return Options{
+		HTTPClient:       &http.Client{},
+		Logger:           slog.Default(),
+		Interval:         30 * time.Second,
+		Timeout:          5 * time.Second,
+		FailureThreshold: 3,
+		Concurrency:      8,
+	}
+}
+
+// Option is a functional option for configuring Monitor.
+type Option func(*Options)
+
+// WithHTTPClient sets the HTTP client used for probes.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *Options) {
+		o.HTTPClient = client
+	}
+}
+
+// WithLogger sets the structured logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *Options) {
+		o.Logger = logger
+	}
+}
+
+// WithInterval sets the time between probe rounds.
+func WithInterval(d time.Duration) Option {
+	return func(o *Options) {
+		if d > 0 {
+			o.Interval = d
+		}
+	}
+}
+
+// WithTimeout sets the timeout of a single probe.
+func WithTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		if d > 0 {
+			o.Timeout = d
+		}
+	}
+}
+
+// WithFailureThreshold sets the number of consecutive failures before an agent is unhealthy.
+func WithFailureThreshold(n int) Option {
+	return func(o *Options) {
+		if n > 0 {
+			o.FailureThreshold = n
+		}
+	}
+}
+
+// WithConcurrency sets the max number of probes in flight.
+func WithConcurrency(n int) Option {
+	return func(o *Options) {
+		if n > 0 {
+			o.Concurrency = n
+		}
+	}
+}
+
+// NewMonitor creates a Monitor for the agents in s.
+func NewMonitor(s store.Store, opts ...Option) *Monitor {
+	options := DefaultOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &Monitor{
+		store:            s,
+		resolver:         agentcard.NewResolver(options.HTTPClient),
+		logger:           options.Logger,
+		interval:         options.Interval,
+		timeout:          options.Timeout,
+		failureThreshold: options.FailureThreshold,
+		concurrency:      options.Concurrency,
+	}
+}
+
+// Run probes all agents immediately and then on every interval until ctx is cancelled.
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.CheckAll(ctx); err != nil && ctx.Err() == nil {
+			m.logger.Error("health check round failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckAll probes every registered agent once and records the results.
+func (m *Monitor) CheckAll(ctx context.Context) error {
+	agents, err := m.listAgents(ctx)
+	if err != nil {
+		return err
+	}
+
+	sem := make(chan struct{}, m.concurrency)
+	var wg sync.WaitGroup
+	for _, agent := range agents {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			m.check(ctx, agent)
+		}()
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// listAgents returns all registered agents.
+func (m *Monitor) listAgents(ctx context.Context) ([]*store.RegisteredAgent, error) {
+	var agents []*store.RegisteredAgent
+	filter := store.AgentFilter{Limit: listPageSize}
+	for {
+		result, err := m.store.ListAgents(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("list agents: %w", err)
+		}
+
+		agents = append(agents, result.Agents...)
+		if result.NextCursor == "" {
+			return agents, nil
+		}
+		filter.Cursor = result.NextCursor
+	}
+}
+
+// check probes a single agent and stores its updated health.
+func (m *Monitor) check(ctx context.Context, agent *store.RegisteredAgent) {
+	latency, probeErr := m.probe(ctx, agent)
+	if ctx.Err() != nil {
+		return
+	}
+
+	health := nextHealth(agent.Health, time.Now(), latency, probeErr, m.failureThreshold)
+	if probeErr != nil {
+		m.logger.Debug("agent probe failed",
+			"agent_id", agent.ID,
+			"consecutive_failures", health.ConsecutiveFailures,
+			"error", probeErr,
+		)
+	}
+
+	if err := m.store.SetAgentHealth(ctx, agent.ID, health); err != nil && !errors.Is(err, store.ErrNotFound) {
+		m.logger.Error("failed to record agent health", "agent_id", agent.ID, "error", err)
+	}
+}
+
+// probe fetches the agent card of agent and returns how long it took.
+func (m *Monitor) probe(ctx context.Context, agent *store.RegisteredAgent) (time.Duration, error) {
+	baseURL, err := probeURL(agent)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := m.resolver.Resolve(ctx, baseURL); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
+
+// probeURL returns the base URL serving the agent's card: the URL it was
+// registered from, or else the origin of its endpoint.
+func probeURL(agent *store.RegisteredAgent) (string, error) {
+	if agent.SourceURL != "" {
+		return agent.SourceURL, nil
+	}
+
+	u, err := url.Parse(agent.Card.URL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid agent url %q", agent.Card.URL)
+	}
+	return u.Scheme + "://" + u.Host, nil
+}
+
+// nextHealth returns the health state following prev after a probe at now.
+// An agent becomes unhealthy after threshold consecutive failures and healthy
+// again after a single successful probe.
+func nextHealth(prev store.AgentHealth, now time.Time, latency time.Duration, probeErr error, threshold int) store.AgentHealth {
+	next := prev
+	next.LastChecked = now
+
+	if probeErr == nil {
+		next.Status = store.HealthHealthy
+		next.LastSeen = now
+		next.ConsecutiveFailures = 0
+		next.Latency = latency
+		next.LastError = ""
+		return next
+	}
+
+	next.ConsecutiveFailures++
+	next.LastError = probeErr.Error()
+	if next.ConsecutiveFailures >= threshold {
+		next.Status = store.HealthUnhealthy
+	}
+	return next
+}
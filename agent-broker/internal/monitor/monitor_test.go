@@ -0,0 +1,124 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+)
+
+func TestNextHealth(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	probeErr := errors.New("connection refused")
+
+	tests := []struct {
+		name         string
+		prev         store.AgentHealth
+		probeErr     error
+		wantStatus   store.HealthStatus
+		wantFailures int
+	}{
+		{
+			name:         "success marks healthy",
+			prev:         store.AgentHealth{Status: store.HealthUnhealthy, ConsecutiveFailures: 5},
+			wantStatus:   store.HealthHealthy,
+			wantFailures: 0,
+		},
+		{
+			name:         "failure below threshold keeps status",
+			prev:         store.AgentHealth{Status: store.HealthHealthy, ConsecutiveFailures: 1},
+			probeErr:     probeErr,
+			wantStatus:   store.HealthHealthy,
+			wantFailures: 2,
+		},
+		{
+			name:         "failure at threshold marks unhealthy",
+			prev:         store.AgentHealth{Status: store.HealthHealthy, ConsecutiveFailures: 2},
+			probeErr:     probeErr,
+			wantStatus:   store.HealthUnhealthy,
+			wantFailures: 3,
+		},
+		{
+			name:         "first failure of unknown agent",
+			prev:         store.AgentHealth{},
+			probeErr:     probeErr,
+			wantStatus:   store.HealthUnknown,
+			wantFailures: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := nextHealth(tt.prev, now, 10*time.Millisecond, tt.probeErr, 3)
+
+			if got.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", got.Status, tt.wantStatus)
+			}
+			if got.ConsecutiveFailures != tt.wantFailures {
+				t.Errorf("ConsecutiveFailures = %d, want %d", got.ConsecutiveFailures, tt.wantFailures)
+			}
+			if !got.LastChecked.Equal(now) {
+				t.Error("LastChecked should be set")
+			}
+			if tt.probeErr == nil && !got.LastSeen.Equal(now) {
+				t.Error("LastSeen should be set on success")
+			}
+			if tt.probeErr != nil && got.LastError == "" {
+				t.Error("LastError should be set on failure")
+			}
+		})
+	}
+}
+
+func TestMonitor_CheckAll(t *testing.T) {
+	t.Parallel()
+
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/agent-card.json" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(a2a.AgentCard{Name: "Up Agent"})
+	}))
+	t.Cleanup(up.Close)
+	down := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(down.Close)
+
+	s := store.NewMemoryStore()
+	ctx := context.Background()
+	_ = s.CreateAgent(ctx, &store.RegisteredAgent{ID: "up", Card: a2a.AgentCard{URL: up.URL + "/a2a"}})
+	_ = s.CreateAgent(ctx, &store.RegisteredAgent{ID: "down", Card: a2a.AgentCard{URL: down.URL}})
+
+	m := NewMonitor(s, WithFailureThreshold(2))
+	for range 2 {
+		if err := m.CheckAll(ctx); err != nil {
+			t.Fatalf("CheckAll() error = %v", err)
+		}
+	}
+
+	upAgent, _ := s.GetAgent(ctx, "up")
+	if upAgent.Health.Status != store.HealthHealthy {
+		t.Errorf("up Status = %q, want healthy", upAgent.Health.Status)
+	}
+	if upAgent.Health.LastSeen.IsZero() {
+		t.Error("up LastSeen should be set")
+	}
+
+	downAgent, _ := s.GetAgent(ctx, "down")
+	if downAgent.Health.Status != store.HealthUnhealthy {
+		t.Errorf("down Status = %q, want unhealthy", downAgent.Health.Status)
+	}
+	if downAgent.Health.ConsecutiveFailures != 2 {
+		t.Errorf("down ConsecutiveFailures = %d, want 2", downAgent.Health.ConsecutiveFailures)
+	}
+}
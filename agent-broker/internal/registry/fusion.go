@@ -0,0 +1,144 @@
+package registry
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+)
+
+// ErrInvalidFusion is returned when a Fusion has an unknown method or unusable weights.
+var ErrInvalidFusion = errors.New("invalid fusion")
+
+// FusionMethod selects how keyword and semantic rankings are combined.
+type FusionMethod string
+
+const (
+	// FusionRRF combines the rankings by weighted reciprocal rank fusion.
+	FusionRRF FusionMethod = "rrf"
+	// FusionWeighted blends cosine scores with BM25 scores normalized to the best match.
+	FusionWeighted FusionMethod = "weighted"
+)
+
+// rrfK dampens the advantage of top ranks in reciprocal rank fusion.
+const rrfK = 60
+
+// candidateFactor is how many candidates per requested result each ranking
+// contributes to fusion.
+const candidateFactor = 4
+
+// Fusion configures hybrid ranking in Discover.
+type Fusion struct {
+	// Method is how the two rankings are combined, FusionRRF if empty.
+	Method FusionMethod
+	// SemanticWeight is the weight of the embedding similarity ranking.
+	SemanticWeight float32
+	// LexicalWeight is the weight of the BM25 keyword ranking, 0 for semantic search only.
+	LexicalWeight float32
+}
+
+// SemanticOnly ranks agents by embedding similarity alone.
+var SemanticOnly = Fusion{Method: FusionRRF, SemanticWeight: 1}
+
+// Validate checks the method and weights.
+func (f Fusion) Validate() error {
+	switch f.Method {
+	case "", FusionRRF, FusionWeighted:
+	default:
+		return fmt.Errorf("%w: unknown method %q", ErrInvalidFusion, f.Method)
+	}
+	if f.SemanticWeight < 0 || f.LexicalWeight < 0 {
+		return fmt.Errorf("%w: weights must not be negative", ErrInvalidFusion)
+	}
+	if f.SemanticWeight == 0 && f.LexicalWeight == 0 {
+		return fmt.Errorf("%w: at least one weight must be positive", ErrInvalidFusion)
+	}
+	return nil
+}
+
+// fuse merges the semantic and lexical rankings into at most limit agents.
+// Fused scores are in 0-1, ties are broken by similarity and then ID. The
+// semantic score is kept as the agents' Similarity, and agents ranked only by
+// keywords keep the Similarity of their lexical hit.
+func fuse(semantic, lexical []store.ScoredAgent, f Fusion, limit int) []store.ScoredAgent {
+	type entry struct {
+		agent  *store.RegisteredAgent
+		cosine float32
+		skill  string
+		fused  float64
+	}
+	entries := make(map[string]*entry)
+	get := func(agent *store.RegisteredAgent) *entry {
+		e, ok := entries[agent.ID]
+		if !ok {
+			e = &entry{agent: agent}
+			entries[agent.ID] = e
+		}
+		return e
+	}
+	getLexical := func(scored store.ScoredAgent) *entry {
+		_, ranked := entries[scored.Agent.ID]
+		e := get(scored.Agent)
+		if !ranked {
+			e.cosine, e.skill = scored.Similarity, scored.MatchedSkill
+		}
+		return e
+	}
+
+	ws, wl := float64(f.SemanticWeight), float64(f.LexicalWeight)
+	if f.Method == FusionWeighted {
+		var best float32
+		for _, scored := range lexical {
+			best = max(best, scored.Score)
+		}
+		for _, scored := range semantic {
+			e := get(scored.Agent)
+			e.cosine, e.skill = scored.Score, scored.MatchedSkill
+			e.fused += ws * float64(max(scored.Score, 0))
+		}
+		for _, scored := range lexical {
+			if best > 0 {
+				getLexical(scored).fused += wl * float64(scored.Score/best)
+			}
+		}
+	} else {
+		for rank, scored := range semantic {
+			e := get(scored.Agent)
+			e.cosine, e.skill = scored.Score, scored.MatchedSkill
+			e.fused += ws / float64(rrfK+rank+1)
+		}
+		for rank, scored := range lexical {
+			getLexical(scored).fused += wl / float64(rrfK+rank+1)
+		}
+		// Scale so an agent ranked first by both lists scores 1.
+		ws, wl = ws/(rrfK+1), wl/(rrfK+1)
+	}
+
+	fused := make([]*entry, 0, len(entries))
+	for _, e := range entries {
+		e.fused /= ws + wl
+		fused = append(fused, e)
+	}
+	slices.SortFunc(fused, func(a, b *entry) int {
+		if c := cmp.Compare(b.fused, a.fused); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(b.cosine, a.cosine); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.agent.ID, b.agent.ID)
+	})
+
+	agents := make([]store.ScoredAgent, 0, min(limit, len(fused)))
+	for _, e := range fused[:min(limit, len(fused))] {
+		agents = append(agents, store.ScoredAgent{
+			Agent:        e.agent,
+			Score:        float32(e.fused),
+			MatchedSkill: e.skill,
+			Similarity:   e.cosine,
+		})
+	}
+	return agents
+}
@@ -0,0 +1,160 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+)
+
+func scored(id string, score float32) store.ScoredAgent {
+	return store.ScoredAgent{Agent: &store.RegisteredAgent{ID: id}, Score: score}
+}
+
+func TestFuse(t *testing.T) {
+	t.Parallel()
+
+	semantic := []store.ScoredAgent{scored("vague", 0.9), scored("exact", 0.8), scored("other", 0.5)}
+	lexical := []store.ScoredAgent{scored("exact", 6), scored("keyword", 3)}
+
+	tests := []struct {
+		name      string
+		fusion    Fusion
+		limit     int
+		wantIDs   []string
+		wantFirst float32
+	}{
+		{
+			name:      "rrf favors agents ranked by both lists",
+			fusion:    Fusion{Method: FusionRRF, SemanticWeight: 1, LexicalWeight: 1},
+			limit:     4,
+			wantIDs:   []string{"exact", "vague", "keyword", "other"},
+			wantFirst: float32((1.0/62 + 1.0/61) / (2.0 / 61)),
+		},
+		{
+			name:    "rrf lexical weight dominates",
+			fusion:  Fusion{Method: FusionRRF, SemanticWeight: 1, LexicalWeight: 5},
+			limit:   2,
+			wantIDs: []string{"exact", "keyword"},
+		},
+		{
+			name:      "weighted blend normalizes bm25 to the best match",
+			fusion:    Fusion{Method: FusionWeighted, SemanticWeight: 1, LexicalWeight: 1},
+			limit:     4,
+			wantIDs:   []string{"exact", "vague", "other", "keyword"},
+			wantFirst: 0.9,
+		},
+		{
+			name:      "lexical only",
+			fusion:    Fusion{Method: FusionWeighted, LexicalWeight: 1},
+			limit:     1,
+			wantIDs:   []string{"exact"},
+			wantFirst: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := fuse(semantic, lexical, tt.fusion, tt.limit)
+
+			var ids []string
+			for _, s := range got {
+				ids = append(ids, s.Agent.ID)
+				if s.Score < 0 || s.Score > 1 {
+					t.Errorf("score of %s = %v, want 0-1", s.Agent.ID, s.Score)
+				}
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("fuse() = %v, want %v", ids, tt.wantIDs)
+			}
+			if tt.wantFirst != 0 && math.Abs(float64(got[0].Score-tt.wantFirst)) > 1e-6 {
+				t.Errorf("top score = %v, want %v", got[0].Score, tt.wantFirst)
+			}
+		})
+	}
+}
+
+func TestFusion_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		fusion  Fusion
+		wantErr bool
+	}{
+		{name: "semantic only", fusion: SemanticOnly},
+		{name: "empty method defaults to rrf", fusion: Fusion{SemanticWeight: 1, LexicalWeight: 1}},
+		{name: "unknown method", fusion: Fusion{Method: "max", SemanticWeight: 1}, wantErr: true},
+		{name: "negative weight", fusion: Fusion{SemanticWeight: 1, LexicalWeight: -1}, wantErr: true},
+		{name: "zero weights", fusion: Fusion{Method: FusionRRF}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.fusion.Validate()
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrInvalidFusion)) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegistryService_Discover_Hybrid(t *testing.T) {
+	t.Parallel()
+
+	// "vague" is the closest embedding, "ocr" the only exact keyword match.
+	agents := []*store.RegisteredAgent{
+		{ID: "vague", Card: validAgentCard(), Embedding: []float32{1, 0}},
+		{ID: "ocr", Card: validAgentCard(), Embedding: []float32{0.6, 0.8}},
+		{ID: "far", Card: validAgentCard(), Embedding: []float32{0, 1}},
+	}
+	agents[1].Card.Skills = []a2a.AgentSkill{{ID: "ocr", Name: "OCR", Description: "Extracts text from scanned images"}}
+
+	s := store.NewMemoryStore()
+	for _, agent := range agents {
+		_ = s.CreateAgent(context.Background(), agent)
+	}
+
+	tests := []struct {
+		name    string
+		fusion  *Fusion
+		wantTop string
+	}{
+		{name: "default is semantic only", wantTop: "vague"},
+		{name: "hybrid ranks the keyword match first", fusion: &Fusion{Method: FusionRRF, SemanticWeight: 1, LexicalWeight: 1}, wantTop: "ocr"},
+		{name: "weighted blend", fusion: &Fusion{Method: FusionWeighted, SemanticWeight: 1, LexicalWeight: 1}, wantTop: "ocr"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc := NewRegistryService(s, WithEmbedder(fixedEmbedder{vector: []float32{1, 0}}))
+
+			result, err := svc.Discover(context.Background(), DiscoverInput{Query: "OCR for scanned invoices", Limit: 2, Fusion: tt.fusion})
+			if err != nil {
+				t.Fatalf("Discover() error = %v", err)
+			}
+			if len(result.Agents) != 2 || result.Agents[0].Agent.ID != tt.wantTop {
+				t.Errorf("Discover() top = %v, want %s", result.Agents, tt.wantTop)
+			}
+		})
+	}
+
+	t.Run("invalid fusion is rejected", func(t *testing.T) {
+		t.Parallel()
+		svc := NewRegistryService(s, WithEmbedder(fixedEmbedder{vector: []float32{1, 0}}))
+
+		_, err := svc.Discover(context.Background(), DiscoverInput{Query: "ocr", Fusion: &Fusion{}})
+		if !errors.Is(err, ErrInvalidFusion) {
+			t.Errorf("Discover() error = %v, want ErrInvalidFusion", err)
+		}
+	})
+}
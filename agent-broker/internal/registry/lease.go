@@ -0,0 +1,90 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+)
+
+// ErrNoLease is returned when sending a heartbeat for an agent registered without a TTL.
+var ErrNoLease = errors.New("agent has no lease")
+
+// defaultReapInterval is used by RunReaper when the interval is not positive.
+const defaultReapInterval = 15 * time.Second
+
+// Heartbeat renews the lease of an agent for another LeaseTTL.
+// Expired agents that have not been reaped yet are revived.
+func (s *RegistryService) Heartbeat(ctx context.Context, id string) (*store.RegisteredAgent, error) {
+	existing, err := s.store.GetAgent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing.LeaseTTL <= 0 {
+		return nil, ErrNoLease
+	}
+
+	expiresAt := leaseExpiry(time.Now(), existing.LeaseTTL)
+	if err := s.store.RenewAgentLease(ctx, id, expiresAt); err != nil {
+		return nil, err
+	}
+
+	renewed := *existing
+	renewed.ExpiresAt = expiresAt
+	return &renewed, nil
+}
+
+// ReapExpired removes agents whose lease has expired and returns their IDs.
+func (s *RegistryService) ReapExpired(ctx context.Context) ([]string, error) {
+	return s.store.DeleteExpiredAgents(ctx, time.Now())
+}
+
+// RunReaper calls ReapExpired on every interval until ctx is cancelled. A
+// non-positive interval is replaced by the default of 15 seconds.
+func (s *RegistryService) RunReaper(ctx context.Context, interval time.Duration, logger *slog.Logger) {
+	if interval <= 0 {
+		interval = defaultReapInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		ids, err := s.ReapExpired(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Error("failed to reap expired agents", "error", err)
+			}
+			continue
+		}
+		if len(ids) > 0 {
+			logger.Info("reaped expired agents", "agent_ids", ids)
+		}
+	}
+}
+
+// leaseExpiry returns when a lease of ttl taken at now expires, zero if ttl is zero.
+func leaseExpiry(now time.Time, ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return now.Add(ttl)
+}
+
+func validateLeaseTTL(ttl time.Duration) error {
+	if ttl < 0 {
+		return fmt.Errorf("ttl must not be negative")
+	}
+	if ttl > 0 && ttl < time.Second {
+		return fmt.Errorf("ttl must be at least 1 second")
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+)
+
+func TestRegistryService_Create_Lease(t *testing.T) {
+	t.Parallel()
+
+	t.Run("TTL sets expiry", func(t *testing.T) {
+		t.Parallel()
+		svc := NewRegistryService(store.NewMemoryStore())
+		input := validCreateInput()
+		input.LeaseTTL = time.Minute
+
+		agent, err := svc.Create(context.Background(), input)
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if agent.ExpiresAt.IsZero() {
+			t.Fatal("Create() ExpiresAt should be set")
+		}
+		if got := agent.ExpiresAt.Sub(agent.CreatedAt); got != time.Minute {
+			t.Errorf("ExpiresAt - CreatedAt = %v, want %v", got, time.Minute)
+		}
+	})
+
+	t.Run("no TTL never expires", func(t *testing.T) {
+		t.Parallel()
+		svc := NewRegistryService(store.NewMemoryStore())
+
+		agent, err := svc.Create(context.Background(), validCreateInput())
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if !agent.ExpiresAt.IsZero() {
+			t.Errorf("Create() ExpiresAt = %v, want zero", agent.ExpiresAt)
+		}
+	})
+
+	t.Run("negative TTL rejected", func(t *testing.T) {
+		t.Parallel()
+		svc := NewRegistryService(store.NewMemoryStore())
+		input := validCreateInput()
+		input.LeaseTTL = -time.Second
+
+		_, err := svc.Create(context.Background(), input)
+		if err == nil || !strings.Contains(err.Error(), "ttl must not be negative") {
+			t.Errorf("Create() error = %v, want ttl error", err)
+		}
+	})
+}
+
+func TestRegistryService_Heartbeat(t *testing.T) {
+	t.Parallel()
+
+	t.Run("extends lease", func(t *testing.T) {
+		t.Parallel()
+		svc := NewRegistryService(store.NewMemoryStore())
+		input := validCreateInput()
+		input.LeaseTTL = time.Minute
+		created, _ := svc.Create(context.Background(), input)
+
+		renewed, err := svc.Heartbeat(context.Background(), input.ID)
+		if err != nil {
+			t.Fatalf("Heartbeat() error = %v", err)
+		}
+		if !renewed.ExpiresAt.After(created.ExpiresAt) {
+			t.Errorf("Heartbeat() ExpiresAt = %v, want after %v", renewed.ExpiresAt, created.ExpiresAt)
+		}
+		stored, _ := svc.Get(context.Background(), input.ID)
+		if !stored.ExpiresAt.Equal(renewed.ExpiresAt) {
+			t.Errorf("stored ExpiresAt = %v, want %v", stored.ExpiresAt, renewed.ExpiresAt)
+		}
+	})
+
+	t.Run("agent without lease", func(t *testing.T) {
+		t.Parallel()
+		svc := NewRegistryService(store.NewMemoryStore())
+		_, _ = svc.Create(context.Background(), validCreateInput())
+
+		_, err := svc.Heartbeat(context.Background(), "test-agent")
+		if !errors.Is(err, ErrNoLease) {
+			t.Errorf("Heartbeat() error = %v, want ErrNoLease", err)
+		}
+	})
+
+	t.Run("non-existent agent", func(t *testing.T) {
+		t.Parallel()
+		svc := NewRegistryService(store.NewMemoryStore())
+
+		_, err := svc.Heartbeat(context.Background(), "not-exists")
+		if !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("Heartbeat() error = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestRegistryService_ReapExpired(t *testing.T) {
+	t.Parallel()
+	s := store.NewMemoryStore()
+	svc := NewRegistryService(s)
+	ctx := context.Background()
+
+	_, _ = svc.Create(ctx, validCreateInput())
+	expired := &store.RegisteredAgent{
+		ID:        "expired-agent",
+		Card:      validAgentCard(),
+		LeaseTTL:  time.Second,
+		ExpiresAt: time.Now().Add(-time.Second),
+	}
+	_ = s.CreateAgent(ctx, expired)
+
+	ids, err := svc.ReapExpired(ctx)
+	if err != nil {
+		t.Fatalf("ReapExpired() error = %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "expired-agent" {
+		t.Errorf("ReapExpired() = %v, want [expired-agent]", ids)
+	}
+	if _, err := svc.Get(ctx, "test-agent"); err != nil {
+		t.Errorf("agent without lease should be kept, Get() error = %v", err)
+	}
+}
+
+func TestRegistryService_RunReaper(t *testing.T) {
+	t.Parallel()
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		t.Run(interval.String(), func(t *testing.T) {
+			t.Parallel()
+			svc := NewRegistryService(store.NewMemoryStore())
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			// Must return instead of panicking on the invalid interval.
+			svc.RunReaper(ctx, interval, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		})
+	}
+}
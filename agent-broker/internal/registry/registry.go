@@ -2,31 +2,94 @@ package registry
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2aclient/agentcard"
 
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/metrics"
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
 	"github.com/lunarr-ai/lunarr/agent-broker/pkg/embedding"
 )
 
 var agentIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
 
+// ErrNoEmbedder is returned by operations that need embeddings when no
+// embedder is configured.
+var ErrNoEmbedder = errors.New("no embedder configured")
+
+// ErrInvalidHealthPolicy is returned for an unknown HealthPolicy.
+var ErrInvalidHealthPolicy = errors.New("invalid health policy")
+
 // RegistryService manages agent registrations.
 type RegistryService struct {
 	// store is the agent storage backend.
 	store store.Store
 	// embedder generates embeddings for agents (optional).
 	embedder embedding.Embedder
+	// resolver fetches agent cards from well-known URLs.
+	resolver *agentcard.Resolver
+	// healthPolicy controls how Discover treats unhealthy agents.
+	healthPolicy HealthPolicy
+	// fusion is the default hybrid ranking of Discover.
+	fusion Fusion
+	// thresholds are the default score thresholds of Discover.
+	thresholds Thresholds
+	// embeddingModel is the name of the embedder's model, recorded on reindexed stores.
+	embeddingModel string
+	// metrics records Discover latency and result counts (optional).
+	metrics *metrics.Metrics
+
+	// reindexMu guards reindexStatus.
+	reindexMu sync.Mutex
+	// reindexStatus is the progress of the running or last reindex.
+	reindexStatus ReindexStatus
+}
+
+// HealthPolicy controls how Discover treats agents marked unhealthy by the health monitor.
+type HealthPolicy string
+
+const (
+	// HealthPolicyIgnore returns unhealthy agents like any other agent.
+	HealthPolicyIgnore HealthPolicy = "ignore"
+	// HealthPolicyDownrank returns unhealthy agents only after all healthy matches.
+	HealthPolicyDownrank HealthPolicy = "downrank"
+	// HealthPolicyExclude never returns unhealthy agents.
+	HealthPolicyExclude HealthPolicy = "exclude"
+)
+
+// Validate checks that p is a known policy.
+func (p HealthPolicy) Validate() error {
+	switch p {
+	case HealthPolicyIgnore, HealthPolicyDownrank, HealthPolicyExclude:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidHealthPolicy, p)
+	}
 }
 
 // Options configures the RegistryService.
 type Options struct {
 	// Embedder generates embeddings for agents.
 	Embedder embedding.Embedder
+	// HTTPClient is the HTTP client used to fetch agent cards.
+	HTTPClient *http.Client
+	// HealthPolicy controls how Discover treats unhealthy agents.
+	HealthPolicy HealthPolicy
+	// Fusion is the default hybrid ranking of Discover.
+	Fusion Fusion
+	// Thresholds are the default score thresholds of Discover.
+	Thresholds Thresholds
+	// EmbeddingModel is the name of the embedder's model.
+	EmbeddingModel string
+	// Metrics records Discover latency and result counts.
+	Metrics *metrics.Metrics
 }
 
 // Option is a functional option for RegistryService.
@@ -39,16 +102,69 @@ func WithEmbedder(e embedding.Embedder) Option {
 	}
 }
 
+// WithHTTPClient sets the HTTP client used to fetch agent cards.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *Options) {
+		o.HTTPClient = client
+	}
+}
+
+// WithHealthPolicy sets how Discover treats unhealthy agents.
+func WithHealthPolicy(policy HealthPolicy) Option {
+	return func(o *Options) {
+		o.HealthPolicy = policy
+	}
+}
+
+// WithFusion sets the default hybrid ranking of Discover.
+func WithFusion(f Fusion) Option {
+	return func(o *Options) {
+		o.Fusion = f
+	}
+}
+
+// WithThresholds sets the default score thresholds of Discover.
+func WithThresholds(t Thresholds) Option {
+	return func(o *Options) {
+		o.Thresholds = t
+	}
+}
+
+// WithEmbeddingModel sets the name of the embedder's model.
+func WithEmbeddingModel(model string) Option {
+	return func(o *Options) {
+		o.EmbeddingModel = model
+	}
+}
+
+// WithMetrics sets the metrics recording Discover latency and result counts.
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(o *Options) {
+		o.Metrics = m
+	}
+}
+
 // NewRegistryService creates a new registry service.
 func NewRegistryService(s store.Store, opts ...Option) *RegistryService {
-	var options Options
+	options := Options{
+		HTTPClient:   &http.Client{Timeout: 30 * time.Second},
+		HealthPolicy: HealthPolicyIgnore,
+		Fusion:       SemanticOnly,
+	}
 	for _, opt := range opts {
 		opt(&options)
 	}
 
 	return &RegistryService{
-		store:    s,
-		embedder: options.Embedder,
+		store:          s,
+		embedder:       options.Embedder,
+		resolver:       agentcard.NewResolver(options.HTTPClient),
+		healthPolicy:   options.HealthPolicy,
+		fusion:         options.Fusion,
+		thresholds:     options.Thresholds,
+		embeddingModel: options.EmbeddingModel,
+		metrics:        options.Metrics,
+		reindexStatus:  ReindexStatus{State: ReindexIdle},
 	}
 }
 
@@ -60,6 +176,8 @@ type CreateInput struct {
 	Card a2a.AgentCard
 	// Tags are classification tags.
 	Tags []string
+	// LeaseTTL is the registration lease, renewed by heartbeats. Zero means no expiry.
+	LeaseTTL time.Duration
 }
 
 // Create registers a new agent.
@@ -70,26 +188,26 @@ func (s *RegistryService) Create(ctx context.Context, input CreateInput) (*store
 	if err := ValidateAgentCard(input.Card); err != nil {
 		return nil, err
 	}
+	if err := validateLeaseTTL(input.LeaseTTL); err != nil {
+		return nil, err
+	}
 
-	var emb []float32
-	if s.embedder != nil {
-		embeddings, err := s.embedder.Embed(ctx, []string{buildEmbeddingText(input.Card)})
-		if err != nil {
-			return nil, fmt.Errorf("generate embedding: %w", err)
-		}
-		if len(embeddings) > 0 {
-			emb = embeddings[0]
-		}
+	emb, skillEmbs, err := s.embedCard(ctx, input.Card)
+	if err != nil {
+		return nil, err
 	}
 
 	now := time.Now()
 	agent := &store.RegisteredAgent{
-		ID:        input.ID,
-		Card:      input.Card,
-		Tags:      input.Tags,
-		Embedding: emb,
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:              input.ID,
+		Card:            input.Card,
+		Tags:            input.Tags,
+		Embedding:       emb,
+		SkillEmbeddings: skillEmbs,
+		LeaseTTL:        input.LeaseTTL,
+		ExpiresAt:       leaseExpiry(now, input.LeaseTTL),
+		CreatedAt:       now,
+		UpdatedAt:       now,
 	}
 
 	if err := s.store.CreateAgent(ctx, agent); err != nil {
@@ -106,10 +224,16 @@ func (s *RegistryService) Get(ctx context.Context, id string) (*store.Registered
 
 // ListInput contains input for listing agents.
 type ListInput struct {
-	// Offset is the number of items to skip.
+	// Offset is the number of items to skip, ignored when Cursor is set.
 	Offset int
 	// Limit is the maximum items to return.
 	Limit int
+	// Cursor continues a previous listing with the same sort.
+	Cursor string
+	// Sort is the field to order by, created_at if empty.
+	Sort store.SortField
+	// Order is the sort direction, the field's natural order if empty.
+	Order store.SortOrder
 	// Tags filters by any matching tag.
 	Tags []string
 	// Skills filters by any matching skill ID.
@@ -133,12 +257,41 @@ func (s *RegistryService) List(ctx context.Context, input ListInput) (*store.Age
 	return s.store.ListAgents(ctx, store.AgentFilter{
 		Offset: input.Offset,
 		Limit:  input.Limit,
+		Cursor: input.Cursor,
+		Sort:   input.Sort,
+		Order:  input.Order,
 		Tags:   input.Tags,
 		Skills: input.Skills,
 		Query:  input.Query,
 	})
 }
 
+// CountAgents returns the number of registered agents in total and per tag.
+// Stores implementing store.AgentCounter count without reading the agents;
+// others are listed in full.
+func (s *RegistryService) CountAgents(ctx context.Context) (int, map[string]int, error) {
+	if counter, ok := s.store.(store.AgentCounter); ok {
+		total, byTag, err := counter.CountAgents(ctx)
+		if err != nil {
+			return 0, nil, fmt.Errorf("count agents: %w", err)
+		}
+		return total, byTag, nil
+	}
+
+	result, err := s.store.ListAgents(ctx, store.AgentFilter{})
+	if err != nil {
+		return 0, nil, fmt.Errorf("list agents: %w", err)
+	}
+
+	byTag := make(map[string]int)
+	for _, agent := range result.Agents {
+		for _, tag := range agent.Tags {
+			byTag[tag]++
+		}
+	}
+	return len(result.Agents), byTag, nil
+}
+
 // UpdateInput contains input for updating an agent.
 type UpdateInput struct {
 	// ID is the agent identifier.
@@ -160,20 +313,15 @@ func (s *RegistryService) Update(ctx context.Context, input UpdateInput) (*store
 		return nil, err
 	}
 
-	var emb []float32
-	if s.embedder != nil {
-		embeddings, err := s.embedder.Embed(ctx, []string{buildEmbeddingText(input.Card)})
-		if err != nil {
-			return nil, fmt.Errorf("generate embedding: %w", err)
-		}
-		if len(embeddings) > 0 {
-			emb = embeddings[0]
-		}
+	emb, skillEmbs, err := s.embedCard(ctx, input.Card)
+	if err != nil {
+		return nil, err
 	}
 
 	existing.Card = input.Card
 	existing.Tags = input.Tags
 	existing.Embedding = emb
+	existing.SkillEmbeddings = skillEmbs
 	existing.UpdatedAt = time.Now()
 
 	if err := s.store.UpdateAgent(ctx, existing); err != nil {
@@ -198,10 +346,39 @@ type DiscoverInput struct {
 	Tags []string
 	// Skills filters by any matching skill ID.
 	Skills []string
+	// Fusion overrides the default hybrid ranking when set.
+	Fusion *Fusion
+	// MinScore overrides the configured Thresholds.MinScore when set.
+	MinScore *float32
+	// MinRelativeScore overrides the configured Thresholds.MinRelativeScore when set.
+	MinRelativeScore *float32
 }
 
-// Discover finds agents by semantic similarity.
-func (s *RegistryService) Discover(ctx context.Context, input DiscoverInput) (*store.SearchResult, error) {
+// DiscoverResult contains the agents found by Discover.
+type DiscoverResult struct {
+	// Agents is the list of confident matches, best first.
+	Agents []store.ScoredAgent
+	// NearMisses holds the best agents dropped by the score thresholds, so
+	// callers can explain why nothing confident was found.
+	NearMisses []store.ScoredAgent
+}
+
+// Discover finds agents by semantic similarity, fused with BM25 keyword
+// relevance when the ranking gives keywords weight. Unhealthy agents are
+// skipped or ranked last according to the configured HealthPolicy, and
+// matches below the score thresholds are reported as near misses.
+func (s *RegistryService) Discover(ctx context.Context, input DiscoverInput) (*DiscoverResult, error) {
+	start := time.Now()
+	result, err := s.discover(ctx, input)
+	if err != nil {
+		s.metrics.ObserveDiscover(time.Since(start), 0, err)
+		return nil, err
+	}
+	s.metrics.ObserveDiscover(time.Since(start), len(result.Agents), nil)
+	return result, nil
+}
+
+func (s *RegistryService) discover(ctx context.Context, input DiscoverInput) (*DiscoverResult, error) {
 	if input.Limit <= 0 {
 		input.Limit = 10
 	}
@@ -209,8 +386,27 @@ func (s *RegistryService) Discover(ctx context.Context, input DiscoverInput) (*s
 		input.Limit = 50
 	}
 
+	fusion := s.fusion
+	if input.Fusion != nil {
+		fusion = *input.Fusion
+	}
+	if err := fusion.Validate(); err != nil {
+		return nil, err
+	}
+
+	thresholds := s.thresholds
+	if input.MinScore != nil {
+		thresholds.MinScore = *input.MinScore
+	}
+	if input.MinRelativeScore != nil {
+		thresholds.MinRelativeScore = *input.MinRelativeScore
+	}
+	if err := thresholds.Validate(); err != nil {
+		return nil, err
+	}
+
 	if s.embedder == nil {
-		return nil, fmt.Errorf("embedder not configured")
+		return nil, ErrNoEmbedder
 	}
 
 	embeddings, err := s.embedder.Embed(ctx, []string{input.Query})
@@ -221,10 +417,88 @@ func (s *RegistryService) Discover(ctx context.Context, input DiscoverInput) (*s
 		return nil, fmt.Errorf("no embedding returned")
 	}
 
-	return s.store.SearchAgents(ctx, embeddings[0], input.Limit, store.AgentFilter{
-		Tags:   input.Tags,
-		Skills: input.Skills,
-	})
+	filter := store.AgentFilter{
+		Tags:             input.Tags,
+		Skills:           input.Skills,
+		ExcludeUnhealthy: s.healthPolicy == HealthPolicyExclude || s.healthPolicy == HealthPolicyDownrank,
+	}
+
+	result, err := s.search(ctx, input.Query, embeddings[0], input.Limit, filter, fusion)
+	if err != nil {
+		return nil, err
+	}
+	if s.healthPolicy != HealthPolicyDownrank || len(result.Agents) >= input.Limit {
+		return newDiscoverResult(result.Agents, thresholds), nil
+	}
+
+	// Fill the remaining slots with the best unhealthy matches.
+	filter.ExcludeUnhealthy = false
+	all, err := s.search(ctx, input.Query, embeddings[0], input.Limit, filter, fusion)
+	if err != nil {
+		return nil, err
+	}
+	for _, scored := range all.Agents {
+		if len(result.Agents) >= input.Limit {
+			break
+		}
+		if scored.Agent.Health.Status == store.HealthUnhealthy {
+			result.Agents = append(result.Agents, scored)
+		}
+	}
+
+	return newDiscoverResult(result.Agents, thresholds), nil
+}
+
+// newDiscoverResult applies thresholds to ranked agents.
+func newDiscoverResult(agents []store.ScoredAgent, thresholds Thresholds) *DiscoverResult {
+	kept, dropped := thresholds.apply(agents)
+	return &DiscoverResult{Agents: kept, NearMisses: dropped}
+}
+
+// search ranks agents by embedding similarity and, if the fusion gives
+// keywords weight, fuses the ranking with BM25 keyword relevance. Stores
+// without keyword search fall back to embedding similarity. Agents carry
+// their embedding similarity whatever the fusion.
+func (s *RegistryService) search(ctx context.Context, query string, vector []float32, limit int, filter store.AgentFilter, fusion Fusion) (*store.SearchResult, error) {
+	if fusion.LexicalWeight == 0 {
+		result, err := s.store.SearchAgents(ctx, vector, limit, filter)
+		if err != nil {
+			return nil, err
+		}
+		setSimilarity(result.Agents)
+		return result, nil
+	}
+
+	candidates := limit * candidateFactor
+	semantic, err := s.store.SearchAgents(ctx, vector, candidates, filter)
+	if err != nil {
+		return nil, err
+	}
+	setSimilarity(semantic.Agents)
+
+	lexical, err := s.store.LexicalSearchAgents(ctx, query, candidates, filter)
+	if errors.Is(err, store.ErrLexicalUnsupported) {
+		semantic.Agents = semantic.Agents[:min(limit, len(semantic.Agents))]
+		return semantic, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Keyword matches outside the semantic candidates need their similarity
+	// for the thresholds too.
+	for i := range lexical.Agents {
+		lexical.Agents[i].Similarity, lexical.Agents[i].MatchedSkill = store.Similarity(vector, lexical.Agents[i].Agent)
+	}
+
+	return &store.SearchResult{Agents: fuse(semantic.Agents, lexical.Agents, fusion, limit)}, nil
+}
+
+// setSimilarity records the embedding search scores of agents as their
+// similarity.
+func setSimilarity(agents []store.ScoredAgent) {
+	for i := range agents {
+		agents[i].Similarity = agents[i].Score
+	}
 }
 
 // ValidateAgentCard validates required fields in an AgentCard.
@@ -272,6 +546,66 @@ func validateAgentID(id string) error {
 	return nil
 }
 
+// cardEmbedding holds the embedding of an agent card and of each of its skills.
+type cardEmbedding struct {
+	// card is the embedding of the whole card.
+	card []float32
+	// skills holds the embedding of each skill by skill ID.
+	skills map[string][]float32
+}
+
+// embedCard generates the embedding of an agent card and of each of its skills
+// in one batch, or nil if no embedder is configured.
+func (s *RegistryService) embedCard(ctx context.Context, card a2a.AgentCard) ([]float32, map[string][]float32, error) {
+	if s.embedder == nil {
+		return nil, nil, nil
+	}
+
+	embeddings, err := s.embedCards(ctx, []a2a.AgentCard{card})
+	if err != nil {
+		return nil, nil, err
+	}
+	return embeddings[0].card, embeddings[0].skills, nil
+}
+
+// embedCards generates the embeddings of agent cards and their skills in one batch.
+func (s *RegistryService) embedCards(ctx context.Context, cards []a2a.AgentCard) ([]cardEmbedding, error) {
+	var texts []string
+	for _, card := range cards {
+		texts = append(texts, buildEmbeddingText(card))
+		for _, skill := range card.Skills {
+			texts = append(texts, buildSkillEmbeddingText(skill))
+		}
+	}
+
+	embeddings, err := s.embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("generate embedding: %w", err)
+	}
+
+	result := make([]cardEmbedding, len(cards))
+	if len(embeddings) == 0 {
+		return result, nil
+	}
+	if len(embeddings) != len(texts) {
+		return nil, fmt.Errorf("generate embedding: got %d embeddings for %d texts", len(embeddings), len(texts))
+	}
+
+	next := 0
+	for i, card := range cards {
+		result[i].card = embeddings[next]
+		next++
+		if len(card.Skills) > 0 {
+			result[i].skills = make(map[string][]float32, len(card.Skills))
+			for _, skill := range card.Skills {
+				result[i].skills[skill.ID] = embeddings[next]
+				next++
+			}
+		}
+	}
+	return result, nil
+}
+
 // buildEmbeddingText constructs the text to embed from an agent card.
 func buildEmbeddingText(card a2a.AgentCard) string {
 	var parts []string
@@ -287,3 +621,14 @@ func buildEmbeddingText(card a2a.AgentCard) string {
 	}
 	return strings.Join(parts, " ")
 }
+
+// buildSkillEmbeddingText constructs the text to embed for a single skill.
+func buildSkillEmbeddingText(skill a2a.AgentSkill) string {
+	parts := []string{skill.Name}
+	if skill.Description != "" {
+		parts = append(parts, skill.Description)
+	}
+	parts = append(parts, skill.Tags...)
+	parts = append(parts, skill.Examples...)
+	return strings.Join(parts, " ")
+}
@@ -2,6 +2,8 @@ package registry
 
 import (
 	"context"
+	"errors"
+	"maps"
 	"strings"
 	"testing"
 
@@ -357,3 +359,221 @@ func TestRegistryService_Delete_NotFound(t *testing.T) {
 		t.Errorf("Delete() error = %v, want ErrNotFound", err)
 	}
 }
+
+func TestRegistryService_CountAgents(t *testing.T) {
+	t.Parallel()
+	svc := NewRegistryService(store.NewMemoryStore())
+	for id, tags := range map[string][]string{
+		"a1": {"security", "scanner"},
+		"a2": {"security"},
+		"a3": nil,
+	} {
+		input := validCreateInput()
+		input.ID = id
+		input.Tags = tags
+		if _, err := svc.Create(context.Background(), input); err != nil {
+			t.Fatalf("Create(%s) error = %v", id, err)
+		}
+	}
+
+	total, byTag, err := svc.CountAgents(context.Background())
+	if err != nil {
+		t.Fatalf("CountAgents() error = %v", err)
+	}
+	if total != 3 {
+		t.Errorf("total = %d, want 3", total)
+	}
+	want := map[string]int{"security": 2, "scanner": 1}
+	if !maps.Equal(byTag, want) {
+		t.Errorf("byTag = %v, want %v", byTag, want)
+	}
+}
+
+// countingStore is a MemoryStore that counts agents without listing them.
+type countingStore struct {
+	*store.MemoryStore
+}
+
+func (countingStore) ListAgents(context.Context, store.AgentFilter) (*store.AgentListResult, error) {
+	return nil, errors.New("ListAgents must not be called")
+}
+
+func (countingStore) CountAgents(context.Context) (int, map[string]int, error) {
+	return 7, map[string]int{"security": 4}, nil
+}
+
+func TestRegistryService_CountAgents_Counter(t *testing.T) {
+	t.Parallel()
+	svc := NewRegistryService(countingStore{MemoryStore: store.NewMemoryStore()})
+
+	total, byTag, err := svc.CountAgents(context.Background())
+	if err != nil {
+		t.Fatalf("CountAgents() error = %v", err)
+	}
+	if total != 7 || !maps.Equal(byTag, map[string]int{"security": 4}) {
+		t.Errorf("CountAgents() = %d %v, want 7 map[security:4]", total, byTag)
+	}
+}
+
+// fixedEmbedder returns the same vector for every text.
+type fixedEmbedder struct {
+	vector []float32
+}
+
+func (e fixedEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	for i := range texts {
+		embeddings[i] = e.vector
+	}
+	return embeddings, nil
+}
+
+func (e fixedEmbedder) Dimensions() int {
+	return len(e.vector)
+}
+
+// wordEmbedder embeds texts as counts of a few words so that skill matches are deterministic.
+type wordEmbedder struct{}
+
+var embedderWords = []string{"forecast", "translate", "general"}
+
+func (wordEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, len(embedderWords))
+		for j, word := range embedderWords {
+			vector[j] = float32(strings.Count(strings.ToLower(text), word)) + 0.01
+		}
+		embeddings[i] = vector
+	}
+	return embeddings, nil
+}
+
+func (wordEmbedder) Dimensions() int {
+	return len(embedderWords)
+}
+
+func TestRegistryService_Discover_MatchedSkill(t *testing.T) {
+	t.Parallel()
+
+	card := validAgentCard()
+	card.Description = "A general purpose helper"
+	card.Skills = []a2a.AgentSkill{
+		{ID: "weather", Name: "Weather", Description: "Daily weather", Examples: []string{"What is the forecast?"}},
+		{ID: "translation", Name: "Translation", Tags: []string{"translate"}},
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		fusion    *Fusion
+		wantSkill string
+	}{
+		{name: "example text matches skill", query: "forecast", wantSkill: "weather"},
+		{name: "tag matches skill", query: "translate", wantSkill: "translation"},
+		{name: "card matches best", query: "general", wantSkill: ""},
+		{name: "hybrid keeps matched skill", query: "translate", fusion: &Fusion{SemanticWeight: 1, LexicalWeight: 1}, wantSkill: "translation"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc := NewRegistryService(store.NewMemoryStore(), WithEmbedder(wordEmbedder{}))
+
+			agent, err := svc.Create(context.Background(), CreateInput{ID: "helper", Card: card})
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			if len(agent.SkillEmbeddings) != len(card.Skills) {
+				t.Fatalf("Create() skill embeddings = %d, want %d", len(agent.SkillEmbeddings), len(card.Skills))
+			}
+
+			result, err := svc.Discover(context.Background(), DiscoverInput{Query: tt.query, Fusion: tt.fusion})
+			if err != nil {
+				t.Fatalf("Discover() error = %v", err)
+			}
+			if len(result.Agents) != 1 {
+				t.Fatalf("Discover() returned %d agents, want 1", len(result.Agents))
+			}
+			if got := result.Agents[0].MatchedSkill; got != tt.wantSkill {
+				t.Errorf("MatchedSkill = %q, want %q", got, tt.wantSkill)
+			}
+		})
+	}
+}
+
+func TestHealthPolicy_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		policy  HealthPolicy
+		wantErr bool
+	}{
+		{policy: HealthPolicyIgnore},
+		{policy: HealthPolicyDownrank},
+		{policy: HealthPolicyExclude},
+		{policy: "", wantErr: true},
+		{policy: "Downrank", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		err := tt.policy.Validate()
+		if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrInvalidHealthPolicy)) {
+			t.Errorf("HealthPolicy(%q).Validate() error = %v, wantErr %v", tt.policy, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRegistryService_Discover_HealthPolicy(t *testing.T) {
+	t.Parallel()
+
+	// "down" is the closest match but unhealthy.
+	agents := []*store.RegisteredAgent{
+		{ID: "down", Card: validAgentCard(), Embedding: []float32{1, 0}, Health: store.AgentHealth{Status: store.HealthUnhealthy}},
+		{ID: "up", Card: validAgentCard(), Embedding: []float32{1, 1}, Health: store.AgentHealth{Status: store.HealthHealthy}},
+		{ID: "unknown", Card: validAgentCard(), Embedding: []float32{0, 1}},
+	}
+
+	tests := []struct {
+		name     string
+		policy   HealthPolicy
+		limit    int
+		minScore float32
+		wantIDs  []string
+	}{
+		{name: "ignore", policy: HealthPolicyIgnore, limit: 3, wantIDs: []string{"down", "up", "unknown"}},
+		{name: "downrank", policy: HealthPolicyDownrank, limit: 3, wantIDs: []string{"up", "unknown", "down"}},
+		{name: "downrank with full healthy page", policy: HealthPolicyDownrank, limit: 1, wantIDs: []string{"up"}},
+		{name: "exclude", policy: HealthPolicyExclude, limit: 3, wantIDs: []string{"up", "unknown"}},
+		{name: "min score drops weak matches", policy: HealthPolicyIgnore, limit: 3, minScore: 0.5, wantIDs: []string{"down", "up"}},
+		{name: "min score applies to downranked matches", policy: HealthPolicyDownrank, limit: 3, minScore: 0.5, wantIDs: []string{"up", "down"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s := store.NewMemoryStore()
+			for _, a := range agents {
+				agent := *a
+				_ = s.CreateAgent(context.Background(), &agent)
+			}
+			svc := NewRegistryService(s,
+				WithEmbedder(fixedEmbedder{vector: []float32{1, 0}}),
+				WithHealthPolicy(tt.policy),
+			)
+
+			result, err := svc.Discover(context.Background(), DiscoverInput{Query: "test", Limit: tt.limit, MinScore: &tt.minScore})
+			if err != nil {
+				t.Fatalf("Discover() error = %v", err)
+			}
+
+			var gotIDs []string
+			for _, scored := range result.Agents {
+				gotIDs = append(gotIDs, scored.Agent.ID)
+			}
+			if strings.Join(gotIDs, ",") != strings.Join(tt.wantIDs, ",") {
+				t.Errorf("Discover() IDs = %v, want %v", gotIDs, tt.wantIDs)
+			}
+		})
+	}
+}
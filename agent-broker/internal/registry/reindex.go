@@ -0,0 +1,335 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+)
+
+// ErrReindexRunning is returned when a reindex is started while another runs.
+var ErrReindexRunning = errors.New("reindex already running")
+
+// ErrReindexUnsupported is returned when the store cannot be reindexed.
+var ErrReindexUnsupported = errors.New("store does not support reindexing")
+
+// defaultReindexBatchSize is the number of agents embedded per request when
+// ReindexInput.BatchSize is not set.
+const defaultReindexBatchSize = 32
+
+// ReindexState is the lifecycle state of a reindex.
+type ReindexState string
+
+const (
+	// ReindexIdle means no reindex has run since the service started.
+	ReindexIdle ReindexState = "idle"
+	// ReindexRunning means a reindex is copying agents.
+	ReindexRunning ReindexState = "running"
+	// ReindexCompleted means the last reindex switched the store to the new index.
+	ReindexCompleted ReindexState = "completed"
+	// ReindexFailed means the last reindex stopped with an error. Running it
+	// again resumes from its last checkpoint.
+	ReindexFailed ReindexState = "failed"
+)
+
+// ReindexStatus reports the progress of a reindex.
+type ReindexStatus struct {
+	// State is the lifecycle state.
+	State ReindexState
+	// Target is the name of the index being filled.
+	Target string
+	// Previous is the index replaced by the switch, kept for rollback. Empty
+	// until the switch or if the previous index was removed.
+	Previous string
+	// Total is the number of agents in the store when the reindex started.
+	Total int
+	// Copied is the number of agents written to the new index so far.
+	Copied int
+	// Resumed is true if the reindex continued from a checkpoint.
+	Resumed bool
+	// StartedAt is when the reindex started.
+	StartedAt time.Time
+	// FinishedAt is when the reindex completed or failed.
+	FinishedAt time.Time
+	// Error is the failure of a failed reindex.
+	Error string
+}
+
+// ReindexInput contains input for reindexing the store.
+type ReindexInput struct {
+	// BatchSize is the number of agents embedded per request, 32 if zero.
+	BatchSize int
+	// Progress is called with the status after each batch (optional).
+	Progress func(ReindexStatus)
+}
+
+// Reindex re-embeds every agent with the current embedder into a new index
+// of the embedder's dimension, then switches the store to it. Progress is
+// checkpointed after each batch, so a reindex that fails or is interrupted
+// resumes where it stopped when run again.
+//
+// Agents registered or updated while the copy runs are picked up by a final
+// pass before the switch; writes made during that pass may be lost.
+func (s *RegistryService) Reindex(ctx context.Context, input ReindexInput) (ReindexStatus, error) {
+	reindexer, err := s.startReindex()
+	if err != nil {
+		return ReindexStatus{}, err
+	}
+
+	err = s.runReindex(ctx, reindexer, input)
+	return s.finishReindex(err), err
+}
+
+// StartReindex starts Reindex in the background and returns its initial
+// status. The reindex outlives ctx; poll ReindexStatus for its progress.
+func (s *RegistryService) StartReindex(ctx context.Context, input ReindexInput) (ReindexStatus, error) {
+	reindexer, err := s.startReindex()
+	if err != nil {
+		return ReindexStatus{}, err
+	}
+
+	status := s.ReindexStatus()
+	go func() {
+		err := s.runReindex(context.WithoutCancel(ctx), reindexer, input)
+		s.finishReindex(err)
+	}()
+	return status, nil
+}
+
+// ReindexStatus returns the progress of the running or last reindex.
+func (s *RegistryService) ReindexStatus() ReindexStatus {
+	s.reindexMu.Lock()
+	defer s.reindexMu.Unlock()
+	return s.reindexStatus
+}
+
+// startReindex checks that a reindex can run and marks it running.
+func (s *RegistryService) startReindex() (store.Reindexer, error) {
+	if s.embedder == nil {
+		return nil, ErrNoEmbedder
+	}
+	reindexer, ok := s.store.(store.Reindexer)
+	if !ok {
+		return nil, ErrReindexUnsupported
+	}
+
+	s.reindexMu.Lock()
+	defer s.reindexMu.Unlock()
+
+	if s.reindexStatus.State == ReindexRunning {
+		return nil, ErrReindexRunning
+	}
+	s.reindexStatus = ReindexStatus{State: ReindexRunning, StartedAt: time.Now()}
+	return reindexer, nil
+}
+
+// finishReindex records the outcome of a reindex and returns the final status.
+func (s *RegistryService) finishReindex(err error) ReindexStatus {
+	s.reindexMu.Lock()
+	defer s.reindexMu.Unlock()
+
+	s.reindexStatus.State = ReindexCompleted
+	if err != nil {
+		s.reindexStatus.State = ReindexFailed
+		s.reindexStatus.Error = err.Error()
+	}
+	s.reindexStatus.FinishedAt = time.Now()
+	return s.reindexStatus
+}
+
+// updateReindex applies update to the running status and reports it to progress.
+func (s *RegistryService) updateReindex(progress func(ReindexStatus), update func(*ReindexStatus)) {
+	s.reindexMu.Lock()
+	update(&s.reindexStatus)
+	status := s.reindexStatus
+	s.reindexMu.Unlock()
+
+	if progress != nil {
+		progress(status)
+	}
+}
+
+// runReindex copies every agent into a reindex target, reconciles changes
+// made meanwhile and switches the store to the target.
+func (s *RegistryService) runReindex(ctx context.Context, reindexer store.Reindexer, input ReindexInput) error {
+	batchSize := input.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultReindexBatchSize
+	}
+
+	target, err := reindexer.ReindexTarget(ctx, s.embeddingModel, s.embedder.Dimensions())
+	if err != nil {
+		return fmt.Errorf("open reindex target: %w", err)
+	}
+	defer target.Close()
+
+	cursor, err := target.Checkpoint(ctx)
+	if err != nil {
+		return fmt.Errorf("read checkpoint: %w", err)
+	}
+	total, err := s.store.ListAgents(ctx, store.AgentFilter{Limit: 1})
+	if err != nil {
+		return fmt.Errorf("count agents: %w", err)
+	}
+	copied := 0
+	if cursor != "" {
+		done, err := target.ListAgents(ctx, store.AgentFilter{Limit: 1})
+		if err != nil {
+			return fmt.Errorf("count copied agents: %w", err)
+		}
+		copied = done.Total
+	}
+	s.updateReindex(input.Progress, func(st *ReindexStatus) {
+		st.Target = target.Name()
+		st.Total = total.Total
+		st.Copied = copied
+		st.Resumed = cursor != ""
+	})
+
+	for {
+		page, err := s.store.ListAgents(ctx, store.AgentFilter{
+			Limit:  batchSize,
+			Cursor: cursor,
+			Sort:   store.SortByCreatedAt,
+			Order:  store.SortAsc,
+		})
+		if errors.Is(err, store.ErrInvalidCursor) && cursor != "" {
+			// The checkpoint no longer matches the store, start over and
+			// overwrite what was already copied.
+			cursor = ""
+			s.updateReindex(input.Progress, func(st *ReindexStatus) { st.Copied = 0 })
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("list agents: %w", err)
+		}
+
+		if err := s.copyAgents(ctx, target, page.Agents); err != nil {
+			return err
+		}
+		if err := target.SaveCheckpoint(ctx, page.NextCursor); err != nil {
+			return fmt.Errorf("save checkpoint: %w", err)
+		}
+		s.updateReindex(input.Progress, func(st *ReindexStatus) {
+			st.Copied += len(page.Agents)
+		})
+
+		cursor = page.NextCursor
+		if cursor == "" {
+			break
+		}
+	}
+
+	if err := s.reconcile(ctx, target, batchSize); err != nil {
+		return err
+	}
+
+	previous, err := reindexer.SwitchIndex(ctx, target)
+	if err != nil {
+		return fmt.Errorf("switch index: %w", err)
+	}
+	s.updateReindex(input.Progress, func(st *ReindexStatus) {
+		st.Previous = previous
+	})
+	return nil
+}
+
+// reconcile brings the target in line with changes made to the store while
+// agents were copied: new and updated agents are re-embedded, health and
+// leases are synced and deleted agents are removed.
+func (s *RegistryService) reconcile(ctx context.Context, target store.ReindexTarget, batchSize int) error {
+	source, err := s.store.ListAgents(ctx, store.AgentFilter{})
+	if err != nil {
+		return fmt.Errorf("list agents: %w", err)
+	}
+	copied, err := target.ListAgents(ctx, store.AgentFilter{})
+	if err != nil {
+		return fmt.Errorf("list copied agents: %w", err)
+	}
+
+	existing := make(map[string]*store.RegisteredAgent, len(copied.Agents))
+	for _, agent := range copied.Agents {
+		existing[agent.ID] = agent
+	}
+
+	var stale []*store.RegisteredAgent
+	for _, agent := range source.Agents {
+		dst, ok := existing[agent.ID]
+		delete(existing, agent.ID)
+		if !ok || !dst.UpdatedAt.Equal(agent.UpdatedAt) {
+			stale = append(stale, agent)
+			continue
+		}
+		if dst.Health != agent.Health {
+			if err := target.SetAgentHealth(ctx, agent.ID, agent.Health); err != nil {
+				return fmt.Errorf("sync agent health: %w", err)
+			}
+		}
+		if !dst.ExpiresAt.Equal(agent.ExpiresAt) {
+			if err := target.RenewAgentLease(ctx, agent.ID, agent.ExpiresAt); err != nil {
+				return fmt.Errorf("sync agent lease: %w", err)
+			}
+		}
+	}
+
+	for start := 0; start < len(stale); start += batchSize {
+		if err := s.copyAgents(ctx, target, stale[start:min(start+batchSize, len(stale))]); err != nil {
+			return err
+		}
+	}
+
+	for id := range existing {
+		if err := target.DeleteAgent(ctx, id); err != nil && !errors.Is(err, store.ErrNotFound) {
+			return fmt.Errorf("delete agent: %w", err)
+		}
+	}
+	return nil
+}
+
+// copyAgents re-embeds agents in one batch and writes them to the target,
+// replacing copies left by an earlier attempt.
+func (s *RegistryService) copyAgents(ctx context.Context, target store.Store, agents []*store.RegisteredAgent) error {
+	if len(agents) == 0 {
+		return nil
+	}
+
+	cards := make([]a2a.AgentCard, len(agents))
+	for i, agent := range agents {
+		cards[i] = agent.Card
+	}
+	embeddings, err := s.embedCards(ctx, cards)
+	if err != nil {
+		return err
+	}
+
+	for i, agent := range agents {
+		copied := *agent
+		copied.Embedding = embeddings[i].card
+		copied.SkillEmbeddings = embeddings[i].skills
+
+		err := target.CreateAgent(ctx, &copied)
+		if errors.Is(err, store.ErrAlreadyExists) {
+			err = replaceAgent(ctx, target, &copied)
+		}
+		if err != nil {
+			return fmt.Errorf("store agent %s: %w", agent.ID, err)
+		}
+	}
+	return nil
+}
+
+// replaceAgent overwrites the copy of agent in target, including the health
+// and lease expiry UpdateAgent keeps.
+func replaceAgent(ctx context.Context, target store.Store, agent *store.RegisteredAgent) error {
+	if err := target.UpdateAgent(ctx, agent); err != nil {
+		return err
+	}
+	if err := target.SetAgentHealth(ctx, agent.ID, agent.Health); err != nil {
+		return err
+	}
+	return target.RenewAgentLease(ctx, agent.ID, agent.ExpiresAt)
+}
@@ -0,0 +1,247 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+)
+
+// flakyEmbedder is a wordEmbedder that fails its failAt-th call and counts calls.
+type flakyEmbedder struct {
+	wordEmbedder
+	failAt int
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (e *flakyEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	e.mu.Lock()
+	e.calls++
+	call := e.calls
+	e.mu.Unlock()
+
+	if call == e.failAt {
+		return nil, errors.New("embedder unavailable")
+	}
+	return e.wordEmbedder.Embed(ctx, texts)
+}
+
+func (e *flakyEmbedder) callCount() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.calls
+}
+
+// seedAgents registers n agents embedded with a 2-dimensional model.
+func seedAgents(t *testing.T, s store.Store, n int) {
+	t.Helper()
+	svc := NewRegistryService(s, WithEmbedder(fixedEmbedder{vector: []float32{1, 0}}))
+	for i := range n {
+		input := validCreateInput()
+		input.ID = fmt.Sprintf("agent-%d", i)
+		input.LeaseTTL = time.Hour
+		if _, err := svc.Create(context.Background(), input); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+}
+
+func assertReindexed(t *testing.T, s store.Store, want int) {
+	t.Helper()
+	result, err := s.ListAgents(context.Background(), store.AgentFilter{})
+	if err != nil {
+		t.Fatalf("ListAgents() error = %v", err)
+	}
+	if result.Total != want {
+		t.Errorf("agents = %d, want %d", result.Total, want)
+	}
+	for _, agent := range result.Agents {
+		if len(agent.Embedding) != len(embedderWords) {
+			t.Errorf("agent %s embedding dimension = %d, want %d", agent.ID, len(agent.Embedding), len(embedderWords))
+		}
+		if len(agent.SkillEmbeddings) != len(agent.Card.Skills) {
+			t.Errorf("agent %s has %d skill embeddings, want %d", agent.ID, len(agent.SkillEmbeddings), len(agent.Card.Skills))
+		}
+		if agent.ExpiresAt.IsZero() || agent.CreatedAt.IsZero() {
+			t.Errorf("agent %s lost its lease or timestamps", agent.ID)
+		}
+	}
+}
+
+func TestRegistryService_Reindex(t *testing.T) {
+	t.Parallel()
+
+	s := store.NewMemoryStore()
+	seedAgents(t, s, 5)
+
+	embedder := &flakyEmbedder{}
+	svc := NewRegistryService(s, WithEmbedder(embedder), WithEmbeddingModel("words"))
+
+	var progress []ReindexStatus
+	status, err := svc.Reindex(context.Background(), ReindexInput{
+		BatchSize: 2,
+		Progress:  func(st ReindexStatus) { progress = append(progress, st) },
+	})
+	if err != nil {
+		t.Fatalf("Reindex() error = %v", err)
+	}
+
+	if status.State != ReindexCompleted || status.Total != 5 || status.Copied != 5 || status.Resumed {
+		t.Errorf("Reindex() status = %+v, want completed 5/5", status)
+	}
+	if got := embedder.callCount(); got != 3 {
+		t.Errorf("embedder calls = %d, want 3 batches", got)
+	}
+	var copied []int
+	for _, st := range progress {
+		copied = append(copied, st.Copied)
+	}
+	if fmt.Sprint(copied) != "[0 2 4 5 5]" {
+		t.Errorf("progress copied = %v, want [0 2 4 5 5]", copied)
+	}
+	if got := svc.ReindexStatus(); got.State != ReindexCompleted {
+		t.Errorf("ReindexStatus().State = %q, want %q", got.State, ReindexCompleted)
+	}
+	assertReindexed(t, s, 5)
+}
+
+func TestRegistryService_Reindex_Resume(t *testing.T) {
+	t.Parallel()
+
+	s := store.NewMemoryStore()
+	seedAgents(t, s, 5)
+
+	failing := NewRegistryService(s, WithEmbedder(&flakyEmbedder{failAt: 2}), WithEmbeddingModel("words"))
+	status, err := failing.Reindex(context.Background(), ReindexInput{BatchSize: 2})
+	if err == nil {
+		t.Fatal("Reindex() error = nil, want embedder error")
+	}
+	if status.State != ReindexFailed || status.Copied != 2 || status.Error == "" {
+		t.Errorf("Reindex() status = %+v, want failed after 2 agents", status)
+	}
+
+	embedder := &flakyEmbedder{}
+	svc := NewRegistryService(s, WithEmbedder(embedder), WithEmbeddingModel("words"))
+	status, err = svc.Reindex(context.Background(), ReindexInput{BatchSize: 2})
+	if err != nil {
+		t.Fatalf("Reindex() resume error = %v", err)
+	}
+	if !status.Resumed || status.Copied != 5 {
+		t.Errorf("Reindex() resume status = %+v, want resumed 5/5", status)
+	}
+	if got := embedder.callCount(); got != 2 {
+		t.Errorf("embedder calls on resume = %d, want 2 remaining batches", got)
+	}
+	assertReindexed(t, s, 5)
+}
+
+func TestRegistryService_Reindex_Reconcile(t *testing.T) {
+	t.Parallel()
+
+	s := store.NewMemoryStore()
+	seedAgents(t, s, 4)
+
+	svc := NewRegistryService(s, WithEmbedder(wordEmbedder{}), WithEmbeddingModel("words"))
+	ctx := context.Background()
+	health := store.AgentHealth{Status: store.HealthUnhealthy, ConsecutiveFailures: 3}
+	changed := false
+
+	// Change agents that were already copied once the first batch is done.
+	_, err := svc.Reindex(ctx, ReindexInput{
+		BatchSize: 3,
+		Progress: func(st ReindexStatus) {
+			if st.Copied != 3 || changed {
+				return
+			}
+			changed = true
+			card := validAgentCard()
+			card.Description = "Updated while reindexing"
+			if _, err := svc.Update(ctx, UpdateInput{ID: "agent-0", Card: card}); err != nil {
+				t.Errorf("Update() error = %v", err)
+			}
+			if err := svc.Delete(ctx, "agent-1"); err != nil {
+				t.Errorf("Delete() error = %v", err)
+			}
+			if err := s.SetAgentHealth(ctx, "agent-2", health); err != nil {
+				t.Errorf("SetAgentHealth() error = %v", err)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("Reindex() error = %v", err)
+	}
+
+	assertReindexed(t, s, 3)
+	updated, err := s.GetAgent(ctx, "agent-0")
+	if err != nil {
+		t.Fatalf("GetAgent() error = %v", err)
+	}
+	if updated.Card.Description != "Updated while reindexing" {
+		t.Errorf("agent-0 description = %q, want the update made during the reindex", updated.Card.Description)
+	}
+	if _, err := s.GetAgent(ctx, "agent-1"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("GetAgent(agent-1) error = %v, want ErrNotFound", err)
+	}
+	synced, err := s.GetAgent(ctx, "agent-2")
+	if err != nil {
+		t.Fatalf("GetAgent() error = %v", err)
+	}
+	if synced.Health != health {
+		t.Errorf("agent-2 health = %+v, want %+v", synced.Health, health)
+	}
+}
+
+func TestRegistryService_Reindex_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no embedder", func(t *testing.T) {
+		t.Parallel()
+		svc := NewRegistryService(store.NewMemoryStore())
+		if _, err := svc.Reindex(context.Background(), ReindexInput{}); !errors.Is(err, ErrNoEmbedder) {
+			t.Errorf("Reindex() error = %v, want ErrNoEmbedder", err)
+		}
+	})
+
+	t.Run("unsupported store", func(t *testing.T) {
+		t.Parallel()
+		bolt, err := store.NewBoltStore(filepath.Join(t.TempDir(), "agents.db"))
+		if err != nil {
+			t.Fatalf("NewBoltStore() error = %v", err)
+		}
+		t.Cleanup(func() { _ = bolt.Close() })
+
+		svc := NewRegistryService(bolt, WithEmbedder(wordEmbedder{}))
+		if _, err := svc.Reindex(context.Background(), ReindexInput{}); !errors.Is(err, ErrReindexUnsupported) {
+			t.Errorf("Reindex() error = %v, want ErrReindexUnsupported", err)
+		}
+	})
+
+	t.Run("already running", func(t *testing.T) {
+		t.Parallel()
+		s := store.NewMemoryStore()
+		seedAgents(t, s, 1)
+		svc := NewRegistryService(s, WithEmbedder(wordEmbedder{}))
+
+		var nested error
+		_, err := svc.Reindex(context.Background(), ReindexInput{
+			Progress: func(ReindexStatus) {
+				if nested == nil {
+					_, nested = svc.StartReindex(context.Background(), ReindexInput{})
+				}
+			},
+		})
+		if err != nil {
+			t.Fatalf("Reindex() error = %v", err)
+		}
+		if !errors.Is(nested, ErrReindexRunning) {
+			t.Errorf("StartReindex() while running error = %v, want ErrReindexRunning", nested)
+		}
+	})
+}
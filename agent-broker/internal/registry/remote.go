@@ -0,0 +1,183 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+)
+
+// ErrCardFetch is returned when an agent card cannot be fetched from its URL.
+var ErrCardFetch = errors.New("fetch agent card")
+
+// ErrNoSourceURL is returned when refreshing an agent that was not registered by URL.
+var ErrNoSourceURL = errors.New("agent has no source URL")
+
+var agentIDInvalidChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// RegisterFromURLInput contains input for registering an agent by its card URL.
+type RegisterFromURLInput struct {
+	// URL is the agent's base URL serving /.well-known/agent-card.json.
+	URL string
+	// ID is the unique agent identifier, derived from the card name if empty.
+	ID string
+	// Tags are classification tags.
+	Tags []string
+	// LeaseTTL is the registration lease, renewed by heartbeats. Zero means no expiry.
+	LeaseTTL time.Duration
+}
+
+// RegisterFromURL fetches the agent card from the agent's well-known URL and registers it.
+func (s *RegistryService) RegisterFromURL(ctx context.Context, input RegisterFromURLInput) (*store.RegisteredAgent, error) {
+	if err := validateSourceURL(input.URL); err != nil {
+		return nil, err
+	}
+	if err := validateLeaseTTL(input.LeaseTTL); err != nil {
+		return nil, err
+	}
+
+	card, err := s.fetchCard(ctx, input.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	id := input.ID
+	if id == "" {
+		id = agentIDFromName(card.Name)
+	}
+	if err := validateAgentID(id); err != nil {
+		return nil, err
+	}
+	if err := ValidateAgentCard(*card); err != nil {
+		return nil, err
+	}
+
+	emb, skillEmbs, err := s.embedCard(ctx, *card)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	agent := &store.RegisteredAgent{
+		ID:              id,
+		Card:            *card,
+		Tags:            input.Tags,
+		Embedding:       emb,
+		SkillEmbeddings: skillEmbs,
+		SourceURL:       input.URL,
+		LeaseTTL:        input.LeaseTTL,
+		ExpiresAt:       leaseExpiry(now, input.LeaseTTL),
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	if err := s.store.CreateAgent(ctx, agent); err != nil {
+		return nil, err
+	}
+
+	return agent, nil
+}
+
+// RefreshResult contains the outcome of refreshing an agent card.
+type RefreshResult struct {
+	// Agent is the agent after the refresh.
+	Agent *store.RegisteredAgent
+	// Changed reports whether the fetched card differed from the stored one.
+	Changed bool
+}
+
+// Refresh re-fetches the card of an agent registered by URL. The agent is
+// re-embedded and updated only if the card changed.
+func (s *RegistryService) Refresh(ctx context.Context, id string) (*RefreshResult, error) {
+	existing, err := s.store.GetAgent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing.SourceURL == "" {
+		return nil, ErrNoSourceURL
+	}
+
+	card, err := s.fetchCard(ctx, existing.SourceURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateAgentCard(*card); err != nil {
+		return nil, err
+	}
+
+	changed, err := cardChanged(existing.Card, *card)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return &RefreshResult{Agent: existing}, nil
+	}
+
+	emb, skillEmbs, err := s.embedCard(ctx, *card)
+	if err != nil {
+		return nil, err
+	}
+
+	existing.Card = *card
+	existing.Embedding = emb
+	existing.SkillEmbeddings = skillEmbs
+	existing.UpdatedAt = time.Now()
+
+	if err := s.store.UpdateAgent(ctx, existing); err != nil {
+		return nil, err
+	}
+
+	return &RefreshResult{Agent: existing, Changed: true}, nil
+}
+
+// fetchCard fetches the agent card published under baseURL.
+func (s *RegistryService) fetchCard(ctx context.Context, baseURL string) (*a2a.AgentCard, error) {
+	card, err := s.resolver.Resolve(ctx, baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCardFetch, err)
+	}
+	return card, nil
+}
+
+// cardChanged reports whether two cards differ in their JSON representation.
+func cardChanged(stored, fetched a2a.AgentCard) (bool, error) {
+	storedJSON, err := json.Marshal(stored)
+	if err != nil {
+		return false, fmt.Errorf("marshal agent card: %w", err)
+	}
+	fetchedJSON, err := json.Marshal(fetched)
+	if err != nil {
+		return false, fmt.Errorf("marshal agent card: %w", err)
+	}
+	return !bytes.Equal(storedJSON, fetchedJSON), nil
+}
+
+func validateSourceURL(rawURL string) error {
+	if rawURL == "" {
+		return fmt.Errorf("url is required")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	return nil
+}
+
+// agentIDFromName derives an agent ID from a card name, e.g. "Weather Agent" becomes "weather-agent".
+func agentIDFromName(name string) string {
+	id := agentIDInvalidChars.ReplaceAllString(strings.ToLower(name), "-")
+	id = strings.Trim(id, "-")
+	if len(id) > 64 {
+		id = strings.TrimRight(id[:64], "-")
+	}
+	return id
+}
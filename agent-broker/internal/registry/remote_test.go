@@ -0,0 +1,221 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/a2aproject/a2a-go/a2a"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+)
+
+// cardServer serves a mutable agent card at /.well-known/agent-card.json.
+type cardServer struct {
+	mu   sync.Mutex
+	card a2a.AgentCard
+}
+
+func (c *cardServer) setCard(card a2a.AgentCard) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.card = card
+}
+
+func (c *cardServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/.well-known/agent-card.json" {
+		http.NotFound(w, r)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(c.card)
+}
+
+func startCardServer(t *testing.T, card a2a.AgentCard) (*cardServer, string) {
+	t.Helper()
+	cs := &cardServer{card: card}
+	server := httptest.NewServer(cs)
+	t.Cleanup(server.Close)
+	return cs, server.URL
+}
+
+func TestRegistryService_RegisterFromURL(t *testing.T) {
+	t.Parallel()
+
+	t.Run("registers fetched card", func(t *testing.T) {
+		t.Parallel()
+		_, url := startCardServer(t, validAgentCard())
+		svc := NewRegistryService(store.NewMemoryStore())
+
+		agent, err := svc.RegisterFromURL(context.Background(), RegisterFromURLInput{
+			URL:  url,
+			ID:   "remote-agent",
+			Tags: []string{"remote"},
+		})
+		if err != nil {
+			t.Fatalf("RegisterFromURL() error = %v", err)
+		}
+		if agent.ID != "remote-agent" {
+			t.Errorf("ID = %v, want remote-agent", agent.ID)
+		}
+		if agent.Card.Name != "Test Agent" {
+			t.Errorf("Card.Name = %v, want Test Agent", agent.Card.Name)
+		}
+		if agent.SourceURL != url {
+			t.Errorf("SourceURL = %v, want %v", agent.SourceURL, url)
+		}
+	})
+
+	t.Run("derives ID from card name", func(t *testing.T) {
+		t.Parallel()
+		_, url := startCardServer(t, validAgentCard())
+		svc := NewRegistryService(store.NewMemoryStore())
+
+		agent, err := svc.RegisterFromURL(context.Background(), RegisterFromURLInput{URL: url})
+		if err != nil {
+			t.Fatalf("RegisterFromURL() error = %v", err)
+		}
+		if agent.ID != "test-agent" {
+			t.Errorf("ID = %v, want test-agent", agent.ID)
+		}
+	})
+
+	t.Run("invalid card rejected", func(t *testing.T) {
+		t.Parallel()
+		card := validAgentCard()
+		card.Skills = nil
+		_, url := startCardServer(t, card)
+		svc := NewRegistryService(store.NewMemoryStore())
+
+		_, err := svc.RegisterFromURL(context.Background(), RegisterFromURLInput{URL: url})
+		if err == nil || !strings.Contains(err.Error(), "at least one skill is required") {
+			t.Errorf("RegisterFromURL() error = %v, want invalid card error", err)
+		}
+	})
+
+	t.Run("unreachable card returns ErrCardFetch", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.NotFoundHandler())
+		t.Cleanup(server.Close)
+		svc := NewRegistryService(store.NewMemoryStore())
+
+		_, err := svc.RegisterFromURL(context.Background(), RegisterFromURLInput{URL: server.URL})
+		if !errors.Is(err, ErrCardFetch) {
+			t.Errorf("RegisterFromURL() error = %v, want ErrCardFetch", err)
+		}
+	})
+
+	t.Run("non-http URL rejected", func(t *testing.T) {
+		t.Parallel()
+		svc := NewRegistryService(store.NewMemoryStore())
+
+		_, err := svc.RegisterFromURL(context.Background(), RegisterFromURLInput{URL: "ftp://agent"})
+		if err == nil || !strings.Contains(err.Error(), "absolute http or https URL") {
+			t.Errorf("RegisterFromURL() error = %v, want URL error", err)
+		}
+	})
+}
+
+func TestRegistryService_Refresh(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unchanged card is not updated", func(t *testing.T) {
+		t.Parallel()
+		_, url := startCardServer(t, validAgentCard())
+		svc := NewRegistryService(store.NewMemoryStore())
+		created, err := svc.RegisterFromURL(context.Background(), RegisterFromURLInput{URL: url})
+		if err != nil {
+			t.Fatalf("RegisterFromURL() error = %v", err)
+		}
+		updatedAt := created.UpdatedAt
+
+		result, err := svc.Refresh(context.Background(), created.ID)
+		if err != nil {
+			t.Fatalf("Refresh() error = %v", err)
+		}
+		if result.Changed {
+			t.Error("Refresh() Changed = true, want false")
+		}
+		if !result.Agent.UpdatedAt.Equal(updatedAt) {
+			t.Error("Refresh() should not touch UpdatedAt")
+		}
+	})
+
+	t.Run("changed card is updated", func(t *testing.T) {
+		t.Parallel()
+		cs, url := startCardServer(t, validAgentCard())
+		svc := NewRegistryService(store.NewMemoryStore())
+		created, err := svc.RegisterFromURL(context.Background(), RegisterFromURLInput{URL: url})
+		if err != nil {
+			t.Fatalf("RegisterFromURL() error = %v", err)
+		}
+
+		card := validAgentCard()
+		card.Description = "Updated description"
+		cs.setCard(card)
+
+		result, err := svc.Refresh(context.Background(), created.ID)
+		if err != nil {
+			t.Fatalf("Refresh() error = %v", err)
+		}
+		if !result.Changed {
+			t.Error("Refresh() Changed = false, want true")
+		}
+		stored, _ := svc.Get(context.Background(), created.ID)
+		if stored.Card.Description != "Updated description" {
+			t.Errorf("Description = %v, want Updated description", stored.Card.Description)
+		}
+	})
+
+	t.Run("agent without source URL", func(t *testing.T) {
+		t.Parallel()
+		svc := NewRegistryService(store.NewMemoryStore())
+		_, _ = svc.Create(context.Background(), validCreateInput())
+
+		_, err := svc.Refresh(context.Background(), "test-agent")
+		if !errors.Is(err, ErrNoSourceURL) {
+			t.Errorf("Refresh() error = %v, want ErrNoSourceURL", err)
+		}
+	})
+
+	t.Run("non-existent agent", func(t *testing.T) {
+		t.Parallel()
+		svc := NewRegistryService(store.NewMemoryStore())
+
+		_, err := svc.Refresh(context.Background(), "not-exists")
+		if !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("Refresh() error = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestAgentIDFromName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "spaces", in: "Weather Agent", want: "weather-agent"},
+		{name: "punctuation", in: "  Travel & Booking (v2)!", want: "travel-booking-v2"},
+		{name: "underscores kept", in: "code_review", want: "code_review"},
+		{name: "truncated", in: strings.Repeat("a", 70), want: strings.Repeat("a", 64)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := agentIDFromName(tt.in); got != tt.want {
+				t.Errorf("agentIDFromName(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+)
+
+// ErrInvalidThresholds is returned when score thresholds are out of range.
+var ErrInvalidThresholds = errors.New("invalid score thresholds")
+
+// nearMissLimit is the most agents dropped by thresholds that Discover reports.
+const nearMissLimit = 3
+
+// Reasons returned by DiscoverResult.Reason when no agent was found.
+const (
+	// ReasonNoMatch means no agent matched the query and filters.
+	ReasonNoMatch = "no matching agents"
+	// ReasonNoConfidentMatch means agents matched but all scored below the
+	// thresholds; they are reported as near misses.
+	ReasonNoConfidentMatch = "no confident match"
+)
+
+// Thresholds decide which Discover matches are confident enough to return.
+type Thresholds struct {
+	// MinScore drops agents whose embedding similarity to the query is below
+	// it, 0 to keep every match. It is compared with the similarity rather
+	// than the ranking score because RRF scores are derived from ranks: the
+	// top semantic match scores high however unrelated it is.
+	MinScore float32
+	// MinRelativeScore drops agents whose ranking score is below this
+	// fraction of the best score, 0 to keep every match. For example 0.8
+	// drops agents scoring less than 80% of the top agent.
+	MinRelativeScore float32
+}
+
+// Validate checks that the thresholds are in range.
+func (t Thresholds) Validate() error {
+	if t.MinScore < 0 || t.MinScore > 1 {
+		return fmt.Errorf("%w: min score must be between 0 and 1", ErrInvalidThresholds)
+	}
+	if t.MinRelativeScore < 0 || t.MinRelativeScore > 1 {
+		return fmt.Errorf("%w: min relative score must be between 0 and 1", ErrInvalidThresholds)
+	}
+	return nil
+}
+
+// apply splits ranked agents into those passing the thresholds and the best
+// of those that do not. The relative threshold is measured against the best
+// score among agents, which need not be the first when unhealthy agents were
+// ranked last.
+func (t Thresholds) apply(agents []store.ScoredAgent) (kept, dropped []store.ScoredAgent) {
+	if len(agents) == 0 {
+		return agents, nil
+	}
+
+	var minRelative float32
+	if t.MinRelativeScore > 0 {
+		best := agents[0].Score
+		for _, scored := range agents[1:] {
+			best = max(best, scored.Score)
+		}
+		if best > 0 {
+			minRelative = best * t.MinRelativeScore
+		}
+	}
+
+	kept = make([]store.ScoredAgent, 0, len(agents))
+	for _, scored := range agents {
+		if scored.Similarity >= t.MinScore && scored.Score >= minRelative {
+			kept = append(kept, scored)
+		} else if len(dropped) < nearMissLimit {
+			dropped = append(dropped, scored)
+		}
+	}
+	return kept, dropped
+}
+
+// Reason explains why no agent was found, empty if some were.
+func (r *DiscoverResult) Reason() string {
+	switch {
+	case len(r.Agents) > 0:
+		return ""
+	case len(r.NearMisses) > 0:
+		return ReasonNoConfidentMatch
+	default:
+		return ReasonNoMatch
+	}
+}
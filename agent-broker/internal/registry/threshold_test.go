@@ -0,0 +1,207 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+)
+
+func scoredIDs(agents []store.ScoredAgent) string {
+	ids := make([]string, len(agents))
+	for i, scored := range agents {
+		ids[i] = scored.Agent.ID
+	}
+	return strings.Join(ids, ",")
+}
+
+// similar returns a semantic search hit, whose score is its similarity.
+func similar(id string, score float32) store.ScoredAgent {
+	return store.ScoredAgent{Agent: &store.RegisteredAgent{ID: id}, Score: score, Similarity: score}
+}
+
+func TestThresholds_Apply(t *testing.T) {
+	t.Parallel()
+
+	agents := []store.ScoredAgent{
+		similar("a", 0.9), similar("b", 0.8), similar("c", 0.5), similar("d", 0.3), similar("e", 0.2), similar("f", 0.1),
+	}
+	// Under RRF the top agent scores high however dissimilar it is.
+	fused := []store.ScoredAgent{
+		{Agent: &store.RegisteredAgent{ID: "unrelated"}, Score: 1, Similarity: 0.2},
+		{Agent: &store.RegisteredAgent{ID: "related"}, Score: 0.95, Similarity: 0.7},
+	}
+
+	tests := []struct {
+		name        string
+		thresholds  Thresholds
+		agents      []store.ScoredAgent
+		wantKept    string
+		wantDropped string
+	}{
+		{name: "no thresholds", agents: agents, wantKept: "a,b,c,d,e,f"},
+		{name: "min score", thresholds: Thresholds{MinScore: 0.5}, agents: agents, wantKept: "a,b,c", wantDropped: "d,e,f"},
+		{name: "relative gap", thresholds: Thresholds{MinRelativeScore: 0.8}, agents: agents, wantKept: "a,b", wantDropped: "c,d,e"},
+		{name: "stricter threshold wins", thresholds: Thresholds{MinScore: 0.85, MinRelativeScore: 0.5}, agents: agents, wantKept: "a", wantDropped: "b,c,d"},
+		{name: "nothing confident", thresholds: Thresholds{MinScore: 0.95}, agents: agents[:2], wantKept: "", wantDropped: "a,b"},
+		{
+			name:        "relative to best, not first",
+			thresholds:  Thresholds{MinRelativeScore: 0.8},
+			agents:      []store.ScoredAgent{similar("healthy", 0.5), similar("unhealthy", 0.9)},
+			wantKept:    "unhealthy",
+			wantDropped: "healthy",
+		},
+		{name: "min score measures similarity", thresholds: Thresholds{MinScore: 0.5}, agents: fused, wantKept: "related", wantDropped: "unrelated"},
+		{name: "relative gap measures fused score", thresholds: Thresholds{MinRelativeScore: 0.99}, agents: fused, wantKept: "unrelated", wantDropped: "related"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			kept, dropped := tt.thresholds.apply(tt.agents)
+			if got := scoredIDs(kept); got != tt.wantKept {
+				t.Errorf("kept = %s, want %s", got, tt.wantKept)
+			}
+			if got := scoredIDs(dropped); got != tt.wantDropped {
+				t.Errorf("dropped = %s, want %s", got, tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestThresholds_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		thresholds Thresholds
+		wantErr    bool
+	}{
+		{name: "zero", thresholds: Thresholds{}},
+		{name: "in range", thresholds: Thresholds{MinScore: 0.4, MinRelativeScore: 0.8}},
+		{name: "negative min score", thresholds: Thresholds{MinScore: -0.1}, wantErr: true},
+		{name: "relative above one", thresholds: Thresholds{MinRelativeScore: 1.5}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.thresholds.Validate()
+			if got := errors.Is(err, ErrInvalidThresholds); got != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegistryService_Discover_Thresholds(t *testing.T) {
+	t.Parallel()
+
+	s := store.NewMemoryStore()
+	for _, a := range []*store.RegisteredAgent{
+		{ID: "close", Card: validAgentCard(), Embedding: []float32{1, 0.1}},
+		{ID: "far", Card: validAgentCard(), Embedding: []float32{0.1, 1}},
+	} {
+		_ = s.CreateAgent(context.Background(), a)
+	}
+	svc := NewRegistryService(s,
+		WithEmbedder(fixedEmbedder{vector: []float32{1, 0}}),
+		WithThresholds(Thresholds{MinScore: 0.5}),
+	)
+
+	result, err := svc.Discover(context.Background(), DiscoverInput{Query: "test"})
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if scoredIDs(result.Agents) != "close" || scoredIDs(result.NearMisses) != "far" {
+		t.Errorf("Discover() = %s near misses %s, want close near misses far",
+			scoredIDs(result.Agents), scoredIDs(result.NearMisses))
+	}
+
+	strict := float32(0.999)
+	result, err = svc.Discover(context.Background(), DiscoverInput{Query: "test", MinScore: &strict})
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if len(result.Agents) != 0 || scoredIDs(result.NearMisses) != "close,far" {
+		t.Errorf("Discover(strict) = %s near misses %s, want none near misses close,far",
+			scoredIDs(result.Agents), scoredIDs(result.NearMisses))
+	}
+
+	invalid := float32(2)
+	if _, err := svc.Discover(context.Background(), DiscoverInput{Query: "test", MinRelativeScore: &invalid}); !errors.Is(err, ErrInvalidThresholds) {
+		t.Errorf("Discover() error = %v, want ErrInvalidThresholds", err)
+	}
+}
+
+func TestRegistryService_Discover_ThresholdsWithRRF(t *testing.T) {
+	t.Parallel()
+
+	keywordCard := validAgentCard()
+	keywordCard.Description = "Weather forecasts"
+	s := store.NewMemoryStore()
+	for _, a := range []*store.RegisteredAgent{
+		{ID: "similar", Card: validAgentCard(), Embedding: []float32{1, 0.1}},
+		{ID: "keyword", Card: keywordCard, Embedding: []float32{0.1, 1}},
+	} {
+		_ = s.CreateAgent(context.Background(), a)
+	}
+	svc := NewRegistryService(s,
+		WithEmbedder(fixedEmbedder{vector: []float32{1, 0}}),
+		WithFusion(Fusion{Method: FusionRRF, SemanticWeight: 1, LexicalWeight: 1}),
+		WithThresholds(Thresholds{MinScore: 0.5}),
+	)
+
+	result, err := svc.Discover(context.Background(), DiscoverInput{Query: "weather"})
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	// The keyword agent ranks first by RRF but is not similar enough.
+	if scoredIDs(result.Agents) != "similar" || scoredIDs(result.NearMisses) != "keyword" {
+		t.Errorf("Discover() = %s near misses %s, want similar near misses keyword",
+			scoredIDs(result.Agents), scoredIDs(result.NearMisses))
+	}
+
+	result, err = svc.Discover(context.Background(), DiscoverInput{Query: "weather", MinScore: new(float32)})
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if scoredIDs(result.Agents) != "keyword,similar" {
+		t.Errorf("Discover(no threshold) = %s, want keyword,similar", scoredIDs(result.Agents))
+	}
+}
+
+func TestRegistryService_Discover_ThresholdsLexicalOnly(t *testing.T) {
+	t.Parallel()
+
+	// The keyword agent is similar enough but ranks below the semantic
+	// candidates, so it is only found by keywords.
+	keywordCard := validAgentCard()
+	keywordCard.Description = "Weather forecasts"
+	s := store.NewMemoryStore()
+	agents := []*store.RegisteredAgent{{ID: "keyword", Card: keywordCard, Embedding: []float32{1, 0.75}}}
+	for _, id := range []string{"near-1", "near-2", "near-3", "near-4"} {
+		agents = append(agents, &store.RegisteredAgent{ID: id, Card: validAgentCard(), Embedding: []float32{1, 0.01}})
+	}
+	for _, a := range agents {
+		_ = s.CreateAgent(context.Background(), a)
+	}
+	svc := NewRegistryService(s,
+		WithEmbedder(fixedEmbedder{vector: []float32{1, 0}}),
+		WithFusion(Fusion{Method: FusionRRF, SemanticWeight: 1, LexicalWeight: 2}),
+		WithThresholds(Thresholds{MinScore: 0.5}),
+	)
+
+	result, err := svc.Discover(context.Background(), DiscoverInput{Query: "weather", Limit: 1})
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if scoredIDs(result.Agents) != "keyword" {
+		t.Fatalf("Discover() = %s near misses %s, want keyword", scoredIDs(result.Agents), scoredIDs(result.NearMisses))
+	}
+	if got := result.Agents[0].Similarity; got < 0.79 || got > 0.81 {
+		t.Errorf("Similarity = %v, want 0.8", got)
+	}
+}
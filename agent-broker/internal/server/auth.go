@@ -0,0 +1,318 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/logging"
+)
+
+// Registry access scopes.
+const (
+	// ScopeRegistryRead allows listing and reading registered agents.
+	ScopeRegistryRead = "registry:read"
+	// ScopeRegistryWrite allows registering, updating and deleting agents.
+	ScopeRegistryWrite = "registry:write"
+	// ScopeRegistryLease allows renewing agent leases by heartbeat.
+	ScopeRegistryLease = "registry:lease"
+)
+
+// apiKeyHeader is the header carrying static API keys.
+const apiKeyHeader = "X-API-Key"
+
+// Authentication errors.
+var (
+	// ErrNoCredentials is returned when a request carries no credentials an authenticator understands.
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials is returned when a request carries unknown, expired or malformed credentials.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Principal is an authenticated caller.
+type Principal struct {
+	// Subject identifies the caller, e.g. the API key name or the token subject.
+	Subject string
+	// Scopes are the scopes granted to the caller.
+	Scopes []string
+}
+
+// HasScope reports whether the principal was granted scope.
+// The write scope implies the read and lease scopes.
+func (p *Principal) HasScope(scope string) bool {
+	if slices.Contains(p.Scopes, scope) {
+		return true
+	}
+	return (scope == ScopeRegistryRead || scope == ScopeRegistryLease) && slices.Contains(p.Scopes, ScopeRegistryWrite)
+}
+
+// Authenticator identifies the caller of a request.
+type Authenticator interface {
+	// Authenticate returns the caller of r, ErrNoCredentials if r carries no
+	// credentials for this authenticator, or an error wrapping
+	// ErrInvalidCredentials if they are rejected.
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// ScopePolicy returns the scope required to serve r, or "" if r is public.
+type ScopePolicy func(r *http.Request) string
+
+// AdminScopePolicy protects the admin API: reads under /v1/admin/ require the
+// read scope, heartbeats the lease scope and all other methods the write
+// scope. Other routes are public.
+func AdminScopePolicy(r *http.Request) string {
+	if r.URL.Path != "/v1/admin" && !strings.HasPrefix(r.URL.Path, "/v1/admin/") {
+		return ""
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return ScopeRegistryRead
+	case http.MethodPost:
+		if isHeartbeatPath(r.URL.Path) {
+			return ScopeRegistryLease
+		}
+		return ScopeRegistryWrite
+	default:
+		return ScopeRegistryWrite
+	}
+}
+
+// isHeartbeatPath reports whether path is /v1/admin/agents/{id}/heartbeat.
+func isHeartbeatPath(path string) bool {
+	rest, ok := strings.CutPrefix(path, "/v1/admin/agents/")
+	if !ok {
+		return false
+	}
+	id, ok := strings.CutSuffix(rest, "/heartbeat")
+	return ok && id != "" && !strings.Contains(id, "/")
+}
+
+type principalKey struct{}
+
+// PrincipalFromContext returns the authenticated caller stored by AuthMiddleware, or nil.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// AuthMiddleware rejects requests to routes protected by policy unless one of
+// authenticators accepts their credentials and grants the required scope.
+// Authenticators are tried in order; the first that finds credentials decides.
+func AuthMiddleware(policy ScopePolicy, authenticators ...Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scope := policy(r)
+			if scope == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			principal, err := authenticate(r, authenticators)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="agent-broker"`)
+				writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", err.Error())
+				return
+			}
+			if !principal.HasScope(scope) {
+				writeError(w, http.StatusForbidden, "FORBIDDEN", fmt.Sprintf("scope %s required", scope))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, principal)))
+		})
+	}
+}
+
+// authenticate returns the principal of the first authenticator that finds credentials in r.
+func authenticate(r *http.Request, authenticators []Authenticator) (*Principal, error) {
+	for _, a := range authenticators {
+		principal, err := a.Authenticate(r)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return principal, nil
+	}
+	return nil, fmt.Errorf("authentication required")
+}
+
+// APIKey is a static API key and the scopes it grants.
+type APIKey struct {
+	// Name identifies the key holder and becomes the principal subject.
+	Name string
+	// Key is the secret sent in the X-API-Key header.
+	Key string
+	// Scopes are the scopes granted by the key.
+	Scopes []string
+}
+
+// APIKeyAuthenticator authenticates requests by a static key in the X-API-Key header.
+type APIKeyAuthenticator struct {
+	// keys holds the configured keys indexed by the SHA-256 digest of their secret.
+	keys map[[sha256.Size]byte]APIKey
+}
+
+// NewAPIKeyAuthenticator creates an authenticator accepting keys.
+func NewAPIKeyAuthenticator(keys ...APIKey) *APIKeyAuthenticator {
+	a := &APIKeyAuthenticator{keys: make(map[[sha256.Size]byte]APIKey, len(keys))}
+	for _, key := range keys {
+		if key.Key != "" {
+			a.keys[sha256.Sum256([]byte(key.Key))] = key
+		}
+	}
+	return a
+}
+
+// Authenticate implements Authenticator.
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	secret := r.Header.Get(apiKeyHeader)
+	if secret == "" {
+		return nil, ErrNoCredentials
+	}
+
+	// Digests have a fixed length, so comparing them does not leak the key length.
+	digest := sha256.Sum256([]byte(secret))
+	for stored, key := range a.keys {
+		if subtle.ConstantTimeCompare(digest[:], stored[:]) == 1 {
+			return &Principal{Subject: key.Name, Scopes: key.Scopes}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: unknown api key", ErrInvalidCredentials)
+}
+
+// JWTAuthenticator authenticates requests by an HMAC-signed JWT bearer token.
+// Scopes are read from the space-separated "scope" claim.
+type JWTAuthenticator struct {
+	// secret is the HMAC signing key.
+	secret []byte
+	// parser validates token signatures and claims.
+	parser *jwt.Parser
+}
+
+// JWTOptions configures the JWTAuthenticator.
+type JWTOptions struct {
+	// Issuer is the required "iss" claim, empty to accept any issuer.
+	Issuer string
+	// Audience is the required "aud" claim, empty to accept any audience.
+	Audience string
+	// Leeway is the allowed clock skew when checking "exp" and "nbf".
+	Leeway time.Duration
+}
+
+// DefaultJWTOptions returns JWTOptions with sensible defaults.
+func DefaultJWTOptions() JWTOptions {
+	return JWTOptions{
+		Leeway: 30 * time.Second,
+	}
+}
+
+// JWTOption is a functional option for configuring JWTAuthenticator.
+type JWTOption func(*JWTOptions)
+
+// WithJWTIssuer sets the required token issuer.
+func WithJWTIssuer(issuer string) JWTOption {
+	return func(o *JWTOptions) {
+		o.Issuer = issuer
+	}
+}
+
+// WithJWTAudience sets the required token audience.
+func WithJWTAudience(audience string) JWTOption {
+	return func(o *JWTOptions) {
+		o.Audience = audience
+	}
+}
+
+// WithJWTLeeway sets the allowed clock skew.
+func WithJWTLeeway(d time.Duration) JWTOption {
+	return func(o *JWTOptions) {
+		if d >= 0 {
+			o.Leeway = d
+		}
+	}
+}
+
+// NewJWTAuthenticator creates an authenticator for tokens signed with secret
+// using HS256, HS384 or HS512. Tokens must carry an expiry.
+func NewJWTAuthenticator(secret []byte, opts ...JWTOption) *JWTAuthenticator {
+	options := DefaultJWTOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"HS256", "HS384", "HS512"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(options.Leeway),
+	}
+	if options.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(options.Issuer))
+	}
+	if options.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(options.Audience))
+	}
+
+	return &JWTAuthenticator{
+		secret: secret,
+		parser: jwt.NewParser(parserOpts...),
+	}
+}
+
+// tokenClaims are the JWT claims read by JWTAuthenticator.
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	// Scope is the space-separated list of granted scopes.
+	Scope string `json:"scope"`
+}
+
+// Authenticate implements Authenticator.
+func (a *JWTAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return nil, ErrNoCredentials
+	}
+
+	var claims tokenClaims
+	_, err := a.parser.ParseWithClaims(strings.TrimSpace(token), &claims, func(*jwt.Token) (any, error) {
+		return a.secret, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+
+	return &Principal{
+		Subject: claims.Subject,
+		Scopes:  strings.Fields(claims.Scope),
+	}, nil
+}
+
+// errorResponse matches the error body returned by the API handlers.
+type errorResponse struct {
+	// Code is the error code.
+	Code string `json:"code"`
+	// Message is the human-readable error message.
+	Message string `json:"message"`
+	// RequestID is the ID of the failed request.
+	RequestID string `json:"request_id,omitempty"`
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(errorResponse{
+		Code:      code,
+		Message:   message,
+		RequestID: w.Header().Get(logging.RequestIDHeader),
+	})
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var testSecret = []byte("test-secret")
+
+func signToken(t *testing.T, method jwt.SigningMethod, key any, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	t.Parallel()
+
+	exp := time.Now().Add(time.Hour).Unix()
+	readToken := signToken(t, jwt.SigningMethodHS256, testSecret, jwt.MapClaims{"sub": "ci", "scope": "registry:read", "exp": exp})
+	writeToken := signToken(t, jwt.SigningMethodHS256, testSecret, jwt.MapClaims{"sub": "ci", "scope": "registry:write", "exp": exp})
+	expiredToken := signToken(t, jwt.SigningMethodHS256, testSecret, jwt.MapClaims{"scope": "registry:write", "exp": time.Now().Add(-time.Hour).Unix()})
+	wrongKeyToken := signToken(t, jwt.SigningMethodHS256, []byte("other"), jwt.MapClaims{"scope": "registry:write", "exp": exp})
+	noExpToken := signToken(t, jwt.SigningMethodHS256, testSecret, jwt.MapClaims{"scope": "registry:write"})
+	leaseToken := signToken(t, jwt.SigningMethodHS256, testSecret, jwt.MapClaims{"sub": "agent", "scope": "registry:lease", "exp": exp})
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p := PrincipalFromContext(r.Context()); p != nil {
+			w.Header().Set("X-Subject", p.Subject)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := AuthMiddleware(AdminScopePolicy,
+		NewAPIKeyAuthenticator(
+			APIKey{Name: "admin", Key: "admin-key", Scopes: []string{ScopeRegistryRead, ScopeRegistryWrite}},
+			APIKey{Name: "viewer", Key: "viewer-key", Scopes: []string{ScopeRegistryRead}},
+		),
+		NewJWTAuthenticator(testSecret),
+	)(next)
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		header      string
+		value       string
+		wantStatus  int
+		wantCode    string
+		wantSubject string
+	}{
+		{name: "a2a endpoint is public", method: http.MethodPost, path: "/", wantStatus: http.StatusOK},
+		{name: "health is public", method: http.MethodGet, path: "/health", wantStatus: http.StatusOK},
+		{name: "admin without credentials", method: http.MethodGet, path: "/v1/admin/agents", wantStatus: http.StatusUnauthorized, wantCode: "UNAUTHORIZED"},
+		{name: "unknown api key", method: http.MethodGet, path: "/v1/admin/agents", header: "X-API-Key", value: "nope", wantStatus: http.StatusUnauthorized, wantCode: "UNAUTHORIZED"},
+		{name: "read-only key can read", method: http.MethodGet, path: "/v1/admin/agents/a1", header: "X-API-Key", value: "viewer-key", wantStatus: http.StatusOK, wantSubject: "viewer"},
+		{name: "read-only key cannot write", method: http.MethodDelete, path: "/v1/admin/agents/a1", header: "X-API-Key", value: "viewer-key", wantStatus: http.StatusForbidden, wantCode: "FORBIDDEN"},
+		{name: "admin key can write", method: http.MethodPost, path: "/v1/admin/agents", header: "X-API-Key", value: "admin-key", wantStatus: http.StatusOK, wantSubject: "admin"},
+		{name: "read token cannot write", method: http.MethodPut, path: "/v1/admin/agents/a1", header: "Authorization", value: "Bearer " + readToken, wantStatus: http.StatusForbidden, wantCode: "FORBIDDEN"},
+		{name: "write token can read", method: http.MethodGet, path: "/v1/admin/agents", header: "Authorization", value: "Bearer " + writeToken, wantStatus: http.StatusOK, wantSubject: "ci"},
+		{name: "expired token", method: http.MethodGet, path: "/v1/admin/agents", header: "Authorization", value: "Bearer " + expiredToken, wantStatus: http.StatusUnauthorized, wantCode: "UNAUTHORIZED"},
+		{name: "token signed with other key", method: http.MethodGet, path: "/v1/admin/agents", header: "Authorization", value: "Bearer " + wrongKeyToken, wantStatus: http.StatusUnauthorized, wantCode: "UNAUTHORIZED"},
+		{name: "lease token can heartbeat", method: http.MethodPost, path: "/v1/admin/agents/a1/heartbeat", header: "Authorization", value: "Bearer " + leaseToken, wantStatus: http.StatusOK, wantSubject: "agent"},
+		{name: "lease token cannot write", method: http.MethodPost, path: "/v1/admin/agents", header: "Authorization", value: "Bearer " + leaseToken, wantStatus: http.StatusForbidden, wantCode: "FORBIDDEN"},
+		{name: "lease token cannot read", method: http.MethodGet, path: "/v1/admin/agents/a1", header: "Authorization", value: "Bearer " + leaseToken, wantStatus: http.StatusForbidden, wantCode: "FORBIDDEN"},
+		{name: "read-only key cannot heartbeat", method: http.MethodPost, path: "/v1/admin/agents/a1/heartbeat", header: "X-API-Key", value: "viewer-key", wantStatus: http.StatusForbidden, wantCode: "FORBIDDEN"},
+		{name: "write token can heartbeat", method: http.MethodPost, path: "/v1/admin/agents/a1/heartbeat", header: "Authorization", value: "Bearer " + writeToken, wantStatus: http.StatusOK, wantSubject: "ci"},
+		{name: "token without expiry", method: http.MethodGet, path: "/v1/admin/agents", header: "Authorization", value: "Bearer " + noExpToken, wantStatus: http.StatusUnauthorized, wantCode: "UNAUTHORIZED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("X-Subject"); got != tt.wantSubject {
+				t.Errorf("subject = %q, want %q", got, tt.wantSubject)
+			}
+			if tt.wantCode == "" {
+				return
+			}
+			var resp errorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode error response: %v", err)
+			}
+			if resp.Code != tt.wantCode || resp.Message == "" {
+				t.Errorf("error = %+v, want code %s with message", resp, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestJWTAuthenticator_Claims(t *testing.T) {
+	t.Parallel()
+
+	exp := time.Now().Add(time.Hour).Unix()
+	auth := NewJWTAuthenticator(testSecret, WithJWTIssuer("lunarr"), WithJWTAudience("agent-broker"))
+
+	tests := []struct {
+		name    string
+		claims  jwt.MapClaims
+		wantErr bool
+	}{
+		{name: "matching issuer and audience", claims: jwt.MapClaims{"iss": "lunarr", "aud": "agent-broker", "exp": exp}},
+		{name: "wrong issuer", claims: jwt.MapClaims{"iss": "other", "aud": "agent-broker", "exp": exp}, wantErr: true},
+		{name: "wrong audience", claims: jwt.MapClaims{"iss": "lunarr", "aud": "other", "exp": exp}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(http.MethodGet, "/v1/admin/agents", nil)
+			req.Header.Set("Authorization", "Bearer "+signToken(t, jwt.SigningMethodHS256, testSecret, tt.claims))
+
+			_, err := auth.Authenticate(req)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Authenticate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("unsigned token is rejected", func(t *testing.T) {
+		t.Parallel()
+		token := signToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, jwt.MapClaims{"iss": "lunarr", "aud": "agent-broker", "exp": exp})
+		req := httptest.NewRequest(http.MethodGet, "/v1/admin/agents", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		if _, err := auth.Authenticate(req); err == nil {
+			t.Error("Authenticate() should reject alg none")
+		}
+	})
+}
@@ -7,10 +7,23 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/google/uuid"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/logging"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/metrics"
 )
 
+// maxRequestIDLength bounds the length of request IDs accepted from callers.
+const maxRequestIDLength = 128
+
 // Server wraps http.Server with graceful shutdown and request logging.
 type Server struct {
 	// httpServer is the underlying HTTP server.
@@ -35,6 +48,13 @@ type Options struct {
 	IdleTimeout time.Duration
 	// ShutdownTimeout is the max duration for graceful shutdown.
 	ShutdownTimeout time.Duration
+	// Middleware wraps the handler inside request logging, outermost first.
+	Middleware []func(http.Handler) http.Handler
+	// Metrics records request counts and latency by route pattern, nil to disable.
+	Metrics *metrics.Metrics
+	// Tracing starts a span for each request, continuing the caller's W3C
+	// trace context. Spans go to the global tracer provider.
+	Tracing bool
 }
 
 // DefaultOptions returns Options with sensible defaults.
@@ -94,6 +114,27 @@ func WithShutdownTimeout(d time.Duration) Option {
 	}
 }
 
+// WithMiddleware appends middleware wrapping the handler.
+func WithMiddleware(mw ...func(http.Handler) http.Handler) Option {
+	return func(o *Options) {
+		o.Middleware = append(o.Middleware, mw...)
+	}
+}
+
+// WithMetrics records request counts and latency by route pattern.
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(o *Options) {
+		o.Metrics = m
+	}
+}
+
+// WithTracing enables or disables a span for each request.
+func WithTracing(enabled bool) Option {
+	return func(o *Options) {
+		o.Tracing = enabled
+	}
+}
+
 // New creates a Server with the given handler and options.
 func New(handler http.Handler, opts ...Option) *Server {
 	options := DefaultOptions()
@@ -101,10 +142,21 @@ func New(handler http.Handler, opts ...Option) *Server {
 		opt(&options)
 	}
 
+	handler = recordRoute(handler)
+	for i := len(options.Middleware) - 1; i >= 0; i-- {
+		handler = options.Middleware[i](handler)
+	}
+	if options.Metrics != nil {
+		handler = metricsMiddleware(options.Metrics)(handler)
+	}
+	if options.Tracing {
+		handler = tracingMiddleware(handler)
+	}
+
 	return &Server{
 		httpServer: &http.Server{
 			Addr:         fmt.Sprintf(":%d", options.Port),
-			Handler:      loggingMiddleware(options.Logger)(handler),
+			Handler:      requestIDMiddleware(loggingMiddleware(options.Logger)(handler)),
 			ReadTimeout:  options.ReadTimeout,
 			WriteTimeout: options.WriteTimeout,
 			IdleTimeout:  options.IdleTimeout,
@@ -114,6 +166,11 @@ func New(handler http.Handler, opts ...Option) *Server {
 	}
 }
 
+// Handler returns the server's handler wrapped in all its middleware.
+func (s *Server) Handler() http.Handler {
+	return s.httpServer.Handler
+}
+
 // Run starts the server and blocks until shutdown signal or context cancellation.
 func (s *Server) Run(ctx context.Context) error {
 	shutdown := make(chan os.Signal, 1)
@@ -154,10 +211,12 @@ func loggingMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
 
+			// Handlers record the A2A task they work on in the scope.
+			r = r.WithContext(logging.WithTaskScope(r.Context()))
 			wrapped := &responseWriter{ResponseWriter: w, status: http.StatusOK}
 			next.ServeHTTP(wrapped, r)
 
-			logger.Info("request",
+			logger.InfoContext(r.Context(), "request",
 				"method", r.Method,
 				"path", r.URL.Path,
 				"status", wrapped.status,
@@ -168,6 +227,97 @@ func loggingMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
 	}
 }
 
+// requestIDMiddleware stores the caller's X-Request-ID, or a generated ID if
+// it is missing or malformed, in the request context and echoes it in the
+// response.
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(logging.RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		w.Header().Set(logging.RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	})
+}
+
+// validRequestID reports whether id is a non-empty, bounded string of
+// printable ASCII characters, safe to log and echo.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// routeKey is the context key of the *string recordRoute stores the matched
+// route pattern in.
+type routeKey struct{}
+
+// withRoute returns r carrying a *string recordRoute stores the matched route
+// pattern in, reusing the one set by an outer middleware.
+func withRoute(r *http.Request) (*http.Request, *string) {
+	if route, ok := r.Context().Value(routeKey{}).(*string); ok {
+		return r, route
+	}
+	route := new(string)
+	return r.WithContext(context.WithValue(r.Context(), routeKey{}, route)), route
+}
+
+// recordRoute stores the pattern the wrapped mux matched where
+// metricsMiddleware can read it. Middleware may pass the mux a copy of the
+// request, so the pattern is not always set on the request metricsMiddleware sees.
+func recordRoute(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+		if route, ok := r.Context().Value(routeKey{}).(*string); ok {
+			*route = r.Pattern
+		}
+	})
+}
+
+func metricsMiddleware(m *metrics.Metrics) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			r, route := withRoute(r)
+			wrapped := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(wrapped, r)
+
+			m.ObserveHTTP(r.Method, *route, wrapped.status, time.Since(start))
+		})
+	}
+}
+
+// tracingMiddleware starts a server span for each request, except health
+// checks and metrics scrapes, and names it after the matched route pattern.
+func tracingMiddleware(next http.Handler) http.Handler {
+	return otelhttp.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r, route := withRoute(r)
+		next.ServeHTTP(w, r)
+
+		if *route == "" {
+			return
+		}
+		// Patterns may start with the method; http.route is the path alone.
+		path := *route
+		if _, p, ok := strings.Cut(path, " "); ok {
+			path = p
+		}
+		span := trace.SpanFromContext(r.Context())
+		span.SetName(r.Method + " " + path)
+		span.SetAttributes(attribute.String("http.route", path))
+	}), "http.server", otelhttp.WithFilter(func(r *http.Request) bool {
+		return r.URL.Path != "/health" && r.URL.Path != "/metrics"
+	}))
+}
+
 type responseWriter struct {
 	http.ResponseWriter
 	// status is the HTTP status code written to the response.
@@ -178,3 +328,14 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.status = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+// Flush sends buffered data to the client, so that server-sent events are
+// streamed through the logging and metrics middleware.
+func (rw *responseWriter) Flush() {
+	_ = http.NewResponseController(rw.ResponseWriter).Flush()
+}
+
+// Unwrap returns the wrapped ResponseWriter for http.ResponseController.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,285 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	"github.com/a2aproject/a2a-go/a2aclient"
+	"github.com/a2aproject/a2a-go/a2asrv"
+	"github.com/a2aproject/a2a-go/a2asrv/eventqueue"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/logging"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/metrics"
+)
+
+func TestServer_Metrics(t *testing.T) {
+	t.Parallel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	m := metrics.New(metrics.WithLogger(logger))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/admin/agents/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	// The middleware passes the mux a copy of the request, like AuthMiddleware.
+	copyRequest := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, &Principal{})))
+		})
+	}
+	srv := New(mux, WithLogger(logger), WithMetrics(m), WithMiddleware(copyRequest))
+
+	for _, path := range []string{"/v1/admin/agents/a1", "/v1/admin/agents/a2", "/unknown"} {
+		srv.httpServer.Handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`broker_http_requests_total{method="GET",route="GET /v1/admin/agents/{id}",status="204"} 2`,
+		`broker_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q", want)
+		}
+	}
+}
+
+// streamingExecutor reports a working update before completing the task.
+type streamingExecutor struct{}
+
+func (streamingExecutor) Execute(ctx context.Context, reqCtx *a2asrv.RequestContext, queue eventqueue.Queue) error {
+	if err := queue.Write(ctx, a2a.NewSubmittedTask(reqCtx, reqCtx.Message)); err != nil {
+		return err
+	}
+	working := a2a.NewStatusUpdateEvent(reqCtx, a2a.TaskStateWorking,
+		a2a.NewMessage(a2a.MessageRoleAgent, a2a.TextPart{Text: "working"}))
+	if err := queue.Write(ctx, working); err != nil {
+		return err
+	}
+	completed := a2a.NewStatusUpdateEvent(reqCtx, a2a.TaskStateCompleted, nil)
+	completed.Final = true
+	return queue.Write(ctx, completed)
+}
+
+func (streamingExecutor) Cancel(_ context.Context, _ *a2asrv.RequestContext, _ eventqueue.Queue) error {
+	return nil
+}
+
+func TestServer_Streaming(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "logging only"},
+		{name: "with metrics and tracing", opts: []Option{
+			WithMetrics(metrics.New(metrics.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))),
+			WithTracing(true),
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mux := http.NewServeMux()
+			mux.Handle("POST /", a2asrv.NewJSONRPCHandler(a2asrv.NewHandler(streamingExecutor{})))
+			opts := append([]Option{WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))}, tt.opts...)
+			httpServer := httptest.NewServer(New(mux, opts...).httpServer.Handler)
+			t.Cleanup(httpServer.Close)
+
+			client, err := a2aclient.NewFromCard(context.Background(),
+				&a2a.AgentCard{
+					Name:               "Streaming Agent",
+					URL:                httpServer.URL,
+					PreferredTransport: a2a.TransportProtocolJSONRPC,
+					Capabilities:       a2a.AgentCapabilities{Streaming: true},
+				},
+				a2aclient.WithJSONRPCTransport(httpServer.Client()),
+			)
+			if err != nil {
+				t.Fatalf("create client: %v", err)
+			}
+			t.Cleanup(func() { _ = client.Destroy() })
+
+			var states []a2a.TaskState
+			params := &a2a.MessageSendParams{Message: a2a.NewMessage(a2a.MessageRoleUser, a2a.TextPart{Text: "hello"})}
+			for event, err := range client.SendStreamingMessage(context.Background(), params) {
+				if err != nil {
+					t.Fatalf("SendStreamingMessage() error = %v", err)
+				}
+				switch v := event.(type) {
+				case *a2a.Task:
+					states = append(states, v.Status.State)
+				case *a2a.TaskStatusUpdateEvent:
+					states = append(states, v.Status.State)
+				}
+			}
+
+			want := []a2a.TaskState{a2a.TaskStateSubmitted, a2a.TaskStateWorking, a2a.TaskStateCompleted}
+			if !slices.Equal(states, want) {
+				t.Errorf("streamed states = %v, want %v", states, want)
+			}
+		})
+	}
+}
+
+// TestServer_WriteDeadline checks that handlers can clear the write deadline
+// through the server middleware, as the broker handler does.
+func TestServer_WriteDeadline(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /", func(w http.ResponseWriter, _ *http.Request) {
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		time.Sleep(300 * time.Millisecond)
+		_, _ = w.Write([]byte("done"))
+	})
+	srv := New(mux,
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		WithMetrics(metrics.New(metrics.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))),
+		WithTracing(true),
+	)
+	httpServer := httptest.NewUnstartedServer(srv.httpServer.Handler)
+	httpServer.Config.WriteTimeout = 100 * time.Millisecond
+	httpServer.Start()
+	t.Cleanup(httpServer.Close)
+
+	resp, err := httpServer.Client().Post(httpServer.URL, "text/plain", nil)
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "done" {
+		t.Errorf("response = %d %q, want 200 %q", resp.StatusCode, body, "done")
+	}
+}
+
+func TestServer_RequestID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		incoming string
+		// echoed reports whether the incoming ID is kept.
+		echoed bool
+	}{
+		{name: "accepts caller ID", incoming: "req-123", echoed: true},
+		{name: "generates missing ID", incoming: ""},
+		{name: "replaces ID with control characters", incoming: "req\nforged=1"},
+		{name: "replaces overlong ID", incoming: strings.Repeat("a", maxRequestIDLength+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var logs bytes.Buffer
+			logger := slog.New(logging.NewContextHandler(slog.NewJSONHandler(&logs, nil)))
+
+			var ctxID string
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /v1/admin/agents/{id}", func(w http.ResponseWriter, r *http.Request) {
+				ctxID = logging.RequestIDFromContext(r.Context())
+				writeError(w, http.StatusNotFound, "AGENT_NOT_FOUND", "agent not found")
+			})
+			srv := New(mux, WithLogger(logger))
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/admin/agents/a1", nil)
+			if tt.incoming != "" {
+				req.Header.Set(logging.RequestIDHeader, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			srv.httpServer.Handler.ServeHTTP(rec, req)
+
+			id := rec.Header().Get(logging.RequestIDHeader)
+			if tt.echoed {
+				if id != tt.incoming {
+					t.Errorf("response %s = %q, want %q", logging.RequestIDHeader, id, tt.incoming)
+				}
+			} else if _, err := uuid.Parse(id); err != nil {
+				t.Errorf("response %s = %q, want generated UUID", logging.RequestIDHeader, id)
+			}
+			if ctxID != id {
+				t.Errorf("context request ID = %q, want %q", ctxID, id)
+			}
+
+			var body errorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("decode error body: %v", err)
+			}
+			if body.RequestID != id {
+				t.Errorf("error body request_id = %q, want %q", body.RequestID, id)
+			}
+
+			var record map[string]any
+			if err := json.Unmarshal(logs.Bytes(), &record); err != nil {
+				t.Fatalf("log line %q is not JSON: %v", logs.String(), err)
+			}
+			if record["request_id"] != id {
+				t.Errorf("log request_id = %v, want %q", record["request_id"], id)
+			}
+		})
+	}
+}
+
+// TestServer_Tracing installs a global tracer provider and is therefore not
+// parallel.
+func TestServer_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/admin/agents/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	srv := New(mux, WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))), WithTracing(true))
+
+	for _, path := range []string{"/v1/admin/agents/a1", "/health"} {
+		srv.httpServer.Handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("recorded %d spans, want 1", len(spans))
+	}
+	if got, want := spans[0].Name(), "GET /v1/admin/agents/{id}"; got != want {
+		t.Errorf("span name = %q, want %q", got, want)
+	}
+	wantRoute := attribute.String("http.route", "/v1/admin/agents/{id}")
+	found := false
+	for _, attr := range spans[0].Attributes() {
+		if attr == wantRoute {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("span attributes = %v, want %v", spans[0].Attributes(), wantRoute)
+	}
+}
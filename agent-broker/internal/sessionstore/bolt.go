@@ -0,0 +1,399 @@
+package sessionstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/adk/session"
+)
+
+// ErrNotFound is returned when a requested session does not exist.
+var ErrNotFound = errors.New("session not found")
+
+// Bucket names.
+var (
+	sessionsBucket  = []byte("sessions")
+	appStateBucket  = []byte("app_state")
+	userStateBucket = []byte("user_state")
+)
+
+// keySeparator separates the parts of composite keys.
+const keySeparator = "\x00"
+
+// BoltService implements session.Service with sessions persisted in a bbolt file.
+type BoltService struct {
+	// db is the bbolt database.
+	db *bolt.DB
+	// maxEvents is the max number of events kept per session, 0 for no limit.
+	maxEvents int
+}
+
+// Options configures the BoltService.
+type Options struct {
+	// MaxEvents is the max number of events kept per session. Older events are
+	// dropped once the limit is reached; their state changes are kept. 0 means no limit.
+	MaxEvents int
+	// OpenTimeout is the max time to wait for the file lock held by another process.
+	OpenTimeout time.Duration
+}
+
+// DefaultOptions returns Options with sensible defaults.
+func DefaultOptions() Options {
+	return Options{
+		MaxEvents:   0,
+		OpenTimeout: 5 * time.Second,
+	}
+}
+
+// Option is a functional option for configuring BoltService.
+type Option func(*Options)
+
+// WithMaxEvents sets the max number of events kept per session.
+func WithMaxEvents(n int) Option {
+	return func(o *Options) {
+		if n >= 0 {
+			o.MaxEvents = n
+		}
+	}
+}
+
+// WithOpenTimeout sets the max time to wait for the database file lock.
+func WithOpenTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.OpenTimeout = d
+	}
+}
+
+// sessionRecord is the persisted form of a session.
+type sessionRecord struct {
+	// AppName is the application the session belongs to.
+	AppName string `json:"app_name"`
+	// UserID is the user the session belongs to.
+	UserID string `json:"user_id"`
+	// ID is the session identifier.
+	ID string `json:"id"`
+	// State is the session scoped state.
+	State map[string]any `json:"state"`
+	// Events is the retained session history, oldest first.
+	Events []*session.Event `json:"events"`
+	// UpdatedAt is the time of the last appended event.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewBoltService opens or creates the session database at path.
+func NewBoltService(path string, opts ...Option) (*BoltService, error) {
+	options := DefaultOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create session directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: options.OpenTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("open session database: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{sessionsBucket, appStateBucket, userStateBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return fmt.Errorf("create %s bucket: %w", name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &BoltService{
+		db:        db,
+		maxEvents: options.MaxEvents,
+	}, nil
+}
+
+// Close closes the session database.
+func (s *BoltService) Close() error {
+	return s.db.Close()
+}
+
+// Create creates a new session with the given initial state.
+// A session ID is generated if none is given.
+func (s *BoltService) Create(_ context.Context, req *session.CreateRequest) (*session.CreateResponse, error) {
+	if req.AppName == "" || req.UserID == "" {
+		return nil, fmt.Errorf("app_name and user_id are required")
+	}
+
+	id := req.SessionID
+	if id == "" {
+		id = uuid.NewString()
+	}
+
+	appDelta, userDelta, sessState := splitState(req.State)
+	record := &sessionRecord{
+		AppName:   req.AppName,
+		UserID:    req.UserID,
+		ID:        id,
+		State:     sessState,
+		UpdatedAt: time.Now(),
+	}
+
+	var sess *storedSession
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		key := sessionKey(req.AppName, req.UserID, id)
+		if tx.Bucket(sessionsBucket).Get(key) != nil {
+			return fmt.Errorf("session %s already exists", id)
+		}
+
+		appState, err := mergeScopedState(tx.Bucket(appStateBucket), []byte(req.AppName), appDelta)
+		if err != nil {
+			return err
+		}
+		userState, err := mergeScopedState(tx.Bucket(userStateBucket), userKey(req.AppName, req.UserID), userDelta)
+		if err != nil {
+			return err
+		}
+		if err := putRecord(tx, record); err != nil {
+			return err
+		}
+
+		sess = newStoredSession(record, appState, userState)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &session.CreateResponse{Session: sess}, nil
+}
+
+// Get returns a session with its events, optionally limited to the most
+// recent ones or to those after a given time.
+func (s *BoltService) Get(_ context.Context, req *session.GetRequest) (*session.GetResponse, error) {
+	var sess *storedSession
+	err := s.db.View(func(tx *bolt.Tx) error {
+		record, err := getRecord(tx, req.AppName, req.UserID, req.SessionID)
+		if err != nil {
+			return err
+		}
+		appState, userState, err := scopedState(tx, req.AppName, req.UserID)
+		if err != nil {
+			return err
+		}
+
+		record.Events = filterEvents(record.Events, req.NumRecentEvents, req.After)
+		sess = newStoredSession(record, appState, userState)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &session.GetResponse{Session: sess}, nil
+}
+
+// List returns the sessions of an app, limited to one user if UserID is set.
+// Listed sessions carry their state but no events.
+func (s *BoltService) List(_ context.Context, req *session.ListRequest) (*session.ListResponse, error) {
+	prefix := []byte(req.AppName + keySeparator)
+	if req.UserID != "" {
+		prefix = append(userKey(req.AppName, req.UserID), keySeparator...)
+	}
+
+	var sessions []session.Session
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(sessionsBucket).Cursor()
+		for k, v := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = cursor.Next() {
+			var record sessionRecord
+			if err := json.Unmarshal(v, &record); err != nil {
+				return fmt.Errorf("unmarshal session %q: %w", k, err)
+			}
+			appState, userState, err := scopedState(tx, record.AppName, record.UserID)
+			if err != nil {
+				return err
+			}
+
+			record.Events = nil
+			sessions = append(sessions, newStoredSession(&record, appState, userState))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &session.ListResponse{Sessions: sessions}, nil
+}
+
+// Delete removes a session. Deleting a missing session is not an error.
+func (s *BoltService) Delete(_ context.Context, req *session.DeleteRequest) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).Delete(sessionKey(req.AppName, req.UserID, req.SessionID))
+	})
+}
+
+// AppendEvent persists event to the session and applies its state delta.
+// Partial events are ignored and temp-scoped state keys are removed from the event.
+func (s *BoltService) AppendEvent(_ context.Context, sess session.Session, event *session.Event) error {
+	if sess == nil || event == nil {
+		return fmt.Errorf("session and event are required")
+	}
+	if event.Partial {
+		return nil
+	}
+
+	event.Actions.StateDelta = withoutTempKeys(event.Actions.StateDelta)
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		record, err := getRecord(tx, sess.AppName(), sess.UserID(), sess.ID())
+		if err != nil {
+			return err
+		}
+
+		appDelta, userDelta, sessDelta := splitState(event.Actions.StateDelta)
+		if _, err := mergeScopedState(tx.Bucket(appStateBucket), []byte(record.AppName), appDelta); err != nil {
+			return err
+		}
+		if _, err := mergeScopedState(tx.Bucket(userStateBucket), userKey(record.AppName, record.UserID), userDelta); err != nil {
+			return err
+		}
+
+		if record.State == nil {
+			record.State = map[string]any{}
+		}
+		maps.Copy(record.State, sessDelta)
+		record.Events = append(record.Events, event)
+		if s.maxEvents > 0 && len(record.Events) > s.maxEvents {
+			record.Events = record.Events[len(record.Events)-s.maxEvents:]
+		}
+		record.UpdatedAt = event.Timestamp
+
+		return putRecord(tx, record)
+	})
+	if err != nil {
+		return err
+	}
+
+	// The runner keeps using the session it fetched within an invocation.
+	if stored, ok := sess.(*storedSession); ok {
+		stored.appendEvent(event)
+	}
+	return nil
+}
+
+func newStoredSession(record *sessionRecord, appState, userState map[string]any) *storedSession {
+	return &storedSession{
+		appName:   record.AppName,
+		userID:    record.UserID,
+		id:        record.ID,
+		state:     mergeState(appState, userState, record.State),
+		events:    record.Events,
+		updatedAt: record.UpdatedAt,
+	}
+}
+
+func getRecord(tx *bolt.Tx, appName, userID, id string) (*sessionRecord, error) {
+	data := tx.Bucket(sessionsBucket).Get(sessionKey(appName, userID, id))
+	if data == nil {
+		return nil, fmt.Errorf("session %s: %w", id, ErrNotFound)
+	}
+
+	var record sessionRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("unmarshal session %s: %w", id, err)
+	}
+	return &record, nil
+}
+
+func putRecord(tx *bolt.Tx, record *sessionRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal session %s: %w", record.ID, err)
+	}
+	return tx.Bucket(sessionsBucket).Put(sessionKey(record.AppName, record.UserID, record.ID), data)
+}
+
+// scopedState loads the app and user state visible to a session.
+func scopedState(tx *bolt.Tx, appName, userID string) (app, user map[string]any, err error) {
+	app, err = loadState(tx.Bucket(appStateBucket), []byte(appName))
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err = loadState(tx.Bucket(userStateBucket), userKey(appName, userID))
+	if err != nil {
+		return nil, nil, err
+	}
+	return app, user, nil
+}
+
+// mergeScopedState applies delta to the state stored under key and returns the result.
+func mergeScopedState(bucket *bolt.Bucket, key []byte, delta map[string]any) (map[string]any, error) {
+	state, err := loadState(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(delta) == 0 {
+		return state, nil
+	}
+
+	maps.Copy(state, delta)
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("marshal state: %w", err)
+	}
+	if err := bucket.Put(key, data); err != nil {
+		return nil, fmt.Errorf("put state: %w", err)
+	}
+	return state, nil
+}
+
+func loadState(bucket *bolt.Bucket, key []byte) (map[string]any, error) {
+	state := map[string]any{}
+	if data := bucket.Get(key); data != nil {
+		if err := json.Unmarshal(data, &state); err != nil {
+			return nil, fmt.Errorf("unmarshal state: %w", err)
+		}
+	}
+	return state, nil
+}
+
+// filterEvents keeps the events at or after after and then the numRecent most recent ones.
+func filterEvents(events []*session.Event, numRecent int, after time.Time) []*session.Event {
+	if !after.IsZero() {
+		start := len(events)
+		for i, event := range events {
+			if !event.Timestamp.Before(after) {
+				start = i
+				break
+			}
+		}
+		events = events[start:]
+	}
+	if numRecent > 0 && len(events) > numRecent {
+		events = events[len(events)-numRecent:]
+	}
+	return events
+}
+
+func sessionKey(appName, userID, id string) []byte {
+	return []byte(appName + keySeparator + userID + keySeparator + id)
+}
+
+func userKey(appName, userID string) []byte {
+	return []byte(appName + keySeparator + userID)
+}
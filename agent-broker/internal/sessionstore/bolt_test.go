@@ -0,0 +1,250 @@
+package sessionstore
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/genai"
+)
+
+func openService(t *testing.T, path string, opts ...Option) *BoltService {
+	t.Helper()
+	svc, err := NewBoltService(path, opts...)
+	if err != nil {
+		t.Fatalf("NewBoltService() error = %v", err)
+	}
+	t.Cleanup(func() { _ = svc.Close() })
+	return svc
+}
+
+func textEvent(author, text string, delta map[string]any) *session.Event {
+	return &session.Event{
+		LLMResponse: model.LLMResponse{
+			Content: genai.NewContentFromText(text, genai.Role(author)),
+		},
+		ID:      text,
+		Author:  author,
+		Actions: session.EventActions{StateDelta: delta},
+	}
+}
+
+func createSession(t *testing.T, svc *BoltService, userID, id string) session.Session {
+	t.Helper()
+	resp, err := svc.Create(context.Background(), &session.CreateRequest{
+		AppName:   "broker",
+		UserID:    userID,
+		SessionID: id,
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	return resp.Session
+}
+
+func TestBoltService_PersistsAcrossRestart(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "sessions.db")
+
+	svc, err := NewBoltService(path)
+	if err != nil {
+		t.Fatalf("NewBoltService() error = %v", err)
+	}
+	sess := createSession(t, svc, "user-1", "s1")
+	events := []*session.Event{
+		textEvent("user", "hello", map[string]any{"topic": "weather", "temp:scratch": "x"}),
+		textEvent("model", "hi there", map[string]any{"user:name": "Ada", "app:version": "1"}),
+	}
+	for _, event := range events {
+		if err := svc.AppendEvent(ctx, sess, event); err != nil {
+			t.Fatalf("AppendEvent() error = %v", err)
+		}
+	}
+	if sess.Events().Len() != 2 {
+		t.Errorf("live session has %d events, want 2", sess.Events().Len())
+	}
+	if err := svc.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	reopened := openService(t, path)
+	resp, err := reopened.Get(ctx, &session.GetRequest{AppName: "broker", UserID: "user-1", SessionID: "s1"})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	got := resp.Session
+	if got.Events().Len() != 2 {
+		t.Fatalf("Events().Len() = %d, want 2", got.Events().Len())
+	}
+	if text := got.Events().At(1).Content.Parts[0].Text; text != "hi there" {
+		t.Errorf("second event text = %q, want %q", text, "hi there")
+	}
+	for key, want := range map[string]any{"topic": "weather", "user:name": "Ada", "app:version": "1"} {
+		value, err := got.State().Get(key)
+		if err != nil || value != want {
+			t.Errorf("State().Get(%q) = %v, %v, want %v", key, value, err, want)
+		}
+	}
+	if _, err := got.State().Get("temp:scratch"); !errors.Is(err, session.ErrStateKeyNotExist) {
+		t.Errorf("temp state should not be persisted, got error %v", err)
+	}
+}
+
+func TestBoltService_ScopedState(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	svc := openService(t, filepath.Join(t.TempDir(), "sessions.db"))
+
+	first := createSession(t, svc, "user-1", "s1")
+	_ = svc.AppendEvent(ctx, first, textEvent("model", "a", map[string]any{"user:name": "Ada", "app:version": "2"}))
+
+	second := createSession(t, svc, "user-1", "s2")
+	if value, _ := second.State().Get("user:name"); value != "Ada" {
+		t.Errorf("user state in new session = %v, want Ada", value)
+	}
+
+	other := createSession(t, svc, "user-2", "s3")
+	if _, err := other.State().Get("user:name"); err == nil {
+		t.Error("user state should not leak to other users")
+	}
+	if value, _ := other.State().Get("app:version"); value != "2" {
+		t.Errorf("app state in other user's session = %v, want 2", value)
+	}
+}
+
+func TestBoltService_Get(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	svc := openService(t, filepath.Join(t.TempDir(), "sessions.db"))
+	sess := createSession(t, svc, "user-1", "s1")
+
+	start := time.Now()
+	for i, text := range []string{"one", "two", "three"} {
+		event := textEvent("user", text, nil)
+		event.Timestamp = start.Add(time.Duration(i) * time.Second)
+		_ = svc.AppendEvent(ctx, sess, event)
+	}
+
+	tests := []struct {
+		name      string
+		req       *session.GetRequest
+		wantFirst string
+		wantLen   int
+	}{
+		{
+			name:      "all events",
+			req:       &session.GetRequest{AppName: "broker", UserID: "user-1", SessionID: "s1"},
+			wantFirst: "one",
+			wantLen:   3,
+		},
+		{
+			name:      "recent events",
+			req:       &session.GetRequest{AppName: "broker", UserID: "user-1", SessionID: "s1", NumRecentEvents: 2},
+			wantFirst: "two",
+			wantLen:   2,
+		},
+		{
+			name:      "events after time",
+			req:       &session.GetRequest{AppName: "broker", UserID: "user-1", SessionID: "s1", After: start.Add(2 * time.Second)},
+			wantFirst: "three",
+			wantLen:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			resp, err := svc.Get(ctx, tt.req)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			events := resp.Session.Events()
+			if events.Len() != tt.wantLen {
+				t.Fatalf("Events().Len() = %d, want %d", events.Len(), tt.wantLen)
+			}
+			if events.At(0).ID != tt.wantFirst {
+				t.Errorf("first event = %q, want %q", events.At(0).ID, tt.wantFirst)
+			}
+		})
+	}
+
+	t.Run("missing session returns ErrNotFound", func(t *testing.T) {
+		t.Parallel()
+		_, err := svc.Get(ctx, &session.GetRequest{AppName: "broker", UserID: "user-1", SessionID: "missing"})
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get() error = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestBoltService_ListAndDelete(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	svc := openService(t, filepath.Join(t.TempDir(), "sessions.db"))
+
+	createSession(t, svc, "user-1", "s1")
+	createSession(t, svc, "user-1", "s2")
+	createSession(t, svc, "user-2", "s3")
+
+	resp, err := svc.List(ctx, &session.ListRequest{AppName: "broker", UserID: "user-1"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(resp.Sessions) != 2 {
+		t.Errorf("List(user-1) returned %d sessions, want 2", len(resp.Sessions))
+	}
+
+	resp, _ = svc.List(ctx, &session.ListRequest{AppName: "broker"})
+	if len(resp.Sessions) != 3 {
+		t.Errorf("List(app) returned %d sessions, want 3", len(resp.Sessions))
+	}
+
+	if err := svc.Delete(ctx, &session.DeleteRequest{AppName: "broker", UserID: "user-1", SessionID: "s1"}); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	resp, _ = svc.List(ctx, &session.ListRequest{AppName: "broker", UserID: "user-1"})
+	if len(resp.Sessions) != 1 || resp.Sessions[0].ID() != "s2" {
+		t.Errorf("List(user-1) after delete = %d sessions, want [s2]", len(resp.Sessions))
+	}
+}
+
+func TestBoltService_MaxEvents(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	svc := openService(t, filepath.Join(t.TempDir(), "sessions.db"), WithMaxEvents(2))
+	sess := createSession(t, svc, "user-1", "s1")
+
+	_ = svc.AppendEvent(ctx, sess, textEvent("user", "one", map[string]any{"first": true}))
+	_ = svc.AppendEvent(ctx, sess, textEvent("user", "two", nil))
+	_ = svc.AppendEvent(ctx, sess, textEvent("user", "three", nil))
+	_ = svc.AppendEvent(ctx, sess, &session.Event{LLMResponse: model.LLMResponse{Partial: true}})
+
+	resp, err := svc.Get(ctx, &session.GetRequest{AppName: "broker", UserID: "user-1", SessionID: "s1"})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	events := resp.Session.Events()
+	if events.Len() != 2 || events.At(0).ID != "two" {
+		t.Errorf("retained %d events starting at %q, want 2 starting at two", events.Len(), events.At(0).ID)
+	}
+	if value, _ := resp.Session.State().Get("first"); value != true {
+		t.Errorf("state of dropped event = %v, want true", value)
+	}
+}
+
+func TestBoltService_Create_Duplicate(t *testing.T) {
+	t.Parallel()
+	svc := openService(t, filepath.Join(t.TempDir(), "sessions.db"))
+	createSession(t, svc, "user-1", "s1")
+
+	_, err := svc.Create(context.Background(), &session.CreateRequest{AppName: "broker", UserID: "user-1", SessionID: "s1"})
+	if err == nil {
+		t.Error("Create() of existing session should fail")
+	}
+}
@@ -0,0 +1,186 @@
+package sessionstore
+
+import (
+	"iter"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/adk/session"
+)
+
+// State key prefixes with special scoping, matching the ADK conventions.
+const (
+	// appPrefix marks state shared by all sessions of an app.
+	appPrefix = "app:"
+	// userPrefix marks state shared by all sessions of a user.
+	userPrefix = "user:"
+	// tempPrefix marks state that is never persisted.
+	tempPrefix = "temp:"
+)
+
+// storedSession implements session.Session. The runner appends events to the
+// session it fetched, so the value is mutable and guarded by mu.
+type storedSession struct {
+	// mu guards state, events and updatedAt.
+	mu sync.RWMutex
+	// appName is the application the session belongs to.
+	appName string
+	// userID is the user the session belongs to.
+	userID string
+	// id is the session identifier.
+	id string
+	// state is the merged app, user and session state.
+	state map[string]any
+	// events is the session history, oldest first.
+	events []*session.Event
+	// updatedAt is the time of the last appended event.
+	updatedAt time.Time
+}
+
+// ID returns the session identifier.
+func (s *storedSession) ID() string { return s.id }
+
+// AppName returns the application the session belongs to.
+func (s *storedSession) AppName() string { return s.appName }
+
+// UserID returns the user the session belongs to.
+func (s *storedSession) UserID() string { return s.userID }
+
+// State returns the merged app, user and session state.
+func (s *storedSession) State() session.State {
+	return &sessionState{session: s}
+}
+
+// Events returns a snapshot of the session history.
+func (s *storedSession) Events() session.Events {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return sessionEvents(append([]*session.Event(nil), s.events...))
+}
+
+// LastUpdateTime returns the time of the last appended event.
+func (s *storedSession) LastUpdateTime() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.updatedAt
+}
+
+// appendEvent adds event to the history and applies its state delta.
+func (s *storedSession) appendEvent(event *session.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, event)
+	s.updatedAt = event.Timestamp
+	maps.Copy(s.state, event.Actions.StateDelta)
+}
+
+// sessionState implements session.State on top of a storedSession.
+type sessionState struct {
+	// session is the session owning the state.
+	session *storedSession
+}
+
+// Get returns the value of key or session.ErrStateKeyNotExist.
+func (st *sessionState) Get(key string) (any, error) {
+	st.session.mu.RLock()
+	defer st.session.mu.RUnlock()
+
+	value, ok := st.session.state[key]
+	if !ok {
+		return nil, session.ErrStateKeyNotExist
+	}
+	return value, nil
+}
+
+// Set sets the value of key.
+func (st *sessionState) Set(key string, value any) error {
+	st.session.mu.Lock()
+	defer st.session.mu.Unlock()
+
+	st.session.state[key] = value
+	return nil
+}
+
+// All iterates over a snapshot of the state.
+func (st *sessionState) All() iter.Seq2[string, any] {
+	st.session.mu.RLock()
+	snapshot := maps.Clone(st.session.state)
+	st.session.mu.RUnlock()
+
+	return maps.All(snapshot)
+}
+
+// sessionEvents implements session.Events over a snapshot of events.
+type sessionEvents []*session.Event
+
+// All iterates over the events, oldest first.
+func (e sessionEvents) All() iter.Seq[*session.Event] {
+	return func(yield func(*session.Event) bool) {
+		for _, event := range e {
+			if !yield(event) {
+				return
+			}
+		}
+	}
+}
+
+// Len returns the number of events.
+func (e sessionEvents) Len() int {
+	return len(e)
+}
+
+// At returns the i-th event, or nil if out of range.
+func (e sessionEvents) At(i int) *session.Event {
+	if i < 0 || i >= len(e) {
+		return nil
+	}
+	return e[i]
+}
+
+// splitState splits a state delta into app, user and session scoped deltas.
+// App and user keys are returned without their prefix; temp keys are dropped.
+func splitState(delta map[string]any) (app, user, sess map[string]any) {
+	app, user, sess = map[string]any{}, map[string]any{}, map[string]any{}
+	for key, value := range delta {
+		switch {
+		case strings.HasPrefix(key, appPrefix):
+			app[strings.TrimPrefix(key, appPrefix)] = value
+		case strings.HasPrefix(key, userPrefix):
+			user[strings.TrimPrefix(key, userPrefix)] = value
+		case strings.HasPrefix(key, tempPrefix):
+		default:
+			sess[key] = value
+		}
+	}
+	return app, user, sess
+}
+
+// mergeState builds the state visible to a session from its scoped parts.
+func mergeState(app, user, sess map[string]any) map[string]any {
+	merged := make(map[string]any, len(app)+len(user)+len(sess))
+	maps.Copy(merged, sess)
+	for key, value := range app {
+		merged[appPrefix+key] = value
+	}
+	for key, value := range user {
+		merged[userPrefix+key] = value
+	}
+	return merged
+}
+
+// withoutTempKeys returns delta without temp-scoped keys.
+func withoutTempKeys(delta map[string]any) map[string]any {
+	if delta == nil {
+		return nil
+	}
+	trimmed := make(map[string]any, len(delta))
+	for key, value := range delta {
+		if !strings.HasPrefix(key, tempPrefix) {
+			trimmed[key] = value
+		}
+	}
+	return trimmed
+}
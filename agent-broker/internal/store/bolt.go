@@ -0,0 +1,463 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+	bolt "go.etcd.io/bbolt"
+)
+
+// agentsBucket holds one JSON record per agent, keyed by agent ID.
+var agentsBucket = []byte("agents")
+
+// metaBucket holds store-wide settings.
+var metaBucket = []byte("meta")
+
+// embeddingKey is the metaBucket key of the embeddingMeta record.
+var embeddingKey = []byte("embedding")
+
+// BoltOptions configures the BoltStore.
+type BoltOptions struct {
+	// OpenTimeout is the max time to wait for the file lock held by another process.
+	OpenTimeout time.Duration
+	// VectorDimension is the size of embedding vectors, 0 to accept any size.
+	VectorDimension int
+	// EmbeddingModel is the name of the model producing the vectors, recorded
+	// in the database and checked on open. Empty skips the model check.
+	EmbeddingModel string
+}
+
+// DefaultBoltOptions returns BoltOptions with sensible defaults.
+func DefaultBoltOptions() BoltOptions {
+	return BoltOptions{
+		OpenTimeout:     5 * time.Second,
+		VectorDimension: 0,
+	}
+}
+
+// BoltOption is a functional option for configuring BoltStore.
+type BoltOption func(*BoltOptions)
+
+// WithBoltOpenTimeout sets the max time to wait for the database file lock.
+func WithBoltOpenTimeout(d time.Duration) BoltOption {
+	return func(o *BoltOptions) {
+		o.OpenTimeout = d
+	}
+}
+
+// WithBoltVectorDimension sets the required embedding vector dimension.
+func WithBoltVectorDimension(dim int) BoltOption {
+	return func(o *BoltOptions) {
+		o.VectorDimension = dim
+	}
+}
+
+// WithBoltEmbeddingModel sets the embedding model name recorded in the database.
+func WithBoltEmbeddingModel(model string) BoltOption {
+	return func(o *BoltOptions) {
+		o.EmbeddingModel = model
+	}
+}
+
+// BoltStore implements Store with agents persisted in a single bbolt file.
+// All agents are also kept in memory, so reads and cosine search never touch
+// the file; every write goes to the file first and then to memory.
+type BoltStore struct {
+	// db is the bbolt database.
+	db *bolt.DB
+	// mem serves reads and searches.
+	mem *MemoryStore
+	// mu serializes writes so the file and memory apply them in the same order.
+	mu sync.Mutex
+	// dim is the required embedding dimension, 0 for any.
+	dim int
+}
+
+// agentRecord is the persisted form of a RegisteredAgent.
+type agentRecord struct {
+	// ID is the unique identifier for the agent in the registry.
+	ID string `json:"id"`
+	// Card is the A2A-compliant agent card.
+	Card a2a.AgentCard `json:"card"`
+	// Tags are classification tags for filtering.
+	Tags []string `json:"tags,omitempty"`
+	// Embedding is the vector representation for semantic search.
+	Embedding []float32 `json:"embedding,omitempty"`
+	// SkillEmbeddings holds a separate vector for each skill, keyed by skill ID.
+	SkillEmbeddings map[string][]float32 `json:"skill_embeddings,omitempty"`
+	// SourceURL is the base URL the card was fetched from.
+	SourceURL string `json:"source_url,omitempty"`
+	// Health is the liveness state recorded by the health monitor.
+	Health healthRecord `json:"health"`
+	// LeaseTTL is the lease duration renewed by each heartbeat.
+	LeaseTTL time.Duration `json:"lease_ttl,omitempty"`
+	// ExpiresAt is when the lease expires.
+	ExpiresAt time.Time `json:"expires_at"`
+	// CreatedAt is when the agent was registered.
+	CreatedAt time.Time `json:"created_at"`
+	// UpdatedAt is when the agent was last updated.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// embeddingMeta records the embedding model and dimension the stored agents
+// were indexed with.
+type embeddingMeta struct {
+	// Model is the embedding model name, empty if never configured.
+	Model string `json:"model,omitempty"`
+	// Dimension is the embedding vector size, 0 if never configured.
+	Dimension int `json:"dimension,omitempty"`
+}
+
+// healthRecord is the persisted form of AgentHealth.
+type healthRecord struct {
+	// Status is the current liveness state.
+	Status HealthStatus `json:"status,omitempty"`
+	// LastSeen is when the agent last answered a probe.
+	LastSeen time.Time `json:"last_seen"`
+	// LastChecked is when the agent was last probed.
+	LastChecked time.Time `json:"last_checked"`
+	// ConsecutiveFailures is the number of failed probes since the last success.
+	ConsecutiveFailures int `json:"consecutive_failures,omitempty"`
+	// Latency is the duration of the last successful probe.
+	Latency time.Duration `json:"latency,omitempty"`
+	// LastError is the error of the last failed probe.
+	LastError string `json:"last_error,omitempty"`
+}
+
+// NewBoltStore opens or creates the agent database at path and loads all
+// agents into memory.
+func NewBoltStore(path string, opts ...BoltOption) (*BoltStore, error) {
+	options := DefaultBoltOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create store directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: options.OpenTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("open store database: %w", err)
+	}
+
+	s := &BoltStore{
+		db:  db,
+		mem: NewMemoryStore(),
+		dim: options.VectorDimension,
+	}
+	if err := s.load(options.EmbeddingModel); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// load creates the buckets if needed, checks the embedding model and
+// dimension, and reads every agent into memory.
+func (s *BoltStore) load(model string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := s.checkEmbedding(tx, model); err != nil {
+			return err
+		}
+
+		bucket, err := tx.CreateBucketIfNotExists(agentsBucket)
+		if err != nil {
+			return fmt.Errorf("create agents bucket: %w", err)
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			agent, err := decodeAgent(v)
+			if err != nil {
+				return fmt.Errorf("decode agent %s: %w", k, err)
+			}
+			if err := s.checkDimension(agent); err != nil {
+				return fmt.Errorf("%w: agent %s: %w", ErrEmbeddingMismatch, k, err)
+			}
+			s.mem.put(agent)
+			return nil
+		})
+	})
+}
+
+// checkEmbedding refuses a database recorded with a different embedding model
+// or dimension than configured, and records the configured ones.
+func (s *BoltStore) checkEmbedding(tx *bolt.Tx, model string) error {
+	bucket, err := tx.CreateBucketIfNotExists(metaBucket)
+	if err != nil {
+		return fmt.Errorf("create meta bucket: %w", err)
+	}
+
+	var meta embeddingMeta
+	if data := bucket.Get(embeddingKey); data != nil {
+		if err := json.Unmarshal(data, &meta); err != nil {
+			return fmt.Errorf("decode embedding metadata: %w", err)
+		}
+	}
+	if meta.Dimension != 0 && s.dim != 0 && meta.Dimension != s.dim {
+		return fmt.Errorf("%w: store was built with dimension %d, embedding dimension is %d",
+			ErrEmbeddingMismatch, meta.Dimension, s.dim)
+	}
+	if meta.Model != "" && model != "" && meta.Model != model {
+		return fmt.Errorf("%w: store was built with model %q, embedding model is %q",
+			ErrEmbeddingMismatch, meta.Model, model)
+	}
+
+	if s.dim != 0 {
+		meta.Dimension = s.dim
+	}
+	if model != "" {
+		meta.Model = model
+	}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("encode embedding metadata: %w", err)
+	}
+	if err := bucket.Put(embeddingKey, data); err != nil {
+		return fmt.Errorf("write embedding metadata: %w", err)
+	}
+	return nil
+}
+
+// Ping checks that the database is open.
+func (s *BoltStore) Ping(_ context.Context) error {
+	return s.db.View(func(*bolt.Tx) error { return nil })
+}
+
+// Close closes the database.
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// CreateAgent stores a new agent.
+func (s *BoltStore) CreateAgent(ctx context.Context, agent *RegisteredAgent) error {
+	if err := s.checkDimension(agent); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.mem.GetAgent(ctx, agent.ID); err == nil {
+		return ErrAlreadyExists
+	}
+	if err := s.put(agent); err != nil {
+		return err
+	}
+	return s.mem.CreateAgent(ctx, agent)
+}
+
+// GetAgent retrieves an agent by ID.
+func (s *BoltStore) GetAgent(ctx context.Context, id string) (*RegisteredAgent, error) {
+	return s.mem.GetAgent(ctx, id)
+}
+
+// ListAgents returns agents matching the filter.
+func (s *BoltStore) ListAgents(ctx context.Context, filter AgentFilter) (*AgentListResult, error) {
+	return s.mem.ListAgents(ctx, filter)
+}
+
+// SearchAgents finds agents by brute-force cosine similarity with optional filtering.
+func (s *BoltStore) SearchAgents(ctx context.Context, query []float32, limit int, filter AgentFilter) (*SearchResult, error) {
+	return s.mem.SearchAgents(ctx, query, limit, filter)
+}
+
+// LexicalSearchAgents finds agents by BM25 keyword relevance with optional filtering.
+func (s *BoltStore) LexicalSearchAgents(ctx context.Context, query string, limit int, filter AgentFilter) (*SearchResult, error) {
+	return s.mem.LexicalSearchAgents(ctx, query, limit, filter)
+}
+
+// UpdateAgent updates an existing agent.
+func (s *BoltStore) UpdateAgent(ctx context.Context, agent *RegisteredAgent) error {
+	if err := s.checkDimension(agent); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.mem.GetAgent(ctx, agent.ID)
+	if err != nil {
+		return err
+	}
+	updated := agent.withStateOf(existing)
+	if err := s.put(updated); err != nil {
+		return err
+	}
+	s.mem.replace(updated)
+	return nil
+}
+
+// DeleteAgent removes an agent.
+func (s *BoltStore) DeleteAgent(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.mem.GetAgent(ctx, id); err != nil {
+		return err
+	}
+	if err := s.delete(id); err != nil {
+		return err
+	}
+	return s.mem.DeleteAgent(ctx, id)
+}
+
+// SetAgentHealth replaces the health state of an agent.
+func (s *BoltStore) SetAgentHealth(ctx context.Context, id string, health AgentHealth) error {
+	return s.modify(ctx, id, func(agent *RegisteredAgent) {
+		agent.Health = health
+	})
+}
+
+// RenewAgentLease sets the lease expiry of an agent.
+func (s *BoltStore) RenewAgentLease(ctx context.Context, id string, expiresAt time.Time) error {
+	return s.modify(ctx, id, func(agent *RegisteredAgent) {
+		agent.ExpiresAt = expiresAt
+	})
+}
+
+// DeleteExpiredAgents removes agents whose lease expired at or before now.
+func (s *BoltStore) DeleteExpiredAgents(ctx context.Context, now time.Time) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.mem.mu.RLock()
+	var ids []string
+	for id, agent := range s.mem.agents {
+		if agent.Expired(now) {
+			ids = append(ids, id)
+		}
+	}
+	s.mem.mu.RUnlock()
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(agentsBucket)
+		for _, id := range ids {
+			if err := bucket.Delete([]byte(id)); err != nil {
+				return fmt.Errorf("delete agent %s: %w", id, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		_ = s.mem.DeleteAgent(ctx, id)
+	}
+	return ids, nil
+}
+
+// modify applies change to a copy of the agent and stores the result.
+// Readers holding the previous pointer are not affected.
+func (s *BoltStore) modify(ctx context.Context, id string, change func(*RegisteredAgent)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, err := s.mem.GetAgent(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	updated := *agent
+	change(&updated)
+	if err := s.put(&updated); err != nil {
+		return err
+	}
+	s.mem.replace(&updated)
+	return nil
+}
+
+// put writes agent to the database.
+func (s *BoltStore) put(agent *RegisteredAgent) error {
+	data, err := encodeAgent(agent)
+	if err != nil {
+		return fmt.Errorf("encode agent %s: %w", agent.ID, err)
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(agentsBucket).Put([]byte(agent.ID), data)
+	})
+}
+
+// delete removes an agent from the database.
+func (s *BoltStore) delete(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(agentsBucket).Delete([]byte(id))
+	})
+}
+
+// checkDimension rejects embeddings that do not match the configured dimension.
+func (s *BoltStore) checkDimension(agent *RegisteredAgent) error {
+	if s.dim > 0 && len(agent.Embedding) > 0 && len(agent.Embedding) != s.dim {
+		return fmt.Errorf("embedding dimension %d does not match store dimension %d", len(agent.Embedding), s.dim)
+	}
+	for id, vector := range agent.SkillEmbeddings {
+		if s.dim > 0 && len(vector) != s.dim {
+			return fmt.Errorf("skill %s embedding dimension %d does not match store dimension %d", id, len(vector), s.dim)
+		}
+	}
+	return nil
+}
+
+func encodeAgent(agent *RegisteredAgent) ([]byte, error) {
+	return json.Marshal(agentRecord{
+		ID:              agent.ID,
+		Card:            agent.Card,
+		Tags:            agent.Tags,
+		Embedding:       agent.Embedding,
+		SkillEmbeddings: agent.SkillEmbeddings,
+		SourceURL:       agent.SourceURL,
+		Health: healthRecord{
+			Status:              agent.Health.Status,
+			LastSeen:            agent.Health.LastSeen,
+			LastChecked:         agent.Health.LastChecked,
+			ConsecutiveFailures: agent.Health.ConsecutiveFailures,
+			Latency:             agent.Health.Latency,
+			LastError:           agent.Health.LastError,
+		},
+		LeaseTTL:  agent.LeaseTTL,
+		ExpiresAt: agent.ExpiresAt,
+		CreatedAt: agent.CreatedAt,
+		UpdatedAt: agent.UpdatedAt,
+	})
+}
+
+func decodeAgent(data []byte) (*RegisteredAgent, error) {
+	var record agentRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+
+	return &RegisteredAgent{
+		ID:              record.ID,
+		Card:            record.Card,
+		Tags:            record.Tags,
+		Embedding:       record.Embedding,
+		SkillEmbeddings: record.SkillEmbeddings,
+		SourceURL:       record.SourceURL,
+		Health: AgentHealth{
+			Status:              record.Health.Status,
+			LastSeen:            record.Health.LastSeen,
+			LastChecked:         record.Health.LastChecked,
+			ConsecutiveFailures: record.Health.ConsecutiveFailures,
+			Latency:             record.Health.Latency,
+			LastError:           record.Health.LastError,
+		},
+		LeaseTTL:  record.LeaseTTL,
+		ExpiresAt: record.ExpiresAt,
+		CreatedAt: record.CreatedAt,
+		UpdatedAt: record.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,226 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func openBoltStore(t *testing.T, path string, opts ...BoltOption) *BoltStore {
+	t.Helper()
+	s, err := NewBoltStore(path, opts...)
+	if err != nil {
+		t.Fatalf("NewBoltStore() error = %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestBoltStore_PersistsAcrossRestart(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "agents.db")
+
+	s, err := NewBoltStore(path)
+	if err != nil {
+		t.Fatalf("NewBoltStore() error = %v", err)
+	}
+	agent := validAgent("agent-1")
+	agent.Embedding = []float32{1, 0, 0}
+	agent.SourceURL = "http://localhost:9000"
+	agent.LeaseTTL = time.Minute
+	if err := s.CreateAgent(ctx, agent); err != nil {
+		t.Fatalf("CreateAgent() error = %v", err)
+	}
+	health := AgentHealth{Status: HealthUnhealthy, ConsecutiveFailures: 3, LastError: "timeout"}
+	if err := s.SetAgentHealth(ctx, "agent-1", health); err != nil {
+		t.Fatalf("SetAgentHealth() error = %v", err)
+	}
+	expiresAt := time.Now().Add(time.Minute).Truncate(time.Second)
+	if err := s.RenewAgentLease(ctx, "agent-1", expiresAt); err != nil {
+		t.Fatalf("RenewAgentLease() error = %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	reopened := openBoltStore(t, path)
+	got, err := reopened.GetAgent(ctx, "agent-1")
+	if err != nil {
+		t.Fatalf("GetAgent() error = %v", err)
+	}
+
+	if got.Card.Name != agent.Card.Name || len(got.Card.Skills) != 1 {
+		t.Errorf("Card = %+v, want %+v", got.Card, agent.Card)
+	}
+	if len(got.Tags) != 1 || got.Tags[0] != "test" {
+		t.Errorf("Tags = %v, want [test]", got.Tags)
+	}
+	if len(got.Embedding) != 3 || got.SourceURL != agent.SourceURL || got.LeaseTTL != time.Minute {
+		t.Errorf("agent = %+v, want embedding, source URL and lease preserved", got)
+	}
+	if got.Health.Status != HealthUnhealthy || got.Health.LastError != "timeout" {
+		t.Errorf("Health = %+v, want %+v", got.Health, health)
+	}
+	if !got.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("ExpiresAt = %v, want %v", got.ExpiresAt, expiresAt)
+	}
+}
+
+func TestBoltStore_SearchAgents(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	s := openBoltStore(t, filepath.Join(t.TempDir(), "agents.db"))
+
+	for id, vector := range map[string][]float32{
+		"east":  {1, 0},
+		"north": {0, 1},
+		"ne":    {1, 1},
+	} {
+		agent := validAgent(id)
+		agent.Embedding = vector
+		if err := s.CreateAgent(ctx, agent); err != nil {
+			t.Fatalf("CreateAgent(%s) error = %v", id, err)
+		}
+	}
+
+	result, err := s.SearchAgents(ctx, []float32{1, 0.1}, 2, AgentFilter{})
+	if err != nil {
+		t.Fatalf("SearchAgents() error = %v", err)
+	}
+	if len(result.Agents) != 2 || result.Agents[0].Agent.ID != "east" || result.Agents[1].Agent.ID != "ne" {
+		t.Errorf("SearchAgents() = %v, want [east ne]", scoredIDs(result.Agents))
+	}
+}
+
+func TestBoltStore_Mutations(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "agents.db")
+	s := openBoltStore(t, path, WithBoltVectorDimension(2))
+
+	t.Run("duplicate create returns ErrAlreadyExists", func(t *testing.T) {
+		_ = s.CreateAgent(ctx, validAgent("dup"))
+		if err := s.CreateAgent(ctx, validAgent("dup")); !errors.Is(err, ErrAlreadyExists) {
+			t.Errorf("CreateAgent() error = %v, want ErrAlreadyExists", err)
+		}
+	})
+
+	t.Run("wrong embedding dimension is rejected", func(t *testing.T) {
+		agent := validAgent("wide")
+		agent.Embedding = []float32{1, 2, 3}
+		if err := s.CreateAgent(ctx, agent); err == nil {
+			t.Error("CreateAgent() should reject a 3-dimensional embedding")
+		}
+	})
+
+	t.Run("update and delete of missing agent return ErrNotFound", func(t *testing.T) {
+		if err := s.UpdateAgent(ctx, validAgent("missing")); !errors.Is(err, ErrNotFound) {
+			t.Errorf("UpdateAgent() error = %v, want ErrNotFound", err)
+		}
+		if err := s.DeleteAgent(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("DeleteAgent() error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("expired agents are deleted from the file", func(t *testing.T) {
+		now := time.Now()
+		expired := validAgent("expired")
+		expired.ExpiresAt = now.Add(-time.Second)
+		_ = s.CreateAgent(ctx, expired)
+
+		ids, err := s.DeleteExpiredAgents(ctx, now)
+		if err != nil {
+			t.Fatalf("DeleteExpiredAgents() error = %v", err)
+		}
+		if len(ids) != 1 || ids[0] != "expired" {
+			t.Errorf("DeleteExpiredAgents() = %v, want [expired]", ids)
+		}
+
+		var stored int
+		_ = s.db.View(func(tx *bolt.Tx) error {
+			stored = tx.Bucket(agentsBucket).Stats().KeyN
+			return nil
+		})
+		if stored != 1 {
+			t.Errorf("file holds %d agents, want 1", stored)
+		}
+	})
+}
+
+func scoredIDs(agents []ScoredAgent) []string {
+	ids := make([]string, len(agents))
+	for i, a := range agents {
+		ids[i] = a.Agent.ID
+	}
+	return ids
+}
+
+func TestBoltStore_EmbeddingMismatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		first        []BoltOption
+		reopen       []BoltOption
+		wantMismatch bool
+	}{
+		{
+			name:   "same model and dimension",
+			first:  []BoltOption{WithBoltVectorDimension(3), WithBoltEmbeddingModel("mini")},
+			reopen: []BoltOption{WithBoltVectorDimension(3), WithBoltEmbeddingModel("mini")},
+		},
+		{
+			name:         "different dimension",
+			first:        []BoltOption{WithBoltVectorDimension(3), WithBoltEmbeddingModel("mini")},
+			reopen:       []BoltOption{WithBoltVectorDimension(4), WithBoltEmbeddingModel("mini")},
+			wantMismatch: true,
+		},
+		{
+			name:         "different model",
+			first:        []BoltOption{WithBoltVectorDimension(3), WithBoltEmbeddingModel("mini")},
+			reopen:       []BoltOption{WithBoltVectorDimension(3), WithBoltEmbeddingModel("large")},
+			wantMismatch: true,
+		},
+		{
+			name:   "unconfigured model is not checked",
+			first:  []BoltOption{WithBoltVectorDimension(3), WithBoltEmbeddingModel("mini")},
+			reopen: []BoltOption{WithBoltVectorDimension(3)},
+		},
+		{
+			name:         "unrecorded store is checked against its agents",
+			reopen:       []BoltOption{WithBoltVectorDimension(4)},
+			wantMismatch: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			path := filepath.Join(t.TempDir(), "agents.db")
+
+			s, err := NewBoltStore(path, tt.first...)
+			if err != nil {
+				t.Fatalf("NewBoltStore() error = %v", err)
+			}
+			agent := validAgent("agent-1")
+			agent.Embedding = []float32{1, 0, 0}
+			if err := s.CreateAgent(context.Background(), agent); err != nil {
+				t.Fatalf("CreateAgent() error = %v", err)
+			}
+			_ = s.Close()
+
+			reopened, err := NewBoltStore(path, tt.reopen...)
+			if err == nil {
+				_ = reopened.Close()
+			}
+			if got := errors.Is(err, ErrEmbeddingMismatch); got != tt.wantMismatch {
+				t.Errorf("NewBoltStore() error = %v, want ErrEmbeddingMismatch %v", err, tt.wantMismatch)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package store_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store/storetest"
+)
+
+func TestMemoryStore_Conformance(t *testing.T) {
+	t.Parallel()
+	storetest.Run(t, func(t *testing.T) store.Store {
+		return store.NewMemoryStore()
+	})
+}
+
+func TestBoltStore_Conformance(t *testing.T) {
+	t.Parallel()
+	storetest.Run(t, func(t *testing.T) store.Store {
+		s, err := store.NewBoltStore(filepath.Join(t.TempDir(), "agents.db"),
+			store.WithBoltVectorDimension(storetest.VectorDimension),
+		)
+		if err != nil {
+			t.Fatalf("NewBoltStore() error = %v", err)
+		}
+		t.Cleanup(func() { _ = s.Close() })
+		return s
+	})
+}
@@ -0,0 +1,146 @@
+package store
+
+import (
+	"hash/fnv"
+	"math"
+	"slices"
+)
+
+// BM25 parameters. Document length is normalized against a fixed average so
+// term weights can be computed per agent without collection statistics, as
+// Qdrant sparse vectors require.
+const (
+	bm25K1        = 1.2
+	bm25B         = 0.75
+	bm25AvgLength = 64
+)
+
+// lexicalText returns the text indexed for keyword search: the card name,
+// description and every skill's ID, name, description, tags and examples.
+func lexicalText(agent *RegisteredAgent) []string {
+	texts := []string{agent.Card.Name, agent.Card.Description}
+	for _, skill := range agent.Card.Skills {
+		texts = append(texts, skill.ID, skill.Name, skill.Description)
+		texts = append(texts, skill.Tags...)
+		texts = append(texts, skill.Examples...)
+	}
+	return texts
+}
+
+// termID hashes a token to its sparse vector index.
+func termID(token string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(token))
+	return h.Sum32()
+}
+
+// lexicalWeights returns the BM25 term frequency weight of every term of an
+// agent, keyed by term ID. The inverse document frequency is applied at
+// query time.
+func lexicalWeights(agent *RegisteredAgent) map[uint32]float32 {
+	counts := make(map[uint32]int)
+	length := 0
+	for _, text := range lexicalText(agent) {
+		for _, token := range textTokens(text) {
+			counts[termID(token)]++
+			length++
+		}
+	}
+
+	norm := bm25K1 * (1 - bm25B + bm25B*float64(length)/bm25AvgLength)
+	weights := make(map[uint32]float32, len(counts))
+	for id, tf := range counts {
+		weights[id] = float32(float64(tf) * (bm25K1 + 1) / (float64(tf) + norm))
+	}
+	return weights
+}
+
+// lexicalVector returns the term weights of an agent as sparse vector
+// indices and values sorted by index.
+func lexicalVector(agent *RegisteredAgent) ([]uint32, []float32) {
+	return sparse(lexicalWeights(agent))
+}
+
+// queryTerms returns the distinct term IDs of a keyword query.
+func queryTerms(query string) []uint32 {
+	var ids []uint32
+	for _, token := range textTokens(query) {
+		id := termID(token)
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// idf returns the BM25 inverse document frequency of a term found in df of n documents.
+func idf(n, df int) float64 {
+	return math.Log(1 + (float64(n)-float64(df)+0.5)/(float64(df)+0.5))
+}
+
+// sparse converts a weight map to sparse vector indices and values sorted by index.
+func sparse(weights map[uint32]float32) ([]uint32, []float32) {
+	indices := make([]uint32, 0, len(weights))
+	for id := range weights {
+		indices = append(indices, id)
+	}
+	slices.Sort(indices)
+
+	values := make([]float32, len(indices))
+	for i, id := range indices {
+		values[i] = weights[id]
+	}
+	return indices, values
+}
+
+// lexicalIndex is an in-process BM25 index over agents.
+type lexicalIndex struct {
+	// docs holds the term weights of each agent by agent ID.
+	docs map[string]map[uint32]float32
+	// df is the number of agents containing each term.
+	df map[uint32]int
+}
+
+func newLexicalIndex() *lexicalIndex {
+	return &lexicalIndex{
+		docs: make(map[string]map[uint32]float32),
+		df:   make(map[uint32]int),
+	}
+}
+
+// add indexes an agent, replacing any previous version.
+func (x *lexicalIndex) add(agent *RegisteredAgent) {
+	x.remove(agent.ID)
+	weights := lexicalWeights(agent)
+	x.docs[agent.ID] = weights
+	for id := range weights {
+		x.df[id]++
+	}
+}
+
+// remove drops an agent from the index.
+func (x *lexicalIndex) remove(agentID string) {
+	weights, ok := x.docs[agentID]
+	if !ok {
+		return
+	}
+	for id := range weights {
+		if x.df[id]--; x.df[id] == 0 {
+			delete(x.df, id)
+		}
+	}
+	delete(x.docs, agentID)
+}
+
+// score returns the BM25 score of an agent for the query terms, 0 if no term matches.
+func (x *lexicalIndex) score(agentID string, terms []uint32) float32 {
+	weights := x.docs[agentID]
+	var score float64
+	for _, id := range terms {
+		if w, ok := weights[id]; ok {
+			score += idf(len(x.docs), x.df[id]) * float64(w)
+		}
+	}
+	return float32(score)
+}
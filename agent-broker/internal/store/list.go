@@ -0,0 +1,150 @@
+package store
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// listPosition is the sort key of an agent in a listing.
+type listPosition struct {
+	// Time is the timestamp sort key in Unix nanoseconds.
+	Time int64 `json:"t,omitempty"`
+	// Name is the lowercased card name sort key.
+	Name string `json:"n,omitempty"`
+	// ID breaks ties between equal sort keys.
+	ID string `json:"id"`
+}
+
+// listCursor is the decoded form of an opaque list cursor. It holds the sort
+// it was issued for and the position of the last agent returned.
+type listCursor struct {
+	// Sort is the field the listing is ordered by.
+	Sort SortField `json:"s"`
+	// Order is the sort direction.
+	Order SortOrder `json:"o"`
+	// Last is the position of the last agent of the previous page.
+	Last listPosition `json:"p"`
+}
+
+// normalizeSort returns the sort field and order of filter with defaults applied.
+func normalizeSort(filter AgentFilter) (SortField, SortOrder, error) {
+	field := filter.Sort
+	switch field {
+	case "":
+		field = SortByCreatedAt
+	case SortByCreatedAt, SortByUpdatedAt, SortByName:
+	default:
+		return "", "", fmt.Errorf("unknown sort field %q", field)
+	}
+
+	order := filter.Order
+	switch order {
+	case "":
+		order = SortDesc
+		if field == SortByName {
+			order = SortAsc
+		}
+	case SortAsc, SortDesc:
+	default:
+		return "", "", fmt.Errorf("unknown sort order %q", order)
+	}
+
+	return field, order, nil
+}
+
+// positionOf returns the sort key of agent for field.
+func positionOf(agent *RegisteredAgent, field SortField) listPosition {
+	switch field {
+	case SortByName:
+		return listPosition{Name: strings.ToLower(agent.Card.Name), ID: agent.ID}
+	case SortByUpdatedAt:
+		return listPosition{Time: agent.UpdatedAt.UnixNano(), ID: agent.ID}
+	default:
+		return listPosition{Time: agent.CreatedAt.UnixNano(), ID: agent.ID}
+	}
+}
+
+// comparePositions reports whether a sorts before (-1), with (0) or after (1) b.
+func comparePositions(a, b listPosition, field SortField, order SortOrder) int {
+	var c int
+	if field == SortByName {
+		c = cmp.Compare(a.Name, b.Name)
+	} else {
+		c = cmp.Compare(a.Time, b.Time)
+	}
+	if order == SortDesc {
+		c = -c
+	}
+	if c != 0 {
+		return c
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// sortAgents orders agents by field and order.
+func sortAgents(agents []*RegisteredAgent, field SortField, order SortOrder) {
+	slices.SortFunc(agents, func(a, b *RegisteredAgent) int {
+		return comparePositions(positionOf(a, field), positionOf(b, field), field, order)
+	})
+}
+
+// encodeCursor returns the cursor continuing a listing after agent.
+func encodeCursor(agent *RegisteredAgent, field SortField, order SortOrder) string {
+	data, _ := json.Marshal(listCursor{Sort: field, Order: order, Last: positionOf(agent, field)})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses a cursor and checks it was issued for field and order.
+func decodeCursor(cursor string, field SortField, order SortOrder) (listCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return listCursor{}, ErrInvalidCursor
+	}
+
+	var c listCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return listCursor{}, ErrInvalidCursor
+	}
+	if c.Sort != field || c.Order != order {
+		return listCursor{}, fmt.Errorf("%w: issued for sort %s %s", ErrInvalidCursor, c.Sort, c.Order)
+	}
+	return c, nil
+}
+
+// pageAgents sorts agents and returns the page selected by the filter's
+// cursor or offset and limit, with the cursor of the next page.
+func pageAgents(agents []*RegisteredAgent, filter AgentFilter) ([]*RegisteredAgent, string, error) {
+	field, order, err := normalizeSort(filter)
+	if err != nil {
+		return nil, "", err
+	}
+	sortAgents(agents, field, order)
+
+	start := min(max(filter.Offset, 0), len(agents))
+	if filter.Cursor != "" {
+		c, err := decodeCursor(filter.Cursor, field, order)
+		if err != nil {
+			return nil, "", err
+		}
+		start = sort.Search(len(agents), func(i int) bool {
+			return comparePositions(positionOf(agents[i], field), c.Last, field, order) > 0
+		})
+	}
+
+	end := len(agents)
+	if filter.Limit > 0 {
+		end = min(start+filter.Limit, len(agents))
+	}
+
+	page := agents[start:end]
+	var next string
+	if end < len(agents) && len(page) > 0 {
+		next = encodeCursor(page[len(page)-1], field, order)
+	}
+	return page, next, nil
+}
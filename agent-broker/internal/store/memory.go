@@ -3,9 +3,12 @@ package store
 import (
 	"context"
 	"math"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
+	"time"
+	"unicode"
 )
 
 // MemoryStore implements AgentStore with in-memory storage.
@@ -14,12 +17,17 @@ type MemoryStore struct {
 	mu sync.RWMutex
 	// agents is the in-memory agent storage.
 	agents map[string]*RegisteredAgent
+	// lexical is the keyword index over agents.
+	lexical *lexicalIndex
+	// reindex is the target of an unfinished reindex, nil if none.
+	reindex *memoryTarget
 }
 
 // NewMemoryStore creates a new in-memory store.
 func NewMemoryStore() *MemoryStore {
 	return &MemoryStore{
-		agents: make(map[string]*RegisteredAgent),
+		agents:  make(map[string]*RegisteredAgent),
+		lexical: newLexicalIndex(),
 	}
 }
 
@@ -42,10 +50,16 @@ func (s *MemoryStore) CreateAgent(_ context.Context, agent *RegisteredAgent) err
 		return ErrAlreadyExists
 	}
 
-	s.agents[agent.ID] = agent
+	s.put(agent)
 	return nil
 }
 
+// put stores and indexes an agent. The caller must hold mu.
+func (s *MemoryStore) put(agent *RegisteredAgent) {
+	s.agents[agent.ID] = agent
+	s.lexical.add(agent)
+}
+
 // GetAgent retrieves an agent by ID.
 func (s *MemoryStore) GetAgent(_ context.Context, id string) (*RegisteredAgent, error) {
 	s.mu.RLock()
@@ -64,25 +78,22 @@ func (s *MemoryStore) ListAgents(_ context.Context, filter AgentFilter) (*AgentL
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	var filtered []*RegisteredAgent
+	filtered := []*RegisteredAgent{}
 	for _, agent := range s.agents {
 		if matchesFilter(agent, filter) {
 			filtered = append(filtered, agent)
 		}
 	}
 
-	sort.Slice(filtered, func(i, j int) bool {
-		return filtered[i].CreatedAt.After(filtered[j].CreatedAt)
-	})
-
-	total := len(filtered)
-
-	start := min(filter.Offset, len(filtered))
-	end := min(start+filter.Limit, len(filtered))
+	page, next, err := pageAgents(filtered, filter)
+	if err != nil {
+		return nil, err
+	}
 
 	return &AgentListResult{
-		Agents: filtered[start:end],
-		Total:  total,
+		Agents:     page,
+		Total:      len(filtered),
+		NextCursor: next,
 	}, nil
 }
 
@@ -91,14 +102,23 @@ func (s *MemoryStore) UpdateAgent(_ context.Context, agent *RegisteredAgent) err
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.agents[agent.ID]; !exists {
+	existing, exists := s.agents[agent.ID]
+	if !exists {
 		return ErrNotFound
 	}
 
-	s.agents[agent.ID] = agent
+	s.put(agent.withStateOf(existing))
 	return nil
 }
 
+// replace stores agent as is, including its health and lease expiry.
+func (s *MemoryStore) replace(agent *RegisteredAgent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.put(agent)
+}
+
 // DeleteAgent removes an agent.
 func (s *MemoryStore) DeleteAgent(_ context.Context, id string) error {
 	s.mu.Lock()
@@ -109,9 +129,59 @@ func (s *MemoryStore) DeleteAgent(_ context.Context, id string) error {
 	}
 
 	delete(s.agents, id)
+	s.lexical.remove(id)
 	return nil
 }
 
+// SetAgentHealth replaces the health state of an agent.
+func (s *MemoryStore) SetAgentHealth(_ context.Context, id string, health AgentHealth) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, exists := s.agents[id]
+	if !exists {
+		return ErrNotFound
+	}
+
+	// Store a copy so readers holding the previous pointer are not affected.
+	updated := *agent
+	updated.Health = health
+	s.agents[id] = &updated
+	return nil
+}
+
+// RenewAgentLease sets the lease expiry of an agent.
+func (s *MemoryStore) RenewAgentLease(_ context.Context, id string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	agent, exists := s.agents[id]
+	if !exists {
+		return ErrNotFound
+	}
+
+	updated := *agent
+	updated.ExpiresAt = expiresAt
+	s.agents[id] = &updated
+	return nil
+}
+
+// DeleteExpiredAgents removes agents whose lease expired at or before now.
+func (s *MemoryStore) DeleteExpiredAgents(_ context.Context, now time.Time) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []string
+	for id, agent := range s.agents {
+		if agent.Expired(now) {
+			delete(s.agents, id)
+			s.lexical.remove(id)
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
 // SearchAgents finds agents by vector similarity with optional filtering.
 func (s *MemoryStore) SearchAgents(_ context.Context, query []float32, limit int, filter AgentFilter) (*SearchResult, error) {
 	s.mu.RLock()
@@ -122,14 +192,15 @@ func (s *MemoryStore) SearchAgents(_ context.Context, query []float32, limit int
 		if !matchesFilter(agent, filter) {
 			continue
 		}
-		if len(agent.Embedding) == 0 {
+		if len(agent.Embedding) == 0 && len(agent.SkillEmbeddings) == 0 {
 			continue
 		}
 
-		score := cosineSimilarity(query, agent.Embedding)
+		score, skill := bestMatch(query, agent)
 		scored = append(scored, ScoredAgent{
-			Agent: agent,
-			Score: score,
+			Agent:        agent,
+			Score:        score,
+			MatchedSkill: skill,
 		})
 	}
 
@@ -144,6 +215,70 @@ func (s *MemoryStore) SearchAgents(_ context.Context, query []float32, limit int
 	return &SearchResult{Agents: scored}, nil
 }
 
+// LexicalSearchAgents finds agents by BM25 keyword relevance with optional filtering.
+func (s *MemoryStore) LexicalSearchAgents(_ context.Context, query string, limit int, filter AgentFilter) (*SearchResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	terms := queryTerms(query)
+	var scored []ScoredAgent
+	for _, agent := range s.agents {
+		if !matchesFilter(agent, filter) {
+			continue
+		}
+
+		if score := s.lexical.score(agent.ID, terms); score > 0 {
+			scored = append(scored, ScoredAgent{
+				Agent: agent,
+				Score: score,
+			})
+		}
+	}
+
+	sort.Slice(scored, func(i, j int) bool {
+		return scored[i].Score > scored[j].Score
+	})
+
+	if limit > 0 && len(scored) > limit {
+		scored = scored[:limit]
+	}
+
+	return &SearchResult{Agents: scored}, nil
+}
+
+// bestMatch returns the best cosine similarity between query and the agent
+// embedding or any skill embedding, with the ID of the skill that produced it,
+// empty if the agent embedding did. Skills are compared in card order.
+func bestMatch(query []float32, agent *RegisteredAgent) (float32, string) {
+	best := float32(math.Inf(-1))
+	if len(agent.Embedding) > 0 {
+		best = cosineSimilarity(query, agent.Embedding)
+	}
+
+	var matched string
+	for _, skill := range agent.Card.Skills {
+		vector, ok := agent.SkillEmbeddings[skill.ID]
+		if !ok {
+			continue
+		}
+		if score := cosineSimilarity(query, vector); score > best {
+			best, matched = score, skill.ID
+		}
+	}
+	return best, matched
+}
+
+// Similarity returns the best cosine similarity between query and the agent
+// embedding or any skill embedding, as embedding search scores agents, with
+// the ID of the skill that produced it. It is 0 for agents without embeddings.
+func Similarity(query []float32, agent *RegisteredAgent) (float32, string) {
+	score, skill := bestMatch(query, agent)
+	if math.IsInf(float64(score), -1) {
+		return 0, ""
+	}
+	return score, skill
+}
+
 // cosineSimilarity calculates the cosine similarity between two vectors.
 func cosineSimilarity(a, b []float32) float32 {
 	if len(a) != len(b) || len(a) == 0 {
@@ -165,6 +300,10 @@ func cosineSimilarity(a, b []float32) float32 {
 }
 
 func matchesFilter(agent *RegisteredAgent, filter AgentFilter) bool {
+	if filter.ExcludeUnhealthy && agent.Health.Status == HealthUnhealthy {
+		return false
+	}
+
 	if len(filter.Tags) > 0 {
 		hasTag := false
 		for _, t := range filter.Tags {
@@ -202,12 +341,31 @@ func matchesFilter(agent *RegisteredAgent, filter AgentFilter) bool {
 	}
 
 	if filter.Query != "" {
-		query := strings.ToLower(filter.Query)
-		if !strings.Contains(strings.ToLower(agent.Card.Name), query) &&
-			!strings.Contains(strings.ToLower(agent.Card.Description), query) {
+		query := textTokens(filter.Query)
+		if !containsAllTokens(agent.Card.Name, query) &&
+			!containsAllTokens(agent.Card.Description, query) {
 			return false
 		}
 	}
 
 	return true
 }
+
+// textTokens splits text into lower-case words, treating every character that
+// is not a letter or digit as a separator. This matches the Qdrant word tokenizer.
+func textTokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// containsAllTokens reports whether every token occurs as a word of text.
+func containsAllTokens(text string, tokens []string) bool {
+	words := textTokens(text)
+	for _, token := range tokens {
+		if !slices.Contains(words, token) {
+			return false
+		}
+	}
+	return true
+}
@@ -338,3 +338,35 @@ func TestMemoryStore_DeleteAgent(t *testing.T) {
 		})
 	}
 }
+
+func TestMemoryStore_DeleteExpiredAgents(t *testing.T) {
+	t.Parallel()
+	s := NewMemoryStore()
+	ctx := context.Background()
+	now := time.Now()
+
+	expired := validAgent("expired")
+	expired.ExpiresAt = now.Add(-time.Second)
+	live := validAgent("live")
+	live.ExpiresAt = now.Add(time.Minute)
+	permanent := validAgent("permanent")
+	_ = s.CreateAgent(ctx, expired)
+	_ = s.CreateAgent(ctx, live)
+	_ = s.CreateAgent(ctx, permanent)
+
+	ids, err := s.DeleteExpiredAgents(ctx, now)
+	if err != nil {
+		t.Fatalf("DeleteExpiredAgents() error = %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "expired" {
+		t.Errorf("DeleteExpiredAgents() = %v, want [expired]", ids)
+	}
+	if _, err := s.GetAgent(ctx, "expired"); err != ErrNotFound {
+		t.Errorf("GetAgent(expired) error = %v, want ErrNotFound", err)
+	}
+	for _, id := range []string{"live", "permanent"} {
+		if _, err := s.GetAgent(ctx, id); err != nil {
+			t.Errorf("GetAgent(%s) error = %v", id, err)
+		}
+	}
+}
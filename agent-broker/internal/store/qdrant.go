@@ -4,12 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"sort"
+	"maps"
+	"slices"
+	"sync/atomic"
 	"time"
 
 	"github.com/a2aproject/a2a-go/a2a"
 	"github.com/google/uuid"
 	"github.com/qdrant/go-client/qdrant"
+	"google.golang.org/grpc"
 )
 
 // Options configures the QdrantStore.
@@ -26,6 +29,15 @@ type Options struct {
 	CollectionName string
 	// VectorDimension is the size of embedding vectors.
 	VectorDimension uint64
+	// EmbeddingModel is the name of the model producing the vectors, recorded
+	// on the collection and checked on start. Empty skips the model check.
+	EmbeddingModel string
+	// EmbeddingCheck refuses to open a collection built with another
+	// embedding model or dimension. Disabled to reindex such a collection.
+	EmbeddingCheck bool
+	// DialOptions are extra gRPC options for the connection, for example
+	// interceptors instrumenting Qdrant calls.
+	DialOptions []grpc.DialOption
 }
 
 // DefaultOptions returns Options with sensible defaults.
@@ -37,6 +49,7 @@ func DefaultOptions() Options {
 		APIKey:         "",
 		UseTLS:         false,
 		CollectionName: "agents",
+		EmbeddingCheck: true,
 	}
 }
 
@@ -85,12 +98,48 @@ func WithVectorDimension(dim uint64) Option {
 	}
 }
 
+// WithEmbeddingModel sets the embedding model name recorded on the collection.
+func WithEmbeddingModel(model string) Option {
+	return func(o *Options) {
+		o.EmbeddingModel = model
+	}
+}
+
+// WithEmbeddingCheck enables or disables the embedding model and dimension check.
+func WithEmbeddingCheck(enabled bool) Option {
+	return func(o *Options) {
+		o.EmbeddingCheck = enabled
+	}
+}
+
+// WithDialOptions appends gRPC options for the connection.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *Options) {
+		o.DialOptions = append(o.DialOptions, opts...)
+	}
+}
+
+// Collection metadata keys recording how the agent vectors were produced.
+const (
+	metaEmbeddingModel = "embedding_model"
+	metaEmbeddingDim   = "embedding_dim"
+)
+
+// lexicalVectorName is the sparse vector holding BM25 term weights.
+const lexicalVectorName = "lexical"
+
 // QdrantStore implements Store using Qdrant as the vector database.
 type QdrantStore struct {
 	// client is the Qdrant gRPC client.
 	client *qdrant.Client
-	// collectionName is the name of the agents collection.
+	// collectionName is the alias of the agents collection, or the collection
+	// itself if it predates aliases.
 	collectionName string
+	// lexical is true if the collection has the sparse vector for keyword search.
+	lexical atomic.Bool
+	// multi is true if the default vector is a multivector holding the agent
+	// embedding followed by its skill embeddings.
+	multi atomic.Bool
 }
 
 // NewQdrantStore creates a QdrantStore with the given options.
@@ -106,10 +155,11 @@ func NewQdrantStore(ctx context.Context, opts ...Option) (*QdrantStore, error) {
 	}
 
 	client, err := qdrant.NewClient(&qdrant.Config{
-		Host:   options.Host,
-		Port:   options.Port,
-		APIKey: options.APIKey,
-		UseTLS: options.UseTLS,
+		Host:        options.Host,
+		Port:        options.Port,
+		APIKey:      options.APIKey,
+		UseTLS:      options.UseTLS,
+		GrpcOptions: options.DialOptions,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create qdrant client: %w", err)
@@ -133,34 +183,153 @@ func NewQdrantStore(ctx context.Context, opts ...Option) (*QdrantStore, error) {
 	return store, nil
 }
 
-// ensureCollection creates the collection if it doesn't exist.
+// ensureCollection creates the collection behind the collectionName alias if
+// neither exists, and checks and upgrades an existing one.
 func (s *QdrantStore) ensureCollection(ctx context.Context, opts Options) error {
-	exists, err := s.client.CollectionExists(ctx, opts.CollectionName)
+	collection, err := s.resolveCollection(ctx)
 	if err != nil {
-		return fmt.Errorf("check collection exists: %w", err)
+		return err
+	}
+	exists := collection != ""
+
+	if !exists {
+		collection = newCollectionName(opts.CollectionName)
+		if err := s.createCollection(ctx, collection, opts.VectorDimension, opts.EmbeddingModel); err != nil {
+			return err
+		}
+		if err := s.client.CreateAlias(ctx, opts.CollectionName, collection); err != nil {
+			return fmt.Errorf("create alias: %w", err)
+		}
+	} else {
+		// Sparse vectors and multivectors cannot be added to an existing
+		// collection, so older collections serve semantic search on agent
+		// embeddings only until they are reindexed.
+		info, err := s.client.GetCollectionInfo(ctx, collection)
+		if err != nil {
+			return fmt.Errorf("get collection info: %w", err)
+		}
+		if opts.EmbeddingCheck {
+			if err := s.checkEmbedding(ctx, collection, info, opts); err != nil {
+				return err
+			}
+		}
+		s.setCapabilities(info)
+	}
+
+	// Indexes are ensured on every start so collections created by earlier
+	// versions gain indexes added since. Qdrant treats re-creating an index
+	// with unchanged parameters as a no-op.
+	if err := s.ensureIndexes(ctx, collection); err != nil {
+		return err
 	}
 
 	if exists {
-		return nil
+		return s.migratePointIDs(ctx)
+	}
+	return nil
+}
+
+// resolveCollection returns the collection served under collectionName: the
+// target of the alias, the collection of that name if it predates aliases, or
+// empty if neither exists.
+func (s *QdrantStore) resolveCollection(ctx context.Context) (string, error) {
+	aliases, err := s.client.ListAliases(ctx)
+	if err != nil {
+		return "", fmt.Errorf("list aliases: %w", err)
+	}
+	for _, alias := range aliases {
+		if alias.GetAliasName() == s.collectionName {
+			return alias.GetCollectionName(), nil
+		}
 	}
 
-	err = s.client.CreateCollection(ctx, &qdrant.CreateCollection{
-		CollectionName: opts.CollectionName,
+	exists, err := s.client.CollectionExists(ctx, s.collectionName)
+	if err != nil {
+		return "", fmt.Errorf("check collection exists: %w", err)
+	}
+	if !exists {
+		return "", nil
+	}
+	return s.collectionName, nil
+}
+
+// newCollectionName returns a unique name for a collection served under alias.
+func newCollectionName(alias string) string {
+	return fmt.Sprintf("%s_%d", alias, time.Now().UnixNano())
+}
+
+// createCollection creates a collection with agent and skill multivectors,
+// BM25 sparse vectors and the embedding model and dimension as metadata.
+func (s *QdrantStore) createCollection(ctx context.Context, name string, dim uint64, model string) error {
+	err := s.client.CreateCollection(ctx, &qdrant.CreateCollection{
+		CollectionName: name,
+		// Points score the best match of the agent and skill embeddings.
 		VectorsConfig: qdrant.NewVectorsConfig(&qdrant.VectorParams{
-			Size:     opts.VectorDimension,
+			Size:     dim,
 			Distance: qdrant.Distance_Cosine,
+			MultivectorConfig: &qdrant.MultiVectorConfig{
+				Comparator: qdrant.MultiVectorComparator_MaxSim,
+			},
+		}),
+		// Qdrant applies the IDF part of BM25 from collection statistics.
+		SparseVectorsConfig: qdrant.NewSparseVectorsConfig(map[string]*qdrant.SparseVectorParams{
+			lexicalVectorName: {Modifier: qdrant.PtrOf(qdrant.Modifier_Idf)},
 		}),
+		Metadata: qdrant.NewValueMap(embeddingMetadata(model, dim)),
 	})
 	if err != nil {
 		return fmt.Errorf("create collection: %w", err)
 	}
+	s.lexical.Store(true)
+	s.multi.Store(true)
+	return nil
+}
 
-	// Create payload indexes for efficient filtering
-	// Index on agent ID for lookups
-	keywordIndexes := []string{"id", "tags", "skill_ids"}
+// setCapabilities records which vectors the collection described by info has.
+func (s *QdrantStore) setCapabilities(info *qdrant.CollectionInfo) {
+	params := info.GetConfig().GetParams()
+	s.lexical.Store(params.GetSparseVectorsConfig().GetMap()[lexicalVectorName] != nil)
+	s.multi.Store(params.GetVectorsConfig().GetParams().GetMultivectorConfig() != nil)
+}
+
+// checkEmbedding refuses a collection built with a different vector dimension
+// or embedding model than configured. Collections created before the model
+// was recorded adopt the configured one.
+func (s *QdrantStore) checkEmbedding(ctx context.Context, collection string, info *qdrant.CollectionInfo, opts Options) error {
+	if size := info.GetConfig().GetParams().GetVectorsConfig().GetParams().GetSize(); size != opts.VectorDimension {
+		return fmt.Errorf("%w: collection %s has %d-dimensional vectors, embedding dimension is %d",
+			ErrEmbeddingMismatch, collection, size, opts.VectorDimension)
+	}
+
+	model := info.GetConfig().GetMetadata()[metaEmbeddingModel].GetStringValue()
+	if model != "" && opts.EmbeddingModel != "" && model != opts.EmbeddingModel {
+		return fmt.Errorf("%w: collection %s was built with model %q, embedding model is %q",
+			ErrEmbeddingMismatch, collection, model, opts.EmbeddingModel)
+	}
+
+	if model == "" && opts.EmbeddingModel != "" {
+		return s.updateMetadata(ctx, collection, embeddingMetadata(opts.EmbeddingModel, opts.VectorDimension))
+	}
+	return nil
+}
+
+// embeddingMetadata returns the collection metadata recording the embedding
+// model and dimension.
+func embeddingMetadata(model string, dim uint64) map[string]any {
+	return map[string]any{
+		metaEmbeddingModel: model,
+		metaEmbeddingDim:   int64(dim),
+	}
+}
+
+// ensureIndexes creates the payload indexes used for filtering and ordering.
+func (s *QdrantStore) ensureIndexes(ctx context.Context, collectionName string) error {
+	var err error
+
+	keywordIndexes := []string{"id", "tags", "skill_ids", "health_status"}
 	for _, field := range keywordIndexes {
 		_, err = s.client.CreateFieldIndex(ctx, &qdrant.CreateFieldIndexCollection{
-			CollectionName: opts.CollectionName,
+			CollectionName: collectionName,
 			FieldName:      field,
 			FieldType:      qdrant.PtrOf(qdrant.FieldType_FieldTypeKeyword),
 		})
@@ -169,34 +338,140 @@ func (s *QdrantStore) ensureCollection(ctx context.Context, opts Options) error
 		}
 	}
 
+	// Text indexes split on non-alphanumerics and lowercase tokens, matching
+	// the whole-word query semantics of AgentFilter.Query.
 	textIndexes := []string{"card_name", "card_description"}
 	for _, field := range textIndexes {
 		_, err = s.client.CreateFieldIndex(ctx, &qdrant.CreateFieldIndexCollection{
-			CollectionName: opts.CollectionName,
+			CollectionName: collectionName,
 			FieldName:      field,
 			FieldType:      qdrant.PtrOf(qdrant.FieldType_FieldTypeText),
+			FieldIndexParams: &qdrant.PayloadIndexParams{
+				IndexParams: &qdrant.PayloadIndexParams_TextIndexParams{
+					TextIndexParams: &qdrant.TextIndexParams{
+						Tokenizer: qdrant.TokenizerType_Word,
+						Lowercase: qdrant.PtrOf(true),
+					},
+				},
+			},
 		})
 		if err != nil {
 			return fmt.Errorf("create %s index: %w", field, err)
 		}
 	}
 
-	// Create integer index with range support for ordering
-	_, err = s.client.CreateFieldIndex(ctx, &qdrant.CreateFieldIndexCollection{
-		CollectionName: opts.CollectionName,
-		FieldName:      "created_at",
-		FieldType:      qdrant.PtrOf(qdrant.FieldType_FieldTypeInteger),
-		FieldIndexParams: &qdrant.PayloadIndexParams{
-			IndexParams: &qdrant.PayloadIndexParams_IntegerIndexParams{
-				IntegerIndexParams: &qdrant.IntegerIndexParams{
-					Lookup: qdrant.PtrOf(true),
-					Range:  qdrant.PtrOf(true),
+	// Integer indexes with range support for ordering and lease expiry
+	rangeIndexes := []string{"created_at", "updated_at", "expires_at"}
+	for _, field := range rangeIndexes {
+		_, err = s.client.CreateFieldIndex(ctx, &qdrant.CreateFieldIndexCollection{
+			CollectionName: collectionName,
+			FieldName:      field,
+			FieldType:      qdrant.PtrOf(qdrant.FieldType_FieldTypeInteger),
+			FieldIndexParams: &qdrant.PayloadIndexParams{
+				IndexParams: &qdrant.PayloadIndexParams_IntegerIndexParams{
+					IntegerIndexParams: &qdrant.IntegerIndexParams{
+						Lookup: qdrant.PtrOf(true),
+						Range:  qdrant.PtrOf(true),
+					},
 				},
 			},
-		},
+		})
+		if err != nil {
+			return fmt.Errorf("create %s index: %w", field, err)
+		}
+	}
+
+	return nil
+}
+
+// migratePointIDs moves points stored under random IDs by earlier versions to
+// the ID derived from their agent ID. Each point is copied before the original
+// is deleted, so an interrupted migration resumes on the next start. When an
+// agent has several legacy points, the most recently updated one is kept.
+func (s *QdrantStore) migratePointIDs(ctx context.Context) error {
+	points, err := s.scrollAll(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("scroll points: %w", err)
+	}
+
+	legacy := make(map[string][]*qdrant.RetrievedPoint)
+	for _, point := range points {
+		agentID := point.Payload["id"].GetStringValue()
+		if point.Id.GetUuid() != pointID(agentID).GetUuid() {
+			legacy[agentID] = append(legacy[agentID], point)
+		}
+	}
+
+	for agentID, group := range legacy {
+		if err := s.migrateAgentPoints(ctx, agentID, group); err != nil {
+			return fmt.Errorf("migrate agent %s: %w", agentID, err)
+		}
+	}
+
+	return nil
+}
+
+// migrateAgentPoints copies the newest legacy point of an agent to its derived
+// ID, unless that point already exists, and deletes all legacy points.
+func (s *QdrantStore) migrateAgentPoints(ctx context.Context, agentID string, group []*qdrant.RetrievedPoint) error {
+	found, err := s.exists(ctx, agentID)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		latest := group[0]
+		for _, point := range group[1:] {
+			if point.Payload["updated_at"].GetIntegerValue() > latest.Payload["updated_at"].GetIntegerValue() {
+				latest = point
+			}
+		}
+
+		withVectors, err := s.client.Get(ctx, &qdrant.GetPoints{
+			CollectionName: s.collectionName,
+			Ids:            []*qdrant.PointId{latest.Id},
+			WithVectors:    qdrant.NewWithVectors(true),
+		})
+		if err != nil {
+			return fmt.Errorf("get point: %w", err)
+		}
+		if len(withVectors) == 0 {
+			return fmt.Errorf("point %s disappeared during migration", latest.Id.GetUuid())
+		}
+
+		agent, err := payloadToAgent(agentID, latest.Payload)
+		if err != nil {
+			return fmt.Errorf("parse payload: %w", err)
+		}
+		setEmbeddings(agent, withVectors[0].Vectors, latest.Payload)
+
+		_, err = s.client.Upsert(ctx, &qdrant.UpsertPoints{
+			CollectionName: s.collectionName,
+			Wait:           qdrant.PtrOf(true),
+			Points: []*qdrant.PointStruct{
+				{
+					Id:      pointID(agentID),
+					Vectors: s.pointVectors(agent),
+					Payload: latest.Payload,
+				},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("upsert point: %w", err)
+		}
+	}
+
+	ids := make([]*qdrant.PointId, len(group))
+	for i, point := range group {
+		ids[i] = point.Id
+	}
+	_, err = s.client.Delete(ctx, &qdrant.DeletePoints{
+		CollectionName: s.collectionName,
+		Wait:           qdrant.PtrOf(true),
+		Points:         qdrant.NewPointsSelectorIDs(ids),
 	})
 	if err != nil {
-		return fmt.Errorf("create created_at index: %w", err)
+		return fmt.Errorf("delete legacy points: %w", err)
 	}
 
 	return nil
@@ -219,58 +494,83 @@ func (s *QdrantStore) Close() error {
 	return nil
 }
 
+// pointNamespace is the UUIDv5 namespace for deriving point IDs from agent IDs.
+// Changing it orphans every stored point.
+var pointNamespace = uuid.MustParse("3b8e4f0a-6c1d-4e2b-9f7a-5d0c8b1e2a64")
+
+// pointID returns the stable Qdrant point ID for an agent ID.
+func pointID(agentID string) *qdrant.PointId {
+	return qdrant.NewID(uuid.NewSHA1(pointNamespace, []byte(agentID)).String())
+}
+
 // CreateAgent stores a new agent in Qdrant.
+// The point is inserted only if no point with the same ID exists, and a
+// per-call token in the payload tells whether this call won a concurrent race.
+// The winner removes the token once it has been read back.
 func (s *QdrantStore) CreateAgent(ctx context.Context, agent *RegisteredAgent) error {
-	// Check if agent already exists by searching payload
-	existing, err := s.findPointByAgentID(ctx, agent.ID)
-	if err != nil {
-		return fmt.Errorf("check agent exists: %w", err)
-	}
-	if existing != nil {
-		return ErrAlreadyExists
-	}
-
 	payload, err := agentToPayload(agent)
 	if err != nil {
 		return fmt.Errorf("build payload: %w", err)
 	}
 
-	// Generate random UUID for point ID
-	pointID := uuid.New().String()
+	token := uuid.NewString()
+	payload["create_token"] = qdrant.NewValueString(token)
+	id := pointID(agent.ID)
 
 	_, err = s.client.Upsert(ctx, &qdrant.UpsertPoints{
 		CollectionName: s.collectionName,
 		Wait:           qdrant.PtrOf(true),
 		Points: []*qdrant.PointStruct{
 			{
-				Id:      qdrant.NewID(pointID),
-				Vectors: qdrant.NewVectorsDense(agent.Embedding),
+				Id:      id,
+				Vectors: s.pointVectors(agent),
 				Payload: payload,
 			},
 		},
+		// Existing points never match, so they are left untouched.
+		UpdateFilter: &qdrant.Filter{
+			MustNot: []*qdrant.Condition{qdrant.NewHasID(id)},
+		},
 	})
 	if err != nil {
 		return fmt.Errorf("upsert point: %w", err)
 	}
 
+	points, err := s.client.Get(ctx, &qdrant.GetPoints{
+		CollectionName: s.collectionName,
+		Ids:            []*qdrant.PointId{id},
+		WithPayload:    qdrant.NewWithPayloadInclude("create_token"),
+	})
+	if err != nil {
+		return fmt.Errorf("get point: %w", err)
+	}
+	if len(points) == 0 || points[0].Payload["create_token"].GetStringValue() != token {
+		return ErrAlreadyExists
+	}
+
+	_, err = s.client.DeletePayload(ctx, &qdrant.DeletePayloadPoints{
+		CollectionName: s.collectionName,
+		Wait:           qdrant.PtrOf(true),
+		Keys:           []string{"create_token"},
+		PointsSelector: qdrant.NewPointsSelector(id),
+	})
+	if err != nil {
+		return fmt.Errorf("delete payload: %w", err)
+	}
+
 	return nil
 }
 
-// findPointByAgentID searches for a point by agent ID in payload.
-func (s *QdrantStore) findPointByAgentID(ctx context.Context, agentID string) (*qdrant.RetrievedPoint, error) {
-	points, err := s.client.Scroll(ctx, &qdrant.ScrollPoints{
+// getPoint retrieves the point of an agent, nil if it does not exist.
+func (s *QdrantStore) getPoint(ctx context.Context, agentID string, withVectors bool) (*qdrant.RetrievedPoint, error) {
+	points, err := s.client.Get(ctx, &qdrant.GetPoints{
 		CollectionName: s.collectionName,
-		Filter: &qdrant.Filter{
-			Must: []*qdrant.Condition{
-				qdrant.NewMatch("id", agentID),
-			},
-		},
-		Limit:       qdrant.PtrOf(uint32(1)),
-		WithPayload: qdrant.NewWithPayload(true),
-		WithVectors: qdrant.NewWithVectors(true),
+		Ids:            []*qdrant.PointId{pointID(agentID)},
+		WithPayload:    qdrant.NewWithPayload(withVectors),
+		WithVectors:    qdrant.NewWithVectors(withVectors),
 	})
 	if err != nil {
-		return nil, fmt.Errorf("scroll: %w", err)
+		return nil, fmt.Errorf("get point: %w", err)
 	}
 	if len(points) == 0 {
 		return nil, nil
@@ -278,9 +578,18 @@ func (s *QdrantStore) findPointByAgentID(ctx context.Context, agentID string) (*
 	return points[0], nil
 }
 
+// exists reports whether an agent is stored.
+func (s *QdrantStore) exists(ctx context.Context, agentID string) (bool, error) {
+	point, err := s.getPoint(ctx, agentID, false)
+	if err != nil {
+		return false, err
+	}
+	return point != nil, nil
+}
+
 // GetAgent retrieves an agent by ID from Qdrant.
 func (s *QdrantStore) GetAgent(ctx context.Context, id string) (*RegisteredAgent, error) {
-	point, err := s.findPointByAgentID(ctx, id)
+	point, err := s.getPoint(ctx, id, true)
 	if err != nil {
 		return nil, fmt.Errorf("find agent: %w", err)
 	}
@@ -292,29 +601,149 @@ func (s *QdrantStore) GetAgent(ctx context.Context, id string) (*RegisteredAgent
 	if err != nil {
 		return nil, fmt.Errorf("parse payload: %w", err)
 	}
-
-	if point.Vectors != nil {
-		if vec := point.Vectors.GetVector(); vec != nil {
-			if dense := vec.GetDense(); dense != nil {
-				agent.Embedding = dense.GetData()
-			}
-		}
-	}
+	setEmbeddings(agent, point.Vectors, point.Payload)
 
 	return agent, nil
 }
 
 // ListAgents returns agents matching the filter criteria.
+// Timestamp sorts with a limit are pushed down to Qdrant using the range
+// indexes; name sorts and unlimited listings read every match and sort in Go.
 func (s *QdrantStore) ListAgents(ctx context.Context, filter AgentFilter) (*AgentListResult, error) {
+	field, order, err := normalizeSort(filter)
+	if err != nil {
+		return nil, err
+	}
 	qdrantFilter := buildFilter(filter)
 
-	// Scroll through all matching results
-	points, err := s.scrollAll(ctx, qdrantFilter)
+	if field == SortByName || filter.Limit <= 0 {
+		agents, err := s.scrollAgents(ctx, qdrantFilter)
+		if err != nil {
+			return nil, err
+		}
+		page, next, err := pageAgents(agents, filter)
+		if err != nil {
+			return nil, err
+		}
+		return &AgentListResult{Agents: page, Total: len(agents), NextCursor: next}, nil
+	}
+
+	var last *listPosition
+	skip := max(filter.Offset, 0)
+	if filter.Cursor != "" {
+		c, err := decodeCursor(filter.Cursor, field, order)
+		if err != nil {
+			return nil, err
+		}
+		last = &c.Last
+		skip = 0
+	}
+
+	total, err := s.client.Count(ctx, &qdrant.CountPoints{
+		CollectionName: s.collectionName,
+		Filter:         qdrantFilter,
+		Exact:          qdrant.PtrOf(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("count points: %w", err)
+	}
+
+	agents, err := s.listOrdered(ctx, qdrantFilter, field, order, last, skip+filter.Limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := agents[min(skip, len(agents)):]
+	var next string
+	if len(page) > filter.Limit {
+		page = page[:filter.Limit]
+		next = encodeCursor(page[len(page)-1], field, order)
+	}
+
+	return &AgentListResult{Agents: page, Total: int(total), NextCursor: next}, nil
+}
+
+// listOrdered returns up to n agents after last, ordered by a timestamp
+// field. Qdrant cannot break ties between equal timestamps, so agents sharing
+// the timestamp at either end of the range are read in full and ordered by
+// ID in Go. Timestamps are stored in whole seconds.
+func (s *QdrantStore) listOrdered(ctx context.Context, filter *qdrant.Filter, field SortField, order SortOrder, last *listPosition, n int) ([]*RegisteredAgent, error) {
+	key := string(field)
+	var agents []*RegisteredAgent
+
+	rest := filter
+	if last != nil {
+		seconds := last.Time / int64(time.Second)
+		tied, err := s.scrollAgents(ctx, withConditions(filter, qdrant.NewMatchInt(key, seconds)))
+		if err != nil {
+			return nil, err
+		}
+		sortAgents(tied, field, order)
+		for _, agent := range tied {
+			if comparePositions(positionOf(agent, field), *last, field, order) > 0 {
+				agents = append(agents, agent)
+			}
+		}
+
+		bound := &qdrant.Range{Lt: qdrant.PtrOf(float64(seconds))}
+		if order == SortAsc {
+			bound = &qdrant.Range{Gt: qdrant.PtrOf(float64(seconds))}
+		}
+		rest = withConditions(filter, qdrant.NewRange(key, bound))
+	}
+
+	if len(agents) >= n {
+		return agents[:n], nil
+	}
+
+	direction := qdrant.Direction_Desc
+	if order == SortAsc {
+		direction = qdrant.Direction_Asc
+	}
+	want := n - len(agents)
+	points, err := s.client.Scroll(ctx, &qdrant.ScrollPoints{
+		CollectionName: s.collectionName,
+		Filter:         rest,
+		Limit:          qdrant.PtrOf(uint32(want)),
+		OrderBy:        &qdrant.OrderBy{Key: key, Direction: &direction},
+		WithPayload:    qdrant.NewWithPayload(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scroll ordered: %w", err)
+	}
+	batch, err := pointsToAgents(points)
+	if err != nil {
+		return nil, err
+	}
+
+	// A full batch may end partway through a run of equal timestamps.
+	if len(batch) == want {
+		edge := positionOf(batch[len(batch)-1], field).Time / int64(time.Second)
+		batch = slices.DeleteFunc(batch, func(agent *RegisteredAgent) bool {
+			return positionOf(agent, field).Time/int64(time.Second) == edge
+		})
+		tied, err := s.scrollAgents(ctx, withConditions(rest, qdrant.NewMatchInt(key, edge)))
+		if err != nil {
+			return nil, err
+		}
+		batch = append(batch, tied...)
+	}
+	sortAgents(batch, field, order)
+
+	return append(agents, batch...), nil
+}
+
+// scrollAgents fetches all agents matching the filter.
+func (s *QdrantStore) scrollAgents(ctx context.Context, filter *qdrant.Filter) ([]*RegisteredAgent, error) {
+	points, err := s.scrollAll(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("scroll points: %w", err)
 	}
+	return pointsToAgents(points)
+}
 
-	// Convert to agents
+// pointsToAgents converts retrieved points to agents without embeddings.
+func pointsToAgents(points []*qdrant.RetrievedPoint) ([]*RegisteredAgent, error) {
 	agents := make([]*RegisteredAgent, 0, len(points))
 	for _, point := range points {
 		id := point.Payload["id"].GetStringValue()
@@ -324,42 +753,33 @@ func (s *QdrantStore) ListAgents(ctx context.Context, filter AgentFilter) (*Agen
 		}
 		agents = append(agents, agent)
 	}
+	return agents, nil
+}
 
-	// Sort by CreatedAt descending (matching memory.go behavior)
-	sort.Slice(agents, func(i, j int) bool {
-		return agents[i].CreatedAt.After(agents[j].CreatedAt)
-	})
-
-	total := len(agents)
-
-	// Apply pagination
-	if filter.Limit == 0 {
-		return &AgentListResult{
-			Agents: []*RegisteredAgent{},
-			Total:  total,
-		}, nil
+// withConditions returns a copy of filter with extra required conditions.
+func withConditions(filter *qdrant.Filter, conditions ...*qdrant.Condition) *qdrant.Filter {
+	if filter == nil {
+		return &qdrant.Filter{Must: conditions}
+	}
+	return &qdrant.Filter{
+		Must:    append(slices.Clone(filter.Must), conditions...),
+		MustNot: filter.MustNot,
 	}
-
-	start := min(filter.Offset, len(agents))
-	end := min(start+filter.Limit, len(agents))
-
-	return &AgentListResult{
-		Agents: agents[start:end],
-		Total:  total,
-	}, nil
 }
 
 // scrollAll fetches all matching points from the collection.
 func (s *QdrantStore) scrollAll(ctx context.Context, filter *qdrant.Filter) ([]*qdrant.RetrievedPoint, error) {
 	batchSize := uint32(100)
 	var allPoints []*qdrant.RetrievedPoint
-	var lastID *qdrant.PointId
+	var offset *qdrant.PointId
 
 	for {
-		resp, err := s.client.Scroll(ctx, &qdrant.ScrollPoints{
+		// The offset is inclusive, so continue from the ID Qdrant reports as
+		// the start of the next page rather than the last ID seen.
+		resp, next, err := s.client.ScrollAndOffset(ctx, &qdrant.ScrollPoints{
 			CollectionName: s.collectionName,
 			Filter:         filter,
-			Offset:         lastID,
+			Offset:         offset,
 			Limit:          qdrant.PtrOf(batchSize),
 			WithPayload:    qdrant.NewWithPayload(true),
 		})
@@ -369,11 +789,11 @@ func (s *QdrantStore) scrollAll(ctx context.Context, filter *qdrant.Filter) ([]*
 
 		allPoints = append(allPoints, resp...)
 
-		if len(resp) < int(batchSize) {
+		if next == nil {
 			break
 		}
 
-		lastID = resp[len(resp)-1].Id
+		offset = next
 	}
 
 	return allPoints, nil
@@ -381,12 +801,17 @@ func (s *QdrantStore) scrollAll(ctx context.Context, filter *qdrant.Filter) ([]*
 
 // UpdateAgent updates an existing agent in Qdrant.
 func (s *QdrantStore) UpdateAgent(ctx context.Context, agent *RegisteredAgent) error {
-	// Find existing point
-	point, err := s.findPointByAgentID(ctx, agent.ID)
+	// The stored health and lease expiry are read back just before the write
+	// and kept, rather than the possibly stale ones the caller read.
+	points, err := s.client.Get(ctx, &qdrant.GetPoints{
+		CollectionName: s.collectionName,
+		Ids:            []*qdrant.PointId{pointID(agent.ID)},
+		WithPayload:    qdrant.NewWithPayloadInclude(stateFields...),
+	})
 	if err != nil {
 		return fmt.Errorf("find agent: %w", err)
 	}
-	if point == nil {
+	if len(points) == 0 {
 		return ErrNotFound
 	}
 
@@ -394,15 +819,15 @@ func (s *QdrantStore) UpdateAgent(ctx context.Context, agent *RegisteredAgent) e
 	if err != nil {
 		return fmt.Errorf("build payload: %w", err)
 	}
+	maps.Copy(payload, points[0].Payload)
 
-	// Reuse existing point ID
 	_, err = s.client.Upsert(ctx, &qdrant.UpsertPoints{
 		CollectionName: s.collectionName,
 		Wait:           qdrant.PtrOf(true),
 		Points: []*qdrant.PointStruct{
 			{
-				Id:      point.Id,
-				Vectors: qdrant.NewVectorsDense(agent.Embedding),
+				Id:      pointID(agent.ID),
+				Vectors: s.pointVectors(agent),
 				Payload: payload,
 			},
 		},
@@ -416,19 +841,18 @@ func (s *QdrantStore) UpdateAgent(ctx context.Context, agent *RegisteredAgent) e
 
 // DeleteAgent removes an agent from Qdrant.
 func (s *QdrantStore) DeleteAgent(ctx context.Context, id string) error {
-	// Find existing point
-	point, err := s.findPointByAgentID(ctx, id)
+	found, err := s.exists(ctx, id)
 	if err != nil {
 		return fmt.Errorf("find agent: %w", err)
 	}
-	if point == nil {
+	if !found {
 		return ErrNotFound
 	}
 
 	_, err = s.client.Delete(ctx, &qdrant.DeletePoints{
 		CollectionName: s.collectionName,
 		Wait:           qdrant.PtrOf(true),
-		Points:         qdrant.NewPointsSelector(point.Id),
+		Points:         qdrant.NewPointsSelector(pointID(id)),
 	})
 	if err != nil {
 		return fmt.Errorf("delete point: %w", err)
@@ -437,13 +861,149 @@ func (s *QdrantStore) DeleteAgent(ctx context.Context, id string) error {
 	return nil
 }
 
+// SetAgentHealth replaces the health payload fields of an agent.
+func (s *QdrantStore) SetAgentHealth(ctx context.Context, id string, health AgentHealth) error {
+	found, err := s.exists(ctx, id)
+	if err != nil {
+		return fmt.Errorf("find agent: %w", err)
+	}
+	if !found {
+		return ErrNotFound
+	}
+
+	_, err = s.client.SetPayload(ctx, &qdrant.SetPayloadPoints{
+		CollectionName: s.collectionName,
+		Wait:           qdrant.PtrOf(true),
+		Payload:        qdrant.NewValueMap(healthToPayload(health)),
+		PointsSelector: qdrant.NewPointsSelector(pointID(id)),
+	})
+	if err != nil {
+		return fmt.Errorf("set payload: %w", err)
+	}
+
+	return nil
+}
+
+// RenewAgentLease sets the lease expiry payload field of an agent.
+func (s *QdrantStore) RenewAgentLease(ctx context.Context, id string, expiresAt time.Time) error {
+	found, err := s.exists(ctx, id)
+	if err != nil {
+		return fmt.Errorf("find agent: %w", err)
+	}
+	if !found {
+		return ErrNotFound
+	}
+
+	_, err = s.client.SetPayload(ctx, &qdrant.SetPayloadPoints{
+		CollectionName: s.collectionName,
+		Wait:           qdrant.PtrOf(true),
+		Payload:        qdrant.NewValueMap(map[string]any{"expires_at": unixOrZero(expiresAt)}),
+		PointsSelector: qdrant.NewPointsSelector(pointID(id)),
+	})
+	if err != nil {
+		return fmt.Errorf("set payload: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredAgents removes agents whose lease expired at or before now.
+// The delete selects points by the expiry filter rather than by the IDs found
+// beforehand, so that an agent renewed in between is kept, and only the agents
+// found that no longer exist afterwards are reported.
+func (s *QdrantStore) DeleteExpiredAgents(ctx context.Context, now time.Time) ([]string, error) {
+	// expires_at is zero for agents without a lease.
+	expired := &qdrant.Filter{
+		Must: []*qdrant.Condition{
+			qdrant.NewRange("expires_at", &qdrant.Range{
+				Gt:  qdrant.PtrOf(0.0),
+				Lte: qdrant.PtrOf(float64(now.Unix())),
+			}),
+		},
+	}
+
+	points, err := s.scrollAll(ctx, expired)
+	if err != nil {
+		return nil, fmt.Errorf("scroll expired points: %w", err)
+	}
+	if len(points) == 0 {
+		return nil, nil
+	}
+
+	_, err = s.client.Delete(ctx, &qdrant.DeletePoints{
+		CollectionName: s.collectionName,
+		Wait:           qdrant.PtrOf(true),
+		Points:         qdrant.NewPointsSelectorFilter(expired),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("delete points: %w", err)
+	}
+
+	pointIDs := make([]*qdrant.PointId, len(points))
+	for i, point := range points {
+		pointIDs[i] = point.Id
+	}
+	kept, err := s.client.Get(ctx, &qdrant.GetPoints{
+		CollectionName: s.collectionName,
+		Ids:            pointIDs,
+		WithPayload:    qdrant.NewWithPayload(false),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get points: %w", err)
+	}
+	renewed := make(map[string]bool, len(kept))
+	for _, point := range kept {
+		renewed[point.Id.GetUuid()] = true
+	}
+
+	ids := make([]string, 0, len(points))
+	for _, point := range points {
+		if !renewed[point.Id.GetUuid()] {
+			ids = append(ids, point.Payload["id"].GetStringValue())
+		}
+	}
+	return ids, nil
+}
+
+// maxTagFacets bounds the number of distinct tags CountAgents reports.
+const maxTagFacets = 1000
+
+// CountAgents returns the number of agents in total and per tag from the
+// point count and the tags index, without reading any point.
+func (s *QdrantStore) CountAgents(ctx context.Context) (int, map[string]int, error) {
+	total, err := s.countPoints(ctx, s.collectionName)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	hits, err := s.client.Facet(ctx, &qdrant.FacetCounts{
+		CollectionName: s.collectionName,
+		Key:            "tags",
+		Limit:          qdrant.PtrOf(uint64(maxTagFacets)),
+		Exact:          qdrant.PtrOf(true),
+	})
+	if err != nil {
+		return 0, nil, fmt.Errorf("facet tags: %w", err)
+	}
+	byTag := make(map[string]int, len(hits))
+	for _, hit := range hits {
+		byTag[hit.GetValue().GetStringValue()] = int(hit.GetCount())
+	}
+	return int(total), byTag, nil
+}
+
 // SearchAgents finds agents by vector similarity with optional filtering.
 func (s *QdrantStore) SearchAgents(ctx context.Context, query []float32, limit int, filter AgentFilter) (*SearchResult, error) {
 	qdrantFilter := buildFilter(filter)
 
+	nearest := qdrant.NewQueryDense(query)
+	if s.multi.Load() {
+		nearest = qdrant.NewQueryMulti([][]float32{query})
+	}
+
 	resp, err := s.client.Query(ctx, &qdrant.QueryPoints{
 		CollectionName: s.collectionName,
-		Query:          qdrant.NewQueryDense(query),
+		Query:          nearest,
 		Limit:          qdrant.PtrOf(uint64(limit)),
 		Filter:         qdrantFilter,
 		WithPayload:    qdrant.NewWithPayload(true),
@@ -453,31 +1013,131 @@ func (s *QdrantStore) SearchAgents(ctx context.Context, query []float32, limit i
 		return nil, fmt.Errorf("query: %w", err)
 	}
 
-	agents := make([]ScoredAgent, 0, len(resp))
-	for _, point := range resp {
+	return scoredPointsToResult(resp, query)
+}
+
+// scoredPointsToResult converts query results to a SearchResult. If query is
+// set, each agent reports the skill whose embedding matched it best.
+func scoredPointsToResult(points []*qdrant.ScoredPoint, query []float32) (*SearchResult, error) {
+	agents := make([]ScoredAgent, 0, len(points))
+	for _, point := range points {
 		id := point.Payload["id"].GetStringValue()
 		agent, err := payloadToAgent(id, point.Payload)
 		if err != nil {
 			return nil, fmt.Errorf("parse payload for %s: %w", id, err)
 		}
 
-		if point.Vectors != nil {
-			if vec := point.Vectors.GetVector(); vec != nil {
-				if dense := vec.GetDense(); dense != nil {
-					agent.Embedding = dense.GetData()
-				}
-			}
-		}
+		setEmbeddings(agent, point.Vectors, point.Payload)
 
-		agents = append(agents, ScoredAgent{
+		scored := ScoredAgent{
 			Agent: agent,
 			Score: point.Score,
-		})
+		}
+		if query != nil {
+			_, scored.MatchedSkill = bestMatch(query, agent)
+		}
+		agents = append(agents, scored)
 	}
 
 	return &SearchResult{Agents: agents}, nil
 }
 
+// pointVectors returns the vectors stored for an agent: its embedding, followed
+// by its skill embeddings if the collection holds multivectors, as the default
+// vector and, if the collection supports it, its BM25 term weights.
+func (s *QdrantStore) pointVectors(agent *RegisteredAgent) *qdrant.Vectors {
+	embedding := qdrant.NewVectorDense(agent.Embedding)
+	if s.multi.Load() && len(agent.Embedding) > 0 {
+		_, skills := skillVectors(agent)
+		embedding = qdrant.NewVectorMulti(append([][]float32{agent.Embedding}, skills...))
+	}
+
+	indices, values := lexicalVector(agent)
+	if !s.lexical.Load() || len(indices) == 0 {
+		return &qdrant.Vectors{VectorsOptions: &qdrant.Vectors_Vector{Vector: embedding}}
+	}
+	return qdrant.NewVectorsMap(map[string]*qdrant.Vector{
+		"":                embedding,
+		lexicalVectorName: qdrant.NewVectorSparse(indices, values),
+	})
+}
+
+// skillVectors returns the IDs and embeddings of the agent's embedded skills in card order.
+func skillVectors(agent *RegisteredAgent) ([]string, [][]float32) {
+	var ids []string
+	var vectors [][]float32
+	for _, skill := range agent.Card.Skills {
+		if vector, ok := agent.SkillEmbeddings[skill.ID]; ok {
+			ids = append(ids, skill.ID)
+			vectors = append(vectors, vector)
+		}
+	}
+	return ids, vectors
+}
+
+// LexicalSearchAgents finds agents by BM25 keyword relevance using the sparse
+// term vectors, with optional filtering.
+func (s *QdrantStore) LexicalSearchAgents(ctx context.Context, query string, limit int, filter AgentFilter) (*SearchResult, error) {
+	if !s.lexical.Load() {
+		return nil, ErrLexicalUnsupported
+	}
+
+	terms := queryTerms(query)
+	if len(terms) == 0 {
+		return &SearchResult{Agents: []ScoredAgent{}}, nil
+	}
+	ones := make([]float32, len(terms))
+	for i := range ones {
+		ones[i] = 1
+	}
+
+	resp, err := s.client.Query(ctx, &qdrant.QueryPoints{
+		CollectionName: s.collectionName,
+		Query:          qdrant.NewQuerySparse(terms, ones),
+		Using:          qdrant.PtrOf(lexicalVectorName),
+		Limit:          qdrant.PtrOf(uint64(limit)),
+		Filter:         buildFilter(filter),
+		WithPayload:    qdrant.NewWithPayload(true),
+		WithVectors:    qdrant.NewWithVectors(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return scoredPointsToResult(resp, nil)
+}
+
+// setEmbeddings sets the agent and skill embeddings of agent from the default
+// vector of a point. Multivector rows after the first are the skill
+// embeddings in the order of the vector_skill_ids payload field.
+func setEmbeddings(agent *RegisteredAgent, vectors *qdrant.VectorsOutput, payload map[string]*qdrant.Value) {
+	vector := vectors.GetVector()
+	if vector == nil {
+		vector = vectors.GetVectors().GetVectors()[""]
+	}
+
+	if dense := vector.GetDense(); dense != nil {
+		agent.Embedding = dense.GetData()
+		return
+	}
+
+	rows := vector.GetMultiDense().GetVectors()
+	if len(rows) == 0 {
+		return
+	}
+	agent.Embedding = rows[0].GetData()
+
+	skillIDs := payload["vector_skill_ids"].GetListValue().GetValues()
+	n := min(len(rows)-1, len(skillIDs))
+	if n == 0 {
+		return
+	}
+	agent.SkillEmbeddings = make(map[string][]float32, n)
+	for i := range n {
+		agent.SkillEmbeddings[skillIDs[i].GetStringValue()] = rows[i+1].GetData()
+	}
+}
+
 // agentToPayload converts a RegisteredAgent to Qdrant payload.
 func agentToPayload(agent *RegisteredAgent) (map[string]*qdrant.Value, error) {
 	cardJSON, err := json.Marshal(agent.Card)
@@ -490,6 +1150,12 @@ func agentToPayload(agent *RegisteredAgent) (map[string]*qdrant.Value, error) {
 		skillIDs[i] = skill.ID
 	}
 
+	embeddedIDs, _ := skillVectors(agent)
+	vectorSkillIDs := make([]any, len(embeddedIDs))
+	for i, id := range embeddedIDs {
+		vectorSkillIDs[i] = id
+	}
+
 	tags := make([]any, len(agent.Tags))
 	for i, tag := range agent.Tags {
 		tags[i] = tag
@@ -502,13 +1168,64 @@ func agentToPayload(agent *RegisteredAgent) (map[string]*qdrant.Value, error) {
 		"card_description": agent.Card.Description,
 		"tags":             tags,
 		"skill_ids":        skillIDs,
+		"vector_skill_ids": vectorSkillIDs,
+		"source_url":       agent.SourceURL,
 		"created_at":       agent.CreatedAt.Unix(),
 		"updated_at":       agent.UpdatedAt.Unix(),
+		"lease_ttl_ms":     agent.LeaseTTL.Milliseconds(),
+		"expires_at":       unixOrZero(agent.ExpiresAt),
 	}
+	maps.Copy(payload, healthToPayload(agent.Health))
 
 	return qdrant.NewValueMap(payload), nil
 }
 
+// stateFields are the payload fields of the health and lease expiry, which
+// only SetAgentHealth and RenewAgentLease change.
+var stateFields = []string{
+	"health_status", "health_last_seen", "health_last_checked",
+	"health_failures", "health_latency_ms", "health_error", "expires_at",
+}
+
+// healthToPayload converts AgentHealth to Qdrant payload fields.
+// Timestamps are stored as Unix seconds, zero if never set.
+func healthToPayload(health AgentHealth) map[string]any {
+	return map[string]any{
+		"health_status":       string(health.Status),
+		"health_last_seen":    unixOrZero(health.LastSeen),
+		"health_last_checked": unixOrZero(health.LastChecked),
+		"health_failures":     int64(health.ConsecutiveFailures),
+		"health_latency_ms":   health.Latency.Milliseconds(),
+		"health_error":        health.LastError,
+	}
+}
+
+// payloadToHealth converts Qdrant payload fields to AgentHealth.
+func payloadToHealth(payload map[string]*qdrant.Value) AgentHealth {
+	return AgentHealth{
+		Status:              HealthStatus(payload["health_status"].GetStringValue()),
+		LastSeen:            timeOrZero(payload["health_last_seen"].GetIntegerValue()),
+		LastChecked:         timeOrZero(payload["health_last_checked"].GetIntegerValue()),
+		ConsecutiveFailures: int(payload["health_failures"].GetIntegerValue()),
+		Latency:             time.Duration(payload["health_latency_ms"].GetIntegerValue()) * time.Millisecond,
+		LastError:           payload["health_error"].GetStringValue(),
+	}
+}
+
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func timeOrZero(unix int64) time.Time {
+	if unix == 0 {
+		return time.Time{}
+	}
+	return time.Unix(unix, 0)
+}
+
 // payloadToAgent converts Qdrant payload to a RegisteredAgent.
 func payloadToAgent(id string, payload map[string]*qdrant.Value) (*RegisteredAgent, error) {
 	cardJSON := payload["card"].GetStringValue()
@@ -534,6 +1251,10 @@ func payloadToAgent(id string, payload map[string]*qdrant.Value) (*RegisteredAge
 		ID:        id,
 		Card:      card,
 		Tags:      tags,
+		SourceURL: payload["source_url"].GetStringValue(),
+		Health:    payloadToHealth(payload),
+		LeaseTTL:  time.Duration(payload["lease_ttl_ms"].GetIntegerValue()) * time.Millisecond,
+		ExpiresAt: timeOrZero(payload["expires_at"].GetIntegerValue()),
 		CreatedAt: createdAt,
 		UpdatedAt: updatedAt,
 	}, nil
@@ -583,9 +1304,14 @@ func buildFilter(filter AgentFilter) *qdrant.Filter {
 		})
 	}
 
-	if len(conditions) == 0 {
+	var mustNot []*qdrant.Condition
+	if filter.ExcludeUnhealthy {
+		mustNot = append(mustNot, qdrant.NewMatch("health_status", string(HealthUnhealthy)))
+	}
+
+	if len(conditions) == 0 && len(mustNot) == 0 {
 		return nil
 	}
 
-	return &qdrant.Filter{Must: conditions}
+	return &qdrant.Filter{Must: conditions, MustNot: mustNot}
 }
@@ -0,0 +1,19 @@
+package store
+
+import "testing"
+
+func TestPointID(t *testing.T) {
+	t.Parallel()
+
+	first := pointID("agent-1").GetUuid()
+
+	if first == "" {
+		t.Fatal("pointID() should return a UUID point ID")
+	}
+	if again := pointID("agent-1").GetUuid(); again != first {
+		t.Errorf("pointID() = %s then %s, want a stable ID", first, again)
+	}
+	if other := pointID("agent-2").GetUuid(); other == first {
+		t.Errorf("pointID() = %s for different agents, want distinct IDs", other)
+	}
+}
@@ -0,0 +1,354 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/qdrant/go-client/qdrant"
+)
+
+// Collection metadata keys tracking a reindex.
+const (
+	// metaReindexTarget names the collection an unfinished reindex of the
+	// served collection is filling.
+	metaReindexTarget = "reindex_target"
+	// metaReindexCursor is the checkpoint of the reindex filling a collection.
+	metaReindexCursor = "reindex_cursor"
+)
+
+// memoryTarget is an in-memory index being filled by a reindex.
+type memoryTarget struct {
+	*MemoryStore
+	// model is the embedding model the index is built for.
+	model string
+	// dim is the embedding dimension the index is built for.
+	dim int
+	// checkpoint is the cursor saved by SaveCheckpoint, guarded by mu.
+	checkpoint string
+}
+
+// Name returns "memory".
+func (t *memoryTarget) Name() string {
+	return "memory"
+}
+
+// Checkpoint returns the saved cursor.
+func (t *memoryTarget) Checkpoint(_ context.Context) (string, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.checkpoint, nil
+}
+
+// SaveCheckpoint saves the cursor.
+func (t *memoryTarget) SaveCheckpoint(_ context.Context, cursor string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.checkpoint = cursor
+	return nil
+}
+
+// ReindexTarget returns an empty in-memory index, or the one of an
+// unfinished reindex for the same model and dimension.
+func (s *MemoryStore) ReindexTarget(_ context.Context, model string, dim int) (ReindexTarget, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.reindex == nil || s.reindex.model != model || s.reindex.dim != dim {
+		s.reindex = &memoryTarget{MemoryStore: NewMemoryStore(), model: model, dim: dim}
+	}
+	return s.reindex, nil
+}
+
+// SwitchIndex replaces the agents of the store with those of target.
+func (s *MemoryStore) SwitchIndex(_ context.Context, target ReindexTarget) (string, error) {
+	t, ok := target.(*memoryTarget)
+	if !ok {
+		return "", fmt.Errorf("reindex target %s is not an in-memory index", target.Name())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	s.agents, s.lexical = t.agents, t.lexical
+	if s.reindex == t {
+		s.reindex = nil
+	}
+	return "", nil
+}
+
+// qdrantTarget is a collection being filled by a reindex. It shares the client
+// of the store it replaces.
+type qdrantTarget struct {
+	*QdrantStore
+}
+
+// Name returns the collection name.
+func (t *qdrantTarget) Name() string {
+	return t.collectionName
+}
+
+// Close is a no-op, the client belongs to the store being reindexed.
+func (t *qdrantTarget) Close() error {
+	return nil
+}
+
+// Checkpoint returns the cursor recorded on the collection.
+func (t *qdrantTarget) Checkpoint(ctx context.Context) (string, error) {
+	info, err := t.client.GetCollectionInfo(ctx, t.collectionName)
+	if err != nil {
+		return "", fmt.Errorf("get collection info: %w", err)
+	}
+	return info.GetConfig().GetMetadata()[metaReindexCursor].GetStringValue(), nil
+}
+
+// SaveCheckpoint records the cursor on the collection.
+func (t *qdrantTarget) SaveCheckpoint(ctx context.Context, cursor string) error {
+	return t.updateMetadata(ctx, t.collectionName, map[string]any{metaReindexCursor: cursor})
+}
+
+// ReindexTarget creates a collection for the given embedding model and
+// dimension and records it on the served collection, so a reindex that is
+// interrupted resumes into it.
+func (s *QdrantStore) ReindexTarget(ctx context.Context, model string, dim int) (ReindexTarget, error) {
+	current, err := s.resolveCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if current == "" {
+		return nil, fmt.Errorf("collection %s not found", s.collectionName)
+	}
+
+	info, err := s.client.GetCollectionInfo(ctx, current)
+	if err != nil {
+		return nil, fmt.Errorf("get collection info: %w", err)
+	}
+	if name := info.GetConfig().GetMetadata()[metaReindexTarget].GetStringValue(); name != "" {
+		target, err := s.openTarget(ctx, name, model, dim)
+		if err != nil || target != nil {
+			return target, err
+		}
+	}
+
+	target := &qdrantTarget{QdrantStore: &QdrantStore{
+		client:         s.client,
+		collectionName: newCollectionName(s.collectionName),
+	}}
+	if err := target.createCollection(ctx, target.collectionName, uint64(dim), model); err != nil {
+		return nil, err
+	}
+	if err := target.ensureIndexes(ctx, target.collectionName); err != nil {
+		return nil, err
+	}
+	if err := s.updateMetadata(ctx, current, map[string]any{metaReindexTarget: target.collectionName}); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// openTarget reopens the collection of an interrupted reindex. It returns nil
+// if the collection is gone, and drops it if it was built for another
+// embedding model or dimension.
+func (s *QdrantStore) openTarget(ctx context.Context, name, model string, dim int) (*qdrantTarget, error) {
+	exists, err := s.client.CollectionExists(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("check collection exists: %w", err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	info, err := s.client.GetCollectionInfo(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("get collection info: %w", err)
+	}
+	size := info.GetConfig().GetParams().GetVectorsConfig().GetParams().GetSize()
+	if size != uint64(dim) || info.GetConfig().GetMetadata()[metaEmbeddingModel].GetStringValue() != model {
+		if err := s.client.DeleteCollection(ctx, name); err != nil {
+			return nil, fmt.Errorf("delete stale reindex collection: %w", err)
+		}
+		return nil, nil
+	}
+
+	target := &qdrantTarget{QdrantStore: &QdrantStore{
+		client:         s.client,
+		collectionName: name,
+	}}
+	target.setCapabilities(info)
+	return target, nil
+}
+
+// SwitchIndex points the store alias at the target collection in a single
+// alias update and returns the collection it replaced, which is kept for
+// rollback. A collection created before aliases is replaced by
+// replaceLegacyCollection, which returns a backup of it instead.
+func (s *QdrantStore) SwitchIndex(ctx context.Context, target ReindexTarget) (string, error) {
+	t, ok := target.(*qdrantTarget)
+	if !ok {
+		return "", fmt.Errorf("reindex target %s is not a qdrant collection", target.Name())
+	}
+
+	current, err := s.resolveCollection(ctx)
+	if err != nil {
+		return "", err
+	}
+	if err := t.SaveCheckpoint(ctx, ""); err != nil {
+		return "", err
+	}
+
+	previous := current
+	if current == s.collectionName {
+		previous, err = s.replaceLegacyCollection(ctx, t)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		err := s.client.UpdateAliases(ctx, []*qdrant.AliasOperations{
+			qdrant.NewAliasDelete(s.collectionName),
+			qdrant.NewAliasCreate(s.collectionName, t.collectionName),
+		})
+		if err != nil {
+			return "", fmt.Errorf("switch alias: %w", err)
+		}
+	}
+
+	s.lexical.Store(t.lexical.Load())
+	s.multi.Store(t.multi.Load())
+	return previous, nil
+}
+
+// replaceLegacyCollection makes the store name an alias of target in place of
+// the collection of that name created before aliases, and returns a backup of
+// that collection. Its name can only become an alias once it is deleted, so
+// the switch is refused unless target holds at least as many agents, and the
+// collection is first copied to the backup. If the alias cannot be created,
+// it points at the backup instead so that the agents stay served. Requests in
+// the moment between deleting the collection and creating the alias fail.
+func (s *QdrantStore) replaceLegacyCollection(ctx context.Context, target *qdrantTarget) (string, error) {
+	legacyCount, err := s.countPoints(ctx, s.collectionName)
+	if err != nil {
+		return "", err
+	}
+	targetCount, err := s.countPoints(ctx, target.collectionName)
+	if err != nil {
+		return "", err
+	}
+	if targetCount < legacyCount {
+		return "", fmt.Errorf("%w: %s holds %d agents, %s holds %d",
+			ErrIncompleteReindex, target.collectionName, targetCount, s.collectionName, legacyCount)
+	}
+
+	backup, err := s.backupCollection(ctx, legacyCount)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.client.DeleteCollection(ctx, s.collectionName); err != nil {
+		return "", fmt.Errorf("delete collection: %w", err)
+	}
+	if err := s.client.CreateAlias(ctx, s.collectionName, target.collectionName); err != nil {
+		if restoreErr := s.client.CreateAlias(ctx, s.collectionName, backup); restoreErr != nil {
+			return "", fmt.Errorf("create alias: %w (serving backup %s failed: %v)", err, backup, restoreErr)
+		}
+		return "", fmt.Errorf("create alias: %w (serving backup %s)", err, backup)
+	}
+	return backup, nil
+}
+
+// backupCollection copies the agents of the store collection, which holds
+// count points, to a new collection for the same embedding model and returns
+// its name.
+func (s *QdrantStore) backupCollection(ctx context.Context, count uint64) (string, error) {
+	info, err := s.client.GetCollectionInfo(ctx, s.collectionName)
+	if err != nil {
+		return "", fmt.Errorf("get collection info: %w", err)
+	}
+	dim := info.GetConfig().GetParams().GetVectorsConfig().GetParams().GetSize()
+	model := info.GetConfig().GetMetadata()[metaEmbeddingModel].GetStringValue()
+
+	backup := &QdrantStore{client: s.client, collectionName: newCollectionName(s.collectionName)}
+	if err := backup.createCollection(ctx, backup.collectionName, dim, model); err != nil {
+		return "", err
+	}
+	if err := backup.ensureIndexes(ctx, backup.collectionName); err != nil {
+		return "", err
+	}
+
+	var offset *qdrant.PointId
+	for {
+		points, next, err := s.client.ScrollAndOffset(ctx, &qdrant.ScrollPoints{
+			CollectionName: s.collectionName,
+			Offset:         offset,
+			Limit:          qdrant.PtrOf(uint32(100)),
+			WithPayload:    qdrant.NewWithPayload(true),
+			WithVectors:    qdrant.NewWithVectors(true),
+		})
+		if err != nil {
+			return "", fmt.Errorf("scroll: %w", err)
+		}
+
+		copies := make([]*qdrant.PointStruct, 0, len(points))
+		for _, point := range points {
+			agentID := point.Payload["id"].GetStringValue()
+			agent, err := payloadToAgent(agentID, point.Payload)
+			if err != nil {
+				return "", fmt.Errorf("parse payload of agent %s: %w", agentID, err)
+			}
+			setEmbeddings(agent, point.Vectors, point.Payload)
+			copies = append(copies, &qdrant.PointStruct{
+				Id:      pointID(agentID),
+				Vectors: backup.pointVectors(agent),
+				Payload: point.Payload,
+			})
+		}
+		if len(copies) > 0 {
+			_, err = s.client.Upsert(ctx, &qdrant.UpsertPoints{
+				CollectionName: backup.collectionName,
+				Wait:           qdrant.PtrOf(true),
+				Points:         copies,
+			})
+			if err != nil {
+				return "", fmt.Errorf("upsert backup points: %w", err)
+			}
+		}
+
+		if next == nil {
+			break
+		}
+		offset = next
+	}
+
+	copied, err := s.countPoints(ctx, backup.collectionName)
+	if err != nil {
+		return "", err
+	}
+	if copied != count {
+		return "", fmt.Errorf("backup %s holds %d agents, want %d", backup.collectionName, copied, count)
+	}
+	return backup.collectionName, nil
+}
+
+// countPoints returns the exact number of points in a collection.
+func (s *QdrantStore) countPoints(ctx context.Context, collection string) (uint64, error) {
+	count, err := s.client.Count(ctx, &qdrant.CountPoints{
+		CollectionName: collection,
+		Exact:          qdrant.PtrOf(true),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("count points: %w", err)
+	}
+	return count, nil
+}
+
+// updateMetadata merges metadata into the collection metadata.
+func (s *QdrantStore) updateMetadata(ctx context.Context, collection string, metadata map[string]any) error {
+	err := s.client.UpdateCollection(ctx, &qdrant.UpdateCollection{
+		CollectionName: collection,
+		Metadata:       qdrant.NewValueMap(metadata),
+	})
+	if err != nil {
+		return fmt.Errorf("update collection metadata: %w", err)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"errors"
+	"time"
 )
 
 // ErrNotFound is returned when a requested agent does not exist.
@@ -11,6 +12,23 @@ var ErrNotFound = errors.New("agent not found")
 // ErrAlreadyExists is returned when creating a duplicate agent.
 var ErrAlreadyExists = errors.New("agent already exists")
 
+// ErrLexicalUnsupported is returned by LexicalSearchAgents when the backend
+// cannot serve keyword search, for example a collection created before it
+// was supported.
+var ErrLexicalUnsupported = errors.New("lexical search not supported")
+
+// ErrInvalidCursor is returned when a list cursor is malformed or was issued
+// for a different sort.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrEmbeddingMismatch is returned when opening a store whose agents were
+// indexed with a different embedding model or dimension than configured.
+var ErrEmbeddingMismatch = errors.New("embedding model or dimension mismatch")
+
+// ErrIncompleteReindex is returned by SwitchIndex when the target holds fewer
+// agents than the index it would replace.
+var ErrIncompleteReindex = errors.New("reindex target is incomplete")
+
 // Store defines the interface for agent storage operations.
 type Store interface {
 	// Ping checks if the storage backend is reachable.
@@ -24,11 +42,27 @@ type Store interface {
 	// ListAgents returns agents matching the filter criteria.
 	ListAgents(ctx context.Context, filter AgentFilter) (*AgentListResult, error)
 	// SearchAgents finds agents by vector similarity with optional filtering.
+	// An agent scores the best similarity of its embedding and its skill embeddings.
 	SearchAgents(ctx context.Context, query []float32, limit int, filter AgentFilter) (*SearchResult, error)
-	// UpdateAgent updates an existing agent. Returns ErrNotFound if not exists.
+	// LexicalSearchAgents finds agents by BM25 keyword relevance over their
+	// name, description and skills. Only agents matching at least one query
+	// word are returned. Returns ErrLexicalUnsupported if unavailable.
+	LexicalSearchAgents(ctx context.Context, query string, limit int, filter AgentFilter) (*SearchResult, error)
+	// UpdateAgent updates an existing agent, keeping its stored health and
+	// lease expiry so that concurrent SetAgentHealth and RenewAgentLease
+	// calls are not undone. Returns ErrNotFound if not exists.
 	UpdateAgent(ctx context.Context, agent *RegisteredAgent) error
 	// DeleteAgent removes an agent. Returns ErrNotFound if not exists.
 	DeleteAgent(ctx context.Context, id string) error
+	// SetAgentHealth replaces the health state of an agent without touching
+	// its card or embedding. Returns ErrNotFound if not exists.
+	SetAgentHealth(ctx context.Context, id string, health AgentHealth) error
+	// RenewAgentLease sets the lease expiry of an agent without touching
+	// its card or embedding. Returns ErrNotFound if not exists.
+	RenewAgentLease(ctx context.Context, id string, expiresAt time.Time) error
+	// DeleteExpiredAgents removes agents whose lease expired at or before now
+	// and returns their IDs. Agents without a lease are never removed.
+	DeleteExpiredAgents(ctx context.Context, now time.Time) ([]string, error)
 }
 
 // HealthChecker provides health check capability for storage backends.
@@ -36,26 +70,93 @@ type HealthChecker interface {
 	Ping(ctx context.Context) error
 }
 
+// AgentCounter is implemented by stores that can count agents without reading
+// them.
+type AgentCounter interface {
+	// CountAgents returns the number of agents in total and per tag.
+	CountAgents(ctx context.Context) (total int, byTag map[string]int, err error)
+}
+
+// Reindexer is implemented by stores that can rebuild their agents into a new
+// index, for example with another embedding model, and switch to it.
+type Reindexer interface {
+	// ReindexTarget creates an empty index for the given embedding model and
+	// dimension, or reopens the one left by an interrupted reindex.
+	ReindexTarget(ctx context.Context, model string, dim int) (ReindexTarget, error)
+	// SwitchIndex atomically makes target the index served by the store and
+	// returns the name of the index it replaced, or of a backup of it, empty
+	// if that was removed. Stores that must remove the replaced index refuse
+	// with ErrIncompleteReindex while target holds fewer agents.
+	SwitchIndex(ctx context.Context, target ReindexTarget) (string, error)
+}
+
+// ReindexTarget is an index being filled by a reindex.
+type ReindexTarget interface {
+	Store
+	// Name identifies the index.
+	Name() string
+	// Checkpoint returns the cursor saved by SaveCheckpoint, empty if none.
+	Checkpoint(ctx context.Context) (string, error)
+	// SaveCheckpoint records the list cursor up to which agents were copied.
+	SaveCheckpoint(ctx context.Context, cursor string) error
+}
+
+// SortField is the agent field a listing is ordered by.
+type SortField string
+
+const (
+	// SortByCreatedAt orders agents by registration time.
+	SortByCreatedAt SortField = "created_at"
+	// SortByUpdatedAt orders agents by last update time.
+	SortByUpdatedAt SortField = "updated_at"
+	// SortByName orders agents by card name, ignoring case.
+	SortByName SortField = "name"
+)
+
+// SortOrder is the direction of a listing.
+type SortOrder string
+
+const (
+	// SortAsc orders from lowest to highest.
+	SortAsc SortOrder = "asc"
+	// SortDesc orders from highest to lowest.
+	SortDesc SortOrder = "desc"
+)
+
 // AgentFilter specifies criteria for listing agents.
 type AgentFilter struct {
-	// Offset is the number of items to skip.
+	// Offset is the number of items to skip, ignored when Cursor is set.
 	Offset int
-	// Limit is the maximum number of items to return.
+	// Limit is the maximum number of items to return, 0 for no limit.
 	Limit int
+	// Cursor continues a listing after the last agent of a previous page.
+	// It must come from AgentListResult.NextCursor with the same sort.
+	Cursor string
+	// Sort is the field to order by, SortByCreatedAt if empty.
+	Sort SortField
+	// Order is the sort direction. If empty, timestamps sort newest first
+	// and names sort alphabetically. Ties are always broken by ascending ID.
+	Order SortOrder
 	// Tags filters by any matching tag.
 	Tags []string
 	// Skills filters by any matching skill ID.
 	Skills []string
-	// Query is a text search in name/description.
+	// Query is a text search in name/description. An agent matches when every
+	// word of the query occurs as a whole word, ignoring case, in its name or in
+	// its description.
 	Query string
+	// ExcludeUnhealthy skips agents whose health status is unhealthy.
+	ExcludeUnhealthy bool
 }
 
 // AgentListResult contains the list result with pagination info.
 type AgentListResult struct {
-	// Agents is the list of matching agents.
+	// Agents is the page of matching agents in sort order, never nil.
 	Agents []*RegisteredAgent
 	// Total is the total count before pagination.
 	Total int
+	// NextCursor continues the listing after this page, empty on the last page.
+	NextCursor string
 }
 
 // SearchResult contains vector search results with similarity scores.
@@ -68,6 +169,14 @@ type SearchResult struct {
 type ScoredAgent struct {
 	// Agent is the matched agent.
 	Agent *RegisteredAgent
-	// Score is the similarity score (0-1, higher is more similar).
+	// Score is the similarity score (0-1, higher is more similar). Lexical
+	// search scores are unbounded BM25 scores.
 	Score float32
+	// MatchedSkill is the ID of the skill whose embedding scored best, empty
+	// if the agent embedding did or the match was lexical.
+	MatchedSkill string
+	// Similarity is the embedding similarity (0-1) to the query in registry
+	// discovery results, whose Score may be a fused hybrid score. It is 0
+	// for agents without embeddings.
+	Similarity float32
 }
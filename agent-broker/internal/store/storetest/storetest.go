@@ -0,0 +1,695 @@
+// Package storetest provides a conformance suite that checks a store.Store
+// implementation against the behavior shared by all backends.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/a2aproject/a2a-go/a2a"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+)
+
+// VectorDimension is the embedding size used by the suite. Factories must
+// return stores accepting vectors of this size.
+const VectorDimension = 4
+
+// Factory returns a new, empty store. It is called once per subtest and
+// should register any cleanup with t.
+type Factory func(t *testing.T) store.Store
+
+// Run runs the conformance suite against stores returned by newStore.
+// Timestamps are compared at second precision.
+func Run(t *testing.T, newStore Factory) {
+	t.Helper()
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T, s store.Store)
+	}{
+		{"CreateAndGet", testCreateAndGet},
+		{"ErrorSentinels", testErrorSentinels},
+		{"UpdateAgent", testUpdateAgent},
+		{"UpdateAgentKeepsHealthAndLease", testUpdateAgentKeepsHealthAndLease},
+		{"DeleteAgent", testDeleteAgent},
+		{"ListEmpty", testListEmpty},
+		{"ListOrderAndPagination", testListOrderAndPagination},
+		{"ListFilters", testListFilters},
+		{"ListSort", testListSort},
+		{"ListCursor", testListCursor},
+		{"SearchOrdering", testSearchOrdering},
+		{"SearchFilters", testSearchFilters},
+		{"SkillSearch", testSkillSearch},
+		{"LexicalSearch", testLexicalSearch},
+		{"HealthAndLease", testHealthAndLease},
+		{"DeleteExpiredAgents", testDeleteExpiredAgents},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tt.run(t, newStore(t))
+		})
+	}
+}
+
+// baseTime is a fixed, whole-second reference time for created agents.
+var baseTime = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// newAgent returns a valid agent created minutesAgo minutes before baseTime.
+func newAgent(id string, minutesAgo int) *store.RegisteredAgent {
+	created := baseTime.Add(-time.Duration(minutesAgo) * time.Minute)
+	return &store.RegisteredAgent{
+		ID: id,
+		Card: a2a.AgentCard{
+			Name:        "Agent " + id,
+			Description: "A conformance test agent",
+			URL:         "http://localhost:9000/" + id,
+			Version:     "1.0.0",
+			Skills:      []a2a.AgentSkill{{ID: "skill-" + id, Name: "Skill " + id}},
+		},
+		Tags:      []string{"test"},
+		Embedding: []float32{0.5, 0.5, 0.5, 0.5},
+		CreatedAt: created,
+		UpdatedAt: created,
+	}
+}
+
+func mustCreate(t *testing.T, s store.Store, agents ...*store.RegisteredAgent) {
+	t.Helper()
+	for _, agent := range agents {
+		if err := s.CreateAgent(context.Background(), agent); err != nil {
+			t.Fatalf("CreateAgent(%s) error = %v", agent.ID, err)
+		}
+	}
+}
+
+func listIDs(agents []*store.RegisteredAgent) []string {
+	ids := make([]string, len(agents))
+	for i, agent := range agents {
+		ids[i] = agent.ID
+	}
+	return ids
+}
+
+func scoredIDs(agents []store.ScoredAgent) []string {
+	ids := make([]string, len(agents))
+	for i, scored := range agents {
+		ids[i] = scored.Agent.ID
+	}
+	return ids
+}
+
+func sameSecond(a, b time.Time) bool {
+	return a.Unix() == b.Unix()
+}
+
+func testCreateAndGet(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	agent := newAgent("a1", 0)
+	agent.Tags = []string{"prod", "ml"}
+	agent.SourceURL = "http://localhost:9000"
+	agent.LeaseTTL = time.Minute
+	agent.ExpiresAt = baseTime.Add(time.Minute)
+	mustCreate(t, s, agent)
+
+	got, err := s.GetAgent(ctx, "a1")
+	if err != nil {
+		t.Fatalf("GetAgent() error = %v", err)
+	}
+
+	if got.ID != "a1" || got.Card.Name != agent.Card.Name || got.Card.Description != agent.Card.Description {
+		t.Errorf("GetAgent() = %s %q, want a1 %q", got.ID, got.Card.Name, agent.Card.Name)
+	}
+	if len(got.Card.Skills) != 1 || got.Card.Skills[0].ID != "skill-a1" {
+		t.Errorf("Skills = %+v, want [skill-a1]", got.Card.Skills)
+	}
+	if !slices.Equal(got.Tags, agent.Tags) {
+		t.Errorf("Tags = %v, want %v", got.Tags, agent.Tags)
+	}
+	if len(got.Embedding) != VectorDimension {
+		t.Errorf("Embedding has %d dimensions, want %d", len(got.Embedding), VectorDimension)
+	}
+	if got.SourceURL != agent.SourceURL || got.LeaseTTL != agent.LeaseTTL {
+		t.Errorf("SourceURL, LeaseTTL = %q, %v, want %q, %v", got.SourceURL, got.LeaseTTL, agent.SourceURL, agent.LeaseTTL)
+	}
+	if !sameSecond(got.CreatedAt, agent.CreatedAt) || !sameSecond(got.ExpiresAt, agent.ExpiresAt) {
+		t.Errorf("CreatedAt, ExpiresAt = %v, %v, want %v, %v", got.CreatedAt, got.ExpiresAt, agent.CreatedAt, agent.ExpiresAt)
+	}
+}
+
+func testErrorSentinels(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	mustCreate(t, s, newAgent("a1", 0))
+
+	checks := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"CreateAgent duplicate", s.CreateAgent(ctx, newAgent("a1", 0)), store.ErrAlreadyExists},
+		{"GetAgent missing", func() error { _, err := s.GetAgent(ctx, "missing"); return err }(), store.ErrNotFound},
+		{"UpdateAgent missing", s.UpdateAgent(ctx, newAgent("missing", 0)), store.ErrNotFound},
+		{"DeleteAgent missing", s.DeleteAgent(ctx, "missing"), store.ErrNotFound},
+		{"SetAgentHealth missing", s.SetAgentHealth(ctx, "missing", store.AgentHealth{}), store.ErrNotFound},
+		{"RenewAgentLease missing", s.RenewAgentLease(ctx, "missing", baseTime), store.ErrNotFound},
+	}
+	for _, check := range checks {
+		if !errors.Is(check.err, check.want) {
+			t.Errorf("%s: error = %v, want %v", check.name, check.err, check.want)
+		}
+	}
+}
+
+func testUpdateAgent(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	mustCreate(t, s, newAgent("a1", 0))
+
+	updated := newAgent("a1", 0)
+	updated.Card.Name = "Renamed"
+	updated.Tags = []string{"prod"}
+	updated.UpdatedAt = baseTime.Add(time.Hour)
+	if err := s.UpdateAgent(ctx, updated); err != nil {
+		t.Fatalf("UpdateAgent() error = %v", err)
+	}
+
+	got, err := s.GetAgent(ctx, "a1")
+	if err != nil {
+		t.Fatalf("GetAgent() error = %v", err)
+	}
+	if got.Card.Name != "Renamed" || !slices.Equal(got.Tags, []string{"prod"}) {
+		t.Errorf("GetAgent() = %q %v, want Renamed [prod]", got.Card.Name, got.Tags)
+	}
+	if !sameSecond(got.UpdatedAt, updated.UpdatedAt) {
+		t.Errorf("UpdatedAt = %v, want %v", got.UpdatedAt, updated.UpdatedAt)
+	}
+
+	result, err := s.ListAgents(ctx, store.AgentFilter{})
+	if err != nil {
+		t.Fatalf("ListAgents() error = %v", err)
+	}
+	if result.Total != 1 {
+		t.Errorf("ListAgents() Total = %d after update, want 1", result.Total)
+	}
+}
+
+func testUpdateAgentKeepsHealthAndLease(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	agent := newAgent("a1", 0)
+	agent.LeaseTTL = time.Minute
+	agent.ExpiresAt = baseTime.Add(time.Minute)
+	mustCreate(t, s, agent)
+
+	stale, err := s.GetAgent(ctx, "a1")
+	if err != nil {
+		t.Fatalf("GetAgent() error = %v", err)
+	}
+	health := store.AgentHealth{Status: store.HealthUnhealthy, ConsecutiveFailures: 2, LastChecked: baseTime}
+	if err := s.SetAgentHealth(ctx, "a1", health); err != nil {
+		t.Fatalf("SetAgentHealth() error = %v", err)
+	}
+	renewed := baseTime.Add(time.Hour)
+	if err := s.RenewAgentLease(ctx, "a1", renewed); err != nil {
+		t.Fatalf("RenewAgentLease() error = %v", err)
+	}
+
+	updated := *stale
+	updated.Card.Name = "Renamed"
+	if err := s.UpdateAgent(ctx, &updated); err != nil {
+		t.Fatalf("UpdateAgent() error = %v", err)
+	}
+
+	got, err := s.GetAgent(ctx, "a1")
+	if err != nil {
+		t.Fatalf("GetAgent() error = %v", err)
+	}
+	if got.Card.Name != "Renamed" {
+		t.Errorf("Card.Name = %q, want Renamed", got.Card.Name)
+	}
+	if got.Health.Status != health.Status || got.Health.ConsecutiveFailures != 2 {
+		t.Errorf("Health = %+v, want %+v", got.Health, health)
+	}
+	if !sameSecond(got.ExpiresAt, renewed) {
+		t.Errorf("ExpiresAt = %v, want %v", got.ExpiresAt, renewed)
+	}
+}
+
+func testDeleteAgent(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	mustCreate(t, s, newAgent("a1", 0), newAgent("a2", 1))
+
+	if err := s.DeleteAgent(ctx, "a1"); err != nil {
+		t.Fatalf("DeleteAgent() error = %v", err)
+	}
+	if _, err := s.GetAgent(ctx, "a1"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("GetAgent() after delete error = %v, want ErrNotFound", err)
+	}
+	if _, err := s.GetAgent(ctx, "a2"); err != nil {
+		t.Errorf("GetAgent(a2) error = %v, other agents should be kept", err)
+	}
+	if err := s.CreateAgent(ctx, newAgent("a1", 0)); err != nil {
+		t.Errorf("CreateAgent() of deleted ID error = %v", err)
+	}
+}
+
+func testListEmpty(t *testing.T, s store.Store) {
+	result, err := s.ListAgents(context.Background(), store.AgentFilter{Limit: 10})
+	if err != nil {
+		t.Fatalf("ListAgents() error = %v", err)
+	}
+	if result.Agents == nil || len(result.Agents) != 0 || result.Total != 0 {
+		t.Errorf("ListAgents() = %v (total %d), want empty non-nil slice", result.Agents, result.Total)
+	}
+}
+
+func testListOrderAndPagination(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	// a0 is the newest agent; b0 shares its creation time and sorts after it by ID.
+	mustCreate(t, s, newAgent("a3", 3), newAgent("a1", 1), newAgent("b0", 0), newAgent("a4", 4), newAgent("a0", 0))
+
+	tests := []struct {
+		name   string
+		filter store.AgentFilter
+		want   []string
+	}{
+		{"no limit returns all newest first", store.AgentFilter{}, []string{"a0", "b0", "a1", "a3", "a4"}},
+		{"offset and limit", store.AgentFilter{Offset: 1, Limit: 2}, []string{"b0", "a1"}},
+		{"offset without limit", store.AgentFilter{Offset: 3}, []string{"a3", "a4"}},
+		{"limit beyond end", store.AgentFilter{Offset: 4, Limit: 10}, []string{"a4"}},
+		{"offset beyond end", store.AgentFilter{Offset: 10, Limit: 10}, []string{}},
+	}
+
+	for _, tt := range tests {
+		result, err := s.ListAgents(ctx, tt.filter)
+		if err != nil {
+			t.Fatalf("%s: ListAgents() error = %v", tt.name, err)
+		}
+		if got := listIDs(result.Agents); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: ListAgents() = %v, want %v", tt.name, got, tt.want)
+		}
+		if result.Total != 5 {
+			t.Errorf("%s: Total = %d, want 5", tt.name, result.Total)
+		}
+	}
+}
+
+func testListFilters(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	translator := newAgent("translator", 0)
+	translator.Card.Name = "Translation Agent"
+	translator.Card.Description = "Translates documents between languages."
+	translator.Card.Skills = []a2a.AgentSkill{{ID: "translate", Name: "Translate"}}
+	translator.Tags = []string{"prod", "nlp"}
+
+	summarizer := newAgent("summarizer", 1)
+	summarizer.Card.Name = "Summarizer"
+	summarizer.Card.Description = "Summarizes long documents."
+	summarizer.Card.Skills = []a2a.AgentSkill{{ID: "summarize", Name: "Summarize"}}
+	summarizer.Tags = []string{"dev"}
+
+	broken := newAgent("broken", 2)
+	broken.Card.Name = "Broken Agent"
+	broken.Tags = []string{"prod"}
+
+	mustCreate(t, s, translator, summarizer, broken)
+	if err := s.SetAgentHealth(ctx, "broken", store.AgentHealth{Status: store.HealthUnhealthy}); err != nil {
+		t.Fatalf("SetAgentHealth() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter store.AgentFilter
+		want   []string
+	}{
+		{"tags match any", store.AgentFilter{Tags: []string{"nlp", "dev"}}, []string{"translator", "summarizer"}},
+		{"skills match any", store.AgentFilter{Skills: []string{"summarize", "unknown"}}, []string{"summarizer"}},
+		{"tags and skills combine", store.AgentFilter{Tags: []string{"prod"}, Skills: []string{"translate"}}, []string{"translator"}},
+		{"query ignores case", store.AgentFilter{Query: "TRANSLATION"}, []string{"translator"}},
+		{"query matches description words", store.AgentFilter{Query: "documents"}, []string{"translator", "summarizer"}},
+		{"query requires every word in one field", store.AgentFilter{Query: "translation agent"}, []string{"translator"}},
+		{"query words split across fields do not match", store.AgentFilter{Query: "summarizer long"}, []string{}},
+		{"query matches whole words only", store.AgentFilter{Query: "transl"}, []string{}},
+		{"query ignores punctuation", store.AgentFilter{Query: "languages"}, []string{"translator"}},
+		{"exclude unhealthy", store.AgentFilter{Tags: []string{"prod"}, ExcludeUnhealthy: true}, []string{"translator"}},
+	}
+
+	for _, tt := range tests {
+		result, err := s.ListAgents(ctx, tt.filter)
+		if err != nil {
+			t.Fatalf("%s: ListAgents() error = %v", tt.name, err)
+		}
+		if got := listIDs(result.Agents); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: ListAgents() = %v, want %v", tt.name, got, tt.want)
+		}
+		if result.Total != len(tt.want) {
+			t.Errorf("%s: Total = %d, want %d", tt.name, result.Total, len(tt.want))
+		}
+	}
+}
+
+func testListSort(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	names := map[string]string{"a1": "charlie", "a2": "Alpha", "a3": "bravo", "a4": "alpha"}
+	for i, id := range []string{"a1", "a2", "a3", "a4"} {
+		agent := newAgent(id, i)
+		agent.Card.Name = names[id]
+		agent.UpdatedAt = baseTime.Add(time.Duration(i) * time.Minute)
+		mustCreate(t, s, agent)
+	}
+
+	tests := []struct {
+		name   string
+		filter store.AgentFilter
+		want   []string
+	}{
+		{"created_at defaults to newest first", store.AgentFilter{Sort: store.SortByCreatedAt}, []string{"a1", "a2", "a3", "a4"}},
+		{"created_at ascending", store.AgentFilter{Sort: store.SortByCreatedAt, Order: store.SortAsc}, []string{"a4", "a3", "a2", "a1"}},
+		{"updated_at defaults to newest first", store.AgentFilter{Sort: store.SortByUpdatedAt}, []string{"a4", "a3", "a2", "a1"}},
+		{"name defaults to alphabetical ignoring case", store.AgentFilter{Sort: store.SortByName}, []string{"a2", "a4", "a3", "a1"}},
+		{"name descending keeps ID tie-break", store.AgentFilter{Sort: store.SortByName, Order: store.SortDesc}, []string{"a1", "a3", "a2", "a4"}},
+		{"name with limit", store.AgentFilter{Sort: store.SortByName, Offset: 1, Limit: 2}, []string{"a4", "a3"}},
+		{"updated_at with limit", store.AgentFilter{Sort: store.SortByUpdatedAt, Order: store.SortAsc, Limit: 2}, []string{"a1", "a2"}},
+	}
+
+	for _, tt := range tests {
+		result, err := s.ListAgents(ctx, tt.filter)
+		if err != nil {
+			t.Fatalf("%s: ListAgents() error = %v", tt.name, err)
+		}
+		if got := listIDs(result.Agents); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: ListAgents() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func testListCursor(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	// Runs of equal timestamps straddle page boundaries.
+	minutes := map[string]int{"a": 0, "b": 0, "c": 0, "d": 1, "e": 1, "f": 2, "g": 3, "h": 3}
+	for id, m := range minutes {
+		mustCreate(t, s, newAgent(id, m))
+	}
+
+	for _, order := range []store.SortOrder{store.SortDesc, store.SortAsc} {
+		for _, limit := range []int{1, 2, 3} {
+			all, err := s.ListAgents(ctx, store.AgentFilter{Order: order})
+			if err != nil {
+				t.Fatalf("ListAgents() error = %v", err)
+			}
+			want := listIDs(all.Agents)
+
+			var got []string
+			filter := store.AgentFilter{Order: order, Limit: limit}
+			for range len(minutes) + 1 {
+				result, err := s.ListAgents(ctx, filter)
+				if err != nil {
+					t.Fatalf("%s/%d: ListAgents() error = %v", order, limit, err)
+				}
+				if result.Total != len(minutes) {
+					t.Errorf("%s/%d: Total = %d, want %d", order, limit, result.Total, len(minutes))
+				}
+				got = append(got, listIDs(result.Agents)...)
+				if result.NextCursor == "" {
+					break
+				}
+				filter.Cursor = result.NextCursor
+			}
+
+			if !slices.Equal(got, want) {
+				t.Errorf("%s/%d: pages = %v, want %v", order, limit, got, want)
+			}
+		}
+	}
+
+	first, err := s.ListAgents(ctx, store.AgentFilter{Limit: 2})
+	if err != nil {
+		t.Fatalf("ListAgents() error = %v", err)
+	}
+	if first.NextCursor == "" {
+		t.Fatal("ListAgents() NextCursor is empty, want a cursor")
+	}
+
+	invalid := []store.AgentFilter{
+		{Limit: 2, Cursor: "not a cursor"},
+		{Limit: 2, Cursor: first.NextCursor, Sort: store.SortByName},
+		{Limit: 2, Cursor: first.NextCursor, Order: store.SortAsc},
+	}
+	for _, filter := range invalid {
+		if _, err := s.ListAgents(ctx, filter); !errors.Is(err, store.ErrInvalidCursor) {
+			t.Errorf("ListAgents(%+v) error = %v, want ErrInvalidCursor", filter, err)
+		}
+	}
+}
+
+func testSearchOrdering(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	vectors := map[string][]float32{
+		"exact":    {1, 0, 0, 0},
+		"close":    {0.9, 0.1, 0, 0},
+		"far":      {0.1, 0.9, 0, 0},
+		"opposite": {0, 0, 1, 0},
+	}
+	for id, vector := range vectors {
+		agent := newAgent(id, 0)
+		agent.Embedding = vector
+		mustCreate(t, s, agent)
+	}
+
+	result, err := s.SearchAgents(ctx, []float32{1, 0, 0, 0}, 3, store.AgentFilter{})
+	if err != nil {
+		t.Fatalf("SearchAgents() error = %v", err)
+	}
+
+	if got, want := scoredIDs(result.Agents), []string{"exact", "close", "far"}; !slices.Equal(got, want) {
+		t.Fatalf("SearchAgents() = %v, want %v", got, want)
+	}
+	if score := result.Agents[0].Score; score < 0.999 || score > 1.001 {
+		t.Errorf("exact match score = %v, want 1", score)
+	}
+	for i := 1; i < len(result.Agents); i++ {
+		if result.Agents[i].Score > result.Agents[i-1].Score {
+			t.Errorf("scores not descending: %v", result.Agents)
+		}
+	}
+	if result.Agents[0].Agent.Card.Name != "Agent exact" {
+		t.Errorf("search hit card = %q, want full agent", result.Agents[0].Agent.Card.Name)
+	}
+}
+
+func testSkillSearch(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	multi := newAgent("multi", 0)
+	multi.Card.Skills = []a2a.AgentSkill{
+		{ID: "weather", Name: "Weather"},
+		{ID: "translate", Name: "Translate"},
+	}
+	multi.Embedding = []float32{1, 0, 0, 0}
+	multi.SkillEmbeddings = map[string][]float32{
+		"weather":   {0, 1, 0, 0},
+		"translate": {0, 0, 1, 0},
+	}
+	plain := newAgent("plain", 0)
+	plain.Embedding = []float32{0.6, 0, 0.8, 0}
+	mustCreate(t, s, multi, plain)
+
+	got, err := s.GetAgent(ctx, "multi")
+	if err != nil {
+		t.Fatalf("GetAgent() error = %v", err)
+	}
+	if len(got.SkillEmbeddings["weather"]) != VectorDimension || len(got.SkillEmbeddings["translate"]) != VectorDimension {
+		t.Errorf("GetAgent() skill embeddings = %v, want weather and translate", got.SkillEmbeddings)
+	}
+
+	tests := []struct {
+		name      string
+		query     []float32
+		wantIDs   []string
+		wantSkill string
+	}{
+		{"agent embedding matches", []float32{1, 0, 0, 0}, []string{"multi", "plain"}, ""},
+		{"skill embedding matches", []float32{0, 0, 1, 0}, []string{"multi", "plain"}, "translate"},
+		{"other skill matches", []float32{0, 1, 0, 0}, []string{"multi", "plain"}, "weather"},
+	}
+	for _, tt := range tests {
+		result, err := s.SearchAgents(ctx, tt.query, 2, store.AgentFilter{})
+		if err != nil {
+			t.Fatalf("%s: SearchAgents() error = %v", tt.name, err)
+		}
+		if got := scoredIDs(result.Agents); !slices.Equal(got, tt.wantIDs) {
+			t.Fatalf("%s: SearchAgents() = %v, want %v", tt.name, got, tt.wantIDs)
+		}
+		top := result.Agents[0]
+		if top.Score < 0.999 || top.Score > 1.001 {
+			t.Errorf("%s: score = %v, want 1", tt.name, top.Score)
+		}
+		if top.MatchedSkill != tt.wantSkill {
+			t.Errorf("%s: MatchedSkill = %q, want %q", tt.name, top.MatchedSkill, tt.wantSkill)
+		}
+		if skill := result.Agents[1].MatchedSkill; skill != "" {
+			t.Errorf("%s: agent without skill embeddings MatchedSkill = %q, want empty", tt.name, skill)
+		}
+	}
+}
+
+func testSearchFilters(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	prod := newAgent("prod", 0)
+	prod.Tags = []string{"prod"}
+	prod.Embedding = []float32{0.8, 0.2, 0, 0}
+	dev := newAgent("dev", 0)
+	dev.Tags = []string{"dev"}
+	dev.Embedding = []float32{1, 0, 0, 0}
+	down := newAgent("down", 0)
+	down.Tags = []string{"prod"}
+	down.Embedding = []float32{1, 0, 0, 0}
+	mustCreate(t, s, prod, dev, down)
+	if err := s.SetAgentHealth(ctx, "down", store.AgentHealth{Status: store.HealthUnhealthy}); err != nil {
+		t.Fatalf("SetAgentHealth() error = %v", err)
+	}
+
+	result, err := s.SearchAgents(ctx, []float32{1, 0, 0, 0}, 10, store.AgentFilter{
+		Tags:             []string{"prod"},
+		ExcludeUnhealthy: true,
+	})
+	if err != nil {
+		t.Fatalf("SearchAgents() error = %v", err)
+	}
+	if got := scoredIDs(result.Agents); !slices.Equal(got, []string{"prod"}) {
+		t.Errorf("SearchAgents() = %v, want [prod]", got)
+	}
+}
+
+func testLexicalSearch(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	ocr := newAgent("ocr", 0)
+	ocr.Card.Name = "Document Reader"
+	ocr.Card.Description = "Reads scanned documents."
+	ocr.Card.Skills = []a2a.AgentSkill{{
+		ID:          "ocr",
+		Name:        "OCR",
+		Description: "Extracts text from scanned images",
+		Examples:    []string{"Read this invoice"},
+	}}
+	ocr.Tags = []string{"prod"}
+
+	writer := newAgent("writer", 0)
+	writer.Card.Name = "Writer"
+	writer.Card.Description = "Drafts documents and emails."
+	writer.Card.Skills = []a2a.AgentSkill{{ID: "draft", Name: "Draft", Tags: []string{"documents"}}}
+	writer.Tags = []string{"dev"}
+
+	mustCreate(t, s, ocr, writer, newAgent("unrelated", 0))
+
+	result, err := s.LexicalSearchAgents(ctx, "OCR of scanned documents", 10, store.AgentFilter{})
+	if errors.Is(err, store.ErrLexicalUnsupported) {
+		t.Skip("store does not support lexical search")
+	}
+	if err != nil {
+		t.Fatalf("LexicalSearchAgents() error = %v", err)
+	}
+	if got := scoredIDs(result.Agents); !slices.Equal(got, []string{"ocr", "writer"}) {
+		t.Errorf("LexicalSearchAgents() = %v, want [ocr writer]", got)
+	}
+	for _, scored := range result.Agents {
+		if scored.Score <= 0 {
+			t.Errorf("score of %s = %v, want positive", scored.Agent.ID, scored.Score)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		query  string
+		filter store.AgentFilter
+		want   []string
+	}{
+		{"skill examples are indexed", "invoice", store.AgentFilter{}, []string{"ocr"}},
+		{"skill tags are indexed", "documents", store.AgentFilter{Tags: []string{"dev"}}, []string{"writer"}},
+		{"no matching words", "weather forecast", store.AgentFilter{}, []string{}},
+		{"empty query", "", store.AgentFilter{}, []string{}},
+	}
+	for _, tt := range tests {
+		result, err := s.LexicalSearchAgents(ctx, tt.query, 10, tt.filter)
+		if err != nil {
+			t.Fatalf("%s: LexicalSearchAgents() error = %v", tt.name, err)
+		}
+		if got := scoredIDs(result.Agents); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: LexicalSearchAgents() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func testHealthAndLease(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	agent := newAgent("a1", 0)
+	agent.LeaseTTL = time.Minute
+	agent.ExpiresAt = baseTime.Add(time.Minute)
+	mustCreate(t, s, agent)
+
+	health := store.AgentHealth{
+		Status:              store.HealthUnhealthy,
+		LastSeen:            baseTime.Add(-time.Hour),
+		LastChecked:         baseTime,
+		ConsecutiveFailures: 3,
+		Latency:             250 * time.Millisecond,
+		LastError:           "connection refused",
+	}
+	if err := s.SetAgentHealth(ctx, "a1", health); err != nil {
+		t.Fatalf("SetAgentHealth() error = %v", err)
+	}
+	renewed := baseTime.Add(time.Hour)
+	if err := s.RenewAgentLease(ctx, "a1", renewed); err != nil {
+		t.Fatalf("RenewAgentLease() error = %v", err)
+	}
+
+	got, err := s.GetAgent(ctx, "a1")
+	if err != nil {
+		t.Fatalf("GetAgent() error = %v", err)
+	}
+	if got.Health.Status != health.Status || got.Health.ConsecutiveFailures != 3 ||
+		got.Health.Latency != health.Latency || got.Health.LastError != health.LastError {
+		t.Errorf("Health = %+v, want %+v", got.Health, health)
+	}
+	if !sameSecond(got.Health.LastSeen, health.LastSeen) || !sameSecond(got.Health.LastChecked, health.LastChecked) {
+		t.Errorf("Health times = %v, %v, want %v, %v", got.Health.LastSeen, got.Health.LastChecked, health.LastSeen, health.LastChecked)
+	}
+	if !sameSecond(got.ExpiresAt, renewed) || got.LeaseTTL != time.Minute {
+		t.Errorf("lease = %v until %v, want %v until %v", got.LeaseTTL, got.ExpiresAt, time.Minute, renewed)
+	}
+	if got.Card.Name != agent.Card.Name || len(got.Embedding) != VectorDimension {
+		t.Error("health and lease updates should keep the card and embedding")
+	}
+}
+
+func testDeleteExpiredAgents(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	expired := newAgent("expired", 0)
+	expired.ExpiresAt = baseTime.Add(-time.Minute)
+	live := newAgent("live", 0)
+	live.ExpiresAt = baseTime.Add(time.Minute)
+	permanent := newAgent("permanent", 0)
+	mustCreate(t, s, expired, live, permanent)
+
+	ids, err := s.DeleteExpiredAgents(ctx, baseTime)
+	if err != nil {
+		t.Fatalf("DeleteExpiredAgents() error = %v", err)
+	}
+	if !slices.Equal(ids, []string{"expired"}) {
+		t.Errorf("DeleteExpiredAgents() = %v, want [expired]", ids)
+	}
+
+	result, err := s.ListAgents(ctx, store.AgentFilter{})
+	if err != nil {
+		t.Fatalf("ListAgents() error = %v", err)
+	}
+	got := listIDs(result.Agents)
+	slices.Sort(got)
+	if !slices.Equal(got, []string{"live", "permanent"}) {
+		t.Errorf("remaining agents = %v, want [live permanent]", got)
+	}
+}
@@ -16,8 +16,60 @@ type RegisteredAgent struct {
 	Tags []string
 	// Embedding is the vector representation for semantic search.
 	Embedding []float32
+	// SkillEmbeddings holds a separate vector for each skill, keyed by skill ID.
+	SkillEmbeddings map[string][]float32
+	// SourceURL is the base URL the card was fetched from, empty if the card was submitted directly.
+	SourceURL string
+	// Health is the liveness state recorded by the health monitor.
+	Health AgentHealth
+	// LeaseTTL is the lease duration renewed by each heartbeat, zero if the registration never expires.
+	LeaseTTL time.Duration
+	// ExpiresAt is when the lease expires, zero if the registration never expires.
+	ExpiresAt time.Time
 	// CreatedAt is when the agent was registered.
 	CreatedAt time.Time
 	// UpdatedAt is when the agent was last updated.
 	UpdatedAt time.Time
 }
+
+// Expired reports whether the agent's lease has expired at now.
+func (a *RegisteredAgent) Expired(now time.Time) bool {
+	return !a.ExpiresAt.IsZero() && !a.ExpiresAt.After(now)
+}
+
+// withStateOf returns a copy of a carrying the health and lease expiry of
+// stored, which are only changed by SetAgentHealth and RenewAgentLease.
+func (a *RegisteredAgent) withStateOf(stored *RegisteredAgent) *RegisteredAgent {
+	updated := *a
+	updated.Health = stored.Health
+	updated.ExpiresAt = stored.ExpiresAt
+	return &updated
+}
+
+// HealthStatus is the liveness state of a registered agent.
+type HealthStatus string
+
+const (
+	// HealthUnknown means the agent has not been probed yet.
+	HealthUnknown HealthStatus = ""
+	// HealthHealthy means the last probe succeeded.
+	HealthHealthy HealthStatus = "healthy"
+	// HealthUnhealthy means the agent failed too many consecutive probes.
+	HealthUnhealthy HealthStatus = "unhealthy"
+)
+
+// AgentHealth holds the result of liveness probes against an agent.
+type AgentHealth struct {
+	// Status is the current liveness state.
+	Status HealthStatus
+	// LastSeen is when the agent last answered a probe.
+	LastSeen time.Time
+	// LastChecked is when the agent was last probed.
+	LastChecked time.Time
+	// ConsecutiveFailures is the number of failed probes since the last success.
+	ConsecutiveFailures int
+	// Latency is the duration of the last successful probe.
+	Latency time.Duration
+	// LastError is the error of the last failed probe.
+	LastError string
+}
@@ -0,0 +1,50 @@
+package telemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/lunarr-ai/lunarr/agent-broker/pkg/embedding"
+)
+
+// tracer creates the spans of instrumented embedders.
+var tracer = otel.Tracer("github.com/lunarr-ai/lunarr/agent-broker/internal/telemetry")
+
+// embedder traces the calls to an Embedder.
+type embedder struct {
+	// next is the traced embedder.
+	next embedding.Embedder
+}
+
+// TraceEmbedder returns e recording a span for each Embed call.
+func TraceEmbedder(e embedding.Embedder) embedding.Embedder {
+	return &embedder{next: e}
+}
+
+// Embed embeds texts with the traced embedder.
+func (e *embedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	ctx, span := tracer.Start(ctx, "Embedder.Embed",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.Int("embedding.texts", len(texts)),
+			attribute.Int("embedding.dimensions", e.next.Dimensions()),
+		),
+	)
+	defer span.End()
+
+	embeddings, err := e.next.Embed(ctx, texts)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return embeddings, err
+}
+
+// Dimensions returns the dimension of the traced embedder.
+func (e *embedder) Dimensions() int {
+	return e.next.Dimensions()
+}
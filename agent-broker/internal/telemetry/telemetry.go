@@ -0,0 +1,257 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// ErrInvalidConfig is returned by Setup for an unknown exporter or protocol
+// or an out of range sample ratio.
+var ErrInvalidConfig = errors.New("invalid tracing config")
+
+// Exporter selects where spans are sent.
+type Exporter string
+
+const (
+	// ExporterNone records no spans. Trace context is still propagated.
+	ExporterNone Exporter = "none"
+	// ExporterOTLP sends spans to an OpenTelemetry collector.
+	ExporterOTLP Exporter = "otlp"
+	// ExporterStdout writes spans as JSON to stdout or File, for local debugging.
+	ExporterStdout Exporter = "stdout"
+)
+
+// Protocol is the transport of the OTLP exporter.
+type Protocol string
+
+const (
+	// ProtocolGRPC sends OTLP over gRPC, by default to localhost:4317.
+	ProtocolGRPC Protocol = "grpc"
+	// ProtocolHTTP sends OTLP as protobuf over HTTP, by default to localhost:4318.
+	ProtocolHTTP Protocol = "http"
+)
+
+// Options configures tracing.
+type Options struct {
+	// ServiceName identifies the broker in traces.
+	ServiceName string
+	// Exporter selects where spans are sent.
+	Exporter Exporter
+	// Protocol is the transport of the OTLP exporter.
+	Protocol Protocol
+	// Endpoint is the OTLP collector as host:port or URL. If empty, the
+	// exporter reads OTEL_EXPORTER_OTLP_ENDPOINT or uses its default.
+	Endpoint string
+	// Insecure disables TLS for the OTLP exporter.
+	Insecure bool
+	// File is the path the stdout exporter appends spans to, stdout if empty.
+	File string
+	// SampleRatio is the fraction of new traces recorded. Traces continued
+	// from a caller follow the caller's sampling decision.
+	SampleRatio float64
+}
+
+// DefaultOptions returns Options with sensible defaults.
+func DefaultOptions() Options {
+	return Options{
+		ServiceName: "agent-broker",
+		Exporter:    ExporterNone,
+		Protocol:    ProtocolGRPC,
+		SampleRatio: 1,
+	}
+}
+
+// Option is a functional option for configuring tracing.
+type Option func(*Options)
+
+// WithServiceName sets the service name reported in traces.
+func WithServiceName(name string) Option {
+	return func(o *Options) {
+		if name != "" {
+			o.ServiceName = name
+		}
+	}
+}
+
+// WithExporter sets where spans are sent.
+func WithExporter(exporter Exporter) Option {
+	return func(o *Options) {
+		o.Exporter = exporter
+	}
+}
+
+// WithProtocol sets the transport of the OTLP exporter.
+func WithProtocol(protocol Protocol) Option {
+	return func(o *Options) {
+		o.Protocol = protocol
+	}
+}
+
+// WithEndpoint sets the OTLP collector endpoint.
+func WithEndpoint(endpoint string) Option {
+	return func(o *Options) {
+		o.Endpoint = endpoint
+	}
+}
+
+// WithInsecure disables TLS for the OTLP exporter.
+func WithInsecure(insecure bool) Option {
+	return func(o *Options) {
+		o.Insecure = insecure
+	}
+}
+
+// WithFile sets the file the stdout exporter appends spans to.
+func WithFile(path string) Option {
+	return func(o *Options) {
+		o.File = path
+	}
+}
+
+// WithSampleRatio sets the fraction of new traces recorded.
+func WithSampleRatio(ratio float64) Option {
+	return func(o *Options) {
+		o.SampleRatio = ratio
+	}
+}
+
+// Setup installs the global W3C trace context and baggage propagators and,
+// unless the exporter is ExporterNone, a global tracer provider exporting
+// spans. The returned function flushes pending spans and releases the
+// exporter; it must be called on shutdown.
+func Setup(ctx context.Context, opts ...Option) (func(context.Context) error, error) {
+	options := DefaultOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.SampleRatio < 0 || options.SampleRatio > 1 {
+		return nil, fmt.Errorf("%w: sample ratio must be between 0 and 1", ErrInvalidConfig)
+	}
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if options.Exporter == ExporterNone {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, closeOutput, err := newExporter(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", options.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("create resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(options.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closeErr := closeOutput(); closeErr != nil {
+			err = errors.Join(err, fmt.Errorf("close trace file: %w", closeErr))
+		}
+		return err
+	}, nil
+}
+
+// newExporter creates the span exporter selected by options and a function
+// closing the file it writes to, if any.
+func newExporter(ctx context.Context, options Options) (sdktrace.SpanExporter, func() error, error) {
+	noClose := func() error { return nil }
+
+	switch options.Exporter {
+	case ExporterOTLP:
+		exporter, err := newOTLPExporter(ctx, options)
+		if err != nil {
+			return nil, nil, err
+		}
+		return exporter, noClose, nil
+	case ExporterStdout:
+		var out io.Writer = os.Stdout
+		closeOutput := noClose
+		stdoutOpts := []stdouttrace.Option{stdouttrace.WithPrettyPrint()}
+		if options.File != "" {
+			f, err := os.OpenFile(options.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+			if err != nil {
+				return nil, nil, fmt.Errorf("open trace file: %w", err)
+			}
+			// One span per line is easier to grep and to load than pretty JSON.
+			out, closeOutput, stdoutOpts = f, f.Close, nil
+		}
+		exporter, err := stdouttrace.New(append(stdoutOpts, stdouttrace.WithWriter(out))...)
+		if err != nil {
+			_ = closeOutput()
+			return nil, nil, fmt.Errorf("create stdout exporter: %w", err)
+		}
+		return exporter, closeOutput, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: unknown exporter %q", ErrInvalidConfig, options.Exporter)
+	}
+}
+
+// newOTLPExporter creates an OTLP exporter over the configured protocol.
+func newOTLPExporter(ctx context.Context, options Options) (sdktrace.SpanExporter, error) {
+	isURL := strings.Contains(options.Endpoint, "://")
+
+	switch options.Protocol {
+	case ProtocolGRPC:
+		var grpcOpts []otlptracegrpc.Option
+		switch {
+		case isURL:
+			grpcOpts = append(grpcOpts, otlptracegrpc.WithEndpointURL(options.Endpoint))
+		case options.Endpoint != "":
+			grpcOpts = append(grpcOpts, otlptracegrpc.WithEndpoint(options.Endpoint))
+		}
+		if options.Insecure {
+			grpcOpts = append(grpcOpts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err := otlptracegrpc.New(ctx, grpcOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("create otlp grpc exporter: %w", err)
+		}
+		return exporter, nil
+	case ProtocolHTTP:
+		var httpOpts []otlptracehttp.Option
+		switch {
+		case isURL:
+			httpOpts = append(httpOpts, otlptracehttp.WithEndpointURL(options.Endpoint))
+		case options.Endpoint != "":
+			httpOpts = append(httpOpts, otlptracehttp.WithEndpoint(options.Endpoint))
+		}
+		if options.Insecure {
+			httpOpts = append(httpOpts, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(ctx, httpOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("create otlp http exporter: %w", err)
+		}
+		return exporter, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown otlp protocol %q", ErrInvalidConfig, options.Protocol)
+	}
+}
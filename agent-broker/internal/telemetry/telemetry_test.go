@@ -0,0 +1,122 @@
+package telemetry
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+)
+
+// failingEmbedder fails every call with err.
+type failingEmbedder struct {
+	err error
+}
+
+func (e failingEmbedder) Embed(context.Context, []string) ([][]float32, error) {
+	return nil, e.err
+}
+
+func (failingEmbedder) Dimensions() int {
+	return 0
+}
+
+// exportedSpan is the part of a span written by the stdout exporter the
+// tests check.
+type exportedSpan struct {
+	Name   string
+	Status struct {
+		Code string
+	}
+}
+
+func TestSetup_InvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{
+			name: "unknown exporter",
+			opts: []Option{WithExporter("jaeger")},
+		},
+		{
+			name: "unknown otlp protocol",
+			opts: []Option{WithExporter(ExporterOTLP), WithProtocol("thrift")},
+		},
+		{
+			name: "sample ratio above one",
+			opts: []Option{WithSampleRatio(1.5)},
+		},
+		{
+			name: "negative sample ratio",
+			opts: []Option{WithSampleRatio(-0.1)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Setup(context.Background(), tt.opts...)
+
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("Setup() error = %v, want %v", err, ErrInvalidConfig)
+			}
+		})
+	}
+}
+
+// TestSetup_StdoutFile installs a global tracer provider and is therefore not
+// parallel.
+func TestSetup_StdoutFile(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	shutdown, err := Setup(context.Background(), WithExporter(ExporterStdout), WithFile(path))
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+
+	embedErr := errors.New("embedder down")
+	_, err = TraceEmbedder(failingEmbedder{err: embedErr}).Embed(context.Background(), []string{"hello"})
+	if !errors.Is(err, embedErr) {
+		t.Fatalf("Embed() error = %v, want %v", err, embedErr)
+	}
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown() error = %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open trace file: %v", err)
+	}
+	defer f.Close()
+
+	var spans []exportedSpan
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var span exportedSpan
+		if err := json.Unmarshal(scanner.Bytes(), &span); err != nil {
+			t.Fatalf("trace file line %q is not JSON: %v", scanner.Text(), err)
+		}
+		spans = append(spans, span)
+	}
+
+	if len(spans) != 1 {
+		t.Fatalf("trace file has %d spans, want 1", len(spans))
+	}
+	if spans[0].Name != "Embedder.Embed" {
+		t.Errorf("span name = %q, want %q", spans[0].Name, "Embedder.Embed")
+	}
+	if spans[0].Status.Code != "Error" {
+		t.Errorf("span status = %q, want %q", spans[0].Status.Code, "Error")
+	}
+}
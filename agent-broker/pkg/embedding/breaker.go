@@ -0,0 +1,75 @@
+package embedding
+
+import (
+	"sync"
+	"time"
+)
+
+// breaker is a circuit breaker that opens after consecutive failures. While
+// open every call fails fast; once the cooldown has passed a single trial
+// call is let through, closing the circuit if it succeeds.
+type breaker struct {
+	// threshold is the number of consecutive failures that opens the circuit, 0 to never open.
+	threshold int
+	// cooldown is how long the circuit stays open before a trial call.
+	cooldown time.Duration
+	// now returns the current time.
+	now func() time.Time
+
+	// mu guards the fields below.
+	mu sync.Mutex
+	// failures is the number of consecutive failed calls.
+	failures int
+	// openUntil is when the open circuit allows a trial call, zero if closed.
+	openUntil time.Time
+	// trial is true while a trial call is in flight.
+	trial bool
+}
+
+// allow reports whether a call may be made now.
+func (b *breaker) allow() bool {
+	if b.threshold <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openUntil.IsZero() {
+		return true
+	}
+	if b.trial || b.now().Before(b.openUntil) {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+// abort releases an allowed call whose outcome says nothing about the
+// provider, such as one canceled by the caller.
+func (b *breaker) abort() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+// record updates the circuit with the outcome of an allowed call.
+func (b *breaker) record(failed bool) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+	if !failed {
+		b.failures = 0
+		b.openUntil = time.Time{}
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = b.now().Add(b.cooldown)
+	}
+}
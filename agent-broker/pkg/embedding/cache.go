@@ -0,0 +1,386 @@
+package embedding
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CacheOptions configures the CachedEmbedder.
+type CacheOptions struct {
+	// Model is the embedding model name, part of every cache key.
+	Model string
+	// Size is the maximum number of cached embeddings, 0 disables caching.
+	Size int
+	// TTL is how long an embedding stays cached, 0 for no expiry.
+	TTL time.Duration
+	// BatchWindow is how long a single-text request waits for others to
+	// share an upstream call, 0 disables coalescing.
+	BatchWindow time.Duration
+	// MaxBatch is the maximum number of texts in a coalesced upstream call.
+	MaxBatch int
+	// BatchTimeout bounds a coalesced upstream call joined by a request
+	// without a deadline, 0 for no bound. Other calls end at the latest
+	// deadline of the joined requests.
+	BatchTimeout time.Duration
+}
+
+// DefaultCacheOptions returns sensible defaults.
+func DefaultCacheOptions() CacheOptions {
+	return CacheOptions{
+		Size:         1024,
+		TTL:          time.Hour,
+		BatchWindow:  5 * time.Millisecond,
+		MaxBatch:     32,
+		BatchTimeout: 30 * time.Second,
+	}
+}
+
+// CacheOption is a functional option for CachedEmbedder.
+type CacheOption func(*CacheOptions)
+
+// WithCacheModel sets the model name used in cache keys.
+func WithCacheModel(model string) CacheOption {
+	return func(o *CacheOptions) {
+		o.Model = model
+	}
+}
+
+// WithCacheSize sets the maximum number of cached embeddings.
+func WithCacheSize(size int) CacheOption {
+	return func(o *CacheOptions) {
+		o.Size = size
+	}
+}
+
+// WithCacheTTL sets how long an embedding stays cached.
+func WithCacheTTL(ttl time.Duration) CacheOption {
+	return func(o *CacheOptions) {
+		o.TTL = ttl
+	}
+}
+
+// WithBatchWindow sets how long single-text requests wait to be coalesced.
+func WithBatchWindow(window time.Duration) CacheOption {
+	return func(o *CacheOptions) {
+		o.BatchWindow = window
+	}
+}
+
+// WithMaxBatch sets the maximum number of texts in a coalesced upstream call.
+func WithMaxBatch(n int) CacheOption {
+	return func(o *CacheOptions) {
+		o.MaxBatch = n
+	}
+}
+
+// WithBatchTimeout sets the bound of coalesced upstream calls joined by a
+// request without a deadline.
+func WithBatchTimeout(d time.Duration) CacheOption {
+	return func(o *CacheOptions) {
+		o.BatchTimeout = d
+	}
+}
+
+// CacheStats reports the effectiveness of a CachedEmbedder.
+type CacheStats struct {
+	// Hits is the number of texts served from the cache.
+	Hits uint64
+	// Misses is the number of texts that had to be embedded upstream.
+	Misses uint64
+	// Calls is the number of upstream Embed calls.
+	Calls uint64
+	// Entries is the number of embeddings currently cached.
+	Entries int
+}
+
+// cacheKey identifies a cached embedding.
+type cacheKey struct {
+	// model is the embedding model name.
+	model string
+	// text is the embedded text.
+	text string
+}
+
+// cacheEntry is a cached embedding in the LRU list.
+type cacheEntry struct {
+	// key identifies the entry.
+	key cacheKey
+	// vector is the cached embedding.
+	vector []float32
+	// expires is when the entry goes stale, zero if never.
+	expires time.Time
+}
+
+// batch is a coalesced upstream call being collected or in flight.
+type batch struct {
+	// ctx carries the values of the request that opened the batch.
+	ctx context.Context
+	// deadline is the latest deadline of the joined requests.
+	deadline time.Time
+	// unbounded is set when a joined request has no deadline.
+	unbounded bool
+	// texts are the distinct texts to embed.
+	texts []string
+	// index maps each text to its position in texts.
+	index map[string]int
+	// done is closed once embeddings or err is set.
+	done chan struct{}
+	// embeddings holds one vector per text after a successful call.
+	embeddings [][]float32
+	// err is the upstream error.
+	err error
+}
+
+// CachedEmbedder decorates an Embedder with an LRU cache keyed by model and
+// text, and coalesces concurrent single-text requests into batched upstream
+// calls. Returned vectors are shared and must not be modified.
+type CachedEmbedder struct {
+	// next is the decorated embedder.
+	next Embedder
+	// opts holds the cache and batching settings.
+	opts CacheOptions
+	// now returns the current time, replaced in tests.
+	now func() time.Time
+
+	// mu guards entries, lru and open.
+	mu sync.Mutex
+	// entries indexes the LRU list elements by key.
+	entries map[cacheKey]*list.Element
+	// lru orders entries from most to least recently used.
+	lru *list.List
+	// open is the batch collecting texts, nil if none.
+	open *batch
+
+	// hits counts texts served from the cache.
+	hits atomic.Uint64
+	// misses counts texts embedded upstream.
+	misses atomic.Uint64
+	// calls counts upstream Embed calls.
+	calls atomic.Uint64
+}
+
+// NewCachedEmbedder wraps next with caching and request coalescing.
+func NewCachedEmbedder(next Embedder, opts ...CacheOption) *CachedEmbedder {
+	options := DefaultCacheOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &CachedEmbedder{
+		next:    next,
+		opts:    options,
+		now:     time.Now,
+		entries: make(map[cacheKey]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// Embed returns cached embeddings where possible and embeds the rest upstream.
+func (c *CachedEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	var missing []string
+	positions := make(map[string][]int)
+	for i, text := range texts {
+		if vector, ok := c.get(text); ok {
+			c.hits.Add(1)
+			embeddings[i] = vector
+			continue
+		}
+		if _, ok := positions[text]; !ok {
+			missing = append(missing, text)
+		}
+		positions[text] = append(positions[text], i)
+	}
+	if len(missing) == 0 {
+		return embeddings, nil
+	}
+	c.misses.Add(uint64(len(missing)))
+
+	var vectors [][]float32
+	var err error
+	if len(missing) == 1 && c.opts.BatchWindow > 0 {
+		var vector []float32
+		vector, err = c.coalesce(ctx, missing[0])
+		vectors = [][]float32{vector}
+	} else {
+		vectors, err = c.embed(ctx, missing)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i, text := range missing {
+		for _, pos := range positions[text] {
+			embeddings[pos] = vectors[i]
+		}
+	}
+	return embeddings, nil
+}
+
+// Dimensions returns the embedding vector dimension of the decorated embedder.
+func (c *CachedEmbedder) Dimensions() int {
+	return c.next.Dimensions()
+}
+
+// Stats returns the cache hit, miss and upstream call counts.
+func (c *CachedEmbedder) Stats() CacheStats {
+	c.mu.Lock()
+	entries := c.lru.Len()
+	c.mu.Unlock()
+
+	return CacheStats{
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Calls:   c.calls.Load(),
+		Entries: entries,
+	}
+}
+
+// embed calls the decorated embedder and caches the results.
+func (c *CachedEmbedder) embed(ctx context.Context, texts []string) ([][]float32, error) {
+	c.calls.Add(1)
+	vectors, err := c.next.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("got %d embeddings for %d texts", len(vectors), len(texts))
+	}
+
+	for i, text := range texts {
+		c.put(text, vectors[i])
+	}
+	return vectors, nil
+}
+
+// coalesce adds text to the open batch and waits for its embedding. The batch
+// is sent when the window elapses or it is full. A caller that gives up does
+// not cancel the batch for the others, but the batch ends by the latest
+// deadline of its callers.
+func (c *CachedEmbedder) coalesce(ctx context.Context, text string) ([]float32, error) {
+	c.mu.Lock()
+	b := c.open
+	if b == nil {
+		b = &batch{
+			ctx:   context.WithoutCancel(ctx),
+			index: make(map[string]int),
+			done:  make(chan struct{}),
+		}
+		c.open = b
+		time.AfterFunc(c.opts.BatchWindow, func() { c.flush(b) })
+	}
+	if deadline, ok := ctx.Deadline(); !ok {
+		b.unbounded = true
+	} else if deadline.After(b.deadline) {
+		b.deadline = deadline
+	}
+	i, ok := b.index[text]
+	if !ok {
+		i = len(b.texts)
+		b.index[text] = i
+		b.texts = append(b.texts, text)
+	}
+	if c.opts.MaxBatch > 0 && len(b.texts) >= c.opts.MaxBatch {
+		c.open = nil
+		go c.send(b)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-b.done:
+		if b.err != nil {
+			return nil, b.err
+		}
+		return b.embeddings[i], nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// flush sends b when its window elapses unless it was already sent full.
+func (c *CachedEmbedder) flush(b *batch) {
+	c.mu.Lock()
+	if c.open != b {
+		c.mu.Unlock()
+		return
+	}
+	c.open = nil
+	c.mu.Unlock()
+
+	c.send(b)
+}
+
+// send embeds the texts of a batch closed to new texts and wakes its waiters.
+func (c *CachedEmbedder) send(b *batch) {
+	ctx := b.ctx
+	var cancel context.CancelFunc
+	switch {
+	case !b.unbounded:
+		ctx, cancel = context.WithDeadline(ctx, b.deadline)
+		defer cancel()
+	case c.opts.BatchTimeout > 0:
+		ctx, cancel = context.WithTimeout(ctx, c.opts.BatchTimeout)
+		defer cancel()
+	}
+
+	b.embeddings, b.err = c.embed(ctx, b.texts)
+	close(b.done)
+}
+
+// get returns the cached embedding of text if present and not expired.
+func (c *CachedEmbedder) get(text string) ([]float32, bool) {
+	if c.opts.Size <= 0 {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[cacheKey{model: c.opts.Model, text: text}]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if !entry.expires.IsZero() && !c.now().Before(entry.expires) {
+		c.lru.Remove(elem)
+		delete(c.entries, entry.key)
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return entry.vector, true
+}
+
+// put caches the embedding of text, evicting the least recently used entry
+// when the cache is full.
+func (c *CachedEmbedder) put(text string, vector []float32) {
+	if c.opts.Size <= 0 {
+		return
+	}
+
+	key := cacheKey{model: c.opts.Model, text: text}
+	var expires time.Time
+	if c.opts.TTL > 0 {
+		expires = c.now().Add(c.opts.TTL)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.vector, entry.expires = vector, expires
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, vector: vector, expires: expires})
+	for c.lru.Len() > c.opts.Size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
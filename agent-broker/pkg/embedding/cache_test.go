@@ -0,0 +1,280 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingEmbedder embeds each text as its length and records every call.
+type countingEmbedder struct {
+	mu    sync.Mutex
+	calls [][]string
+	err   error
+}
+
+func (e *countingEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	e.mu.Lock()
+	e.calls = append(e.calls, texts)
+	e.mu.Unlock()
+
+	if e.err != nil {
+		return nil, e.err
+	}
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		embeddings[i] = []float32{float32(len(text))}
+	}
+	return embeddings, nil
+}
+
+func (e *countingEmbedder) Dimensions() int {
+	return 1
+}
+
+func (e *countingEmbedder) callCount() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.calls)
+}
+
+func TestCachedEmbedder_Cache(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		opts      []CacheOption
+		advance   time.Duration
+		requests  [][]string
+		wantCalls int
+		wantStats CacheStats
+	}{
+		{
+			name:      "repeated text hits",
+			requests:  [][]string{{"a", "bb"}, {"bb"}, {"a", "bb"}},
+			wantCalls: 1,
+			wantStats: CacheStats{Hits: 3, Misses: 2, Calls: 1, Entries: 2},
+		},
+		{
+			name:      "duplicates in one request embed once",
+			requests:  [][]string{{"a", "a", "bb"}},
+			wantCalls: 1,
+			wantStats: CacheStats{Misses: 2, Calls: 1, Entries: 2},
+		},
+		{
+			name:      "least recently used is evicted",
+			opts:      []CacheOption{WithCacheSize(2)},
+			requests:  [][]string{{"a", "bb"}, {"a"}, {"ccc"}, {"bb"}},
+			wantCalls: 3,
+			wantStats: CacheStats{Hits: 1, Misses: 4, Calls: 3, Entries: 2},
+		},
+		{
+			name:      "expired entries miss",
+			opts:      []CacheOption{WithCacheTTL(time.Minute)},
+			advance:   time.Minute,
+			requests:  [][]string{{"a"}, {"a"}},
+			wantCalls: 2,
+			wantStats: CacheStats{Misses: 2, Calls: 2, Entries: 1},
+		},
+		{
+			name:      "size zero disables caching",
+			opts:      []CacheOption{WithCacheSize(0)},
+			requests:  [][]string{{"a"}, {"a"}},
+			wantCalls: 2,
+			wantStats: CacheStats{Misses: 2, Calls: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			next := &countingEmbedder{}
+			c := NewCachedEmbedder(next, append([]CacheOption{WithBatchWindow(0)}, tt.opts...)...)
+			now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			c.now = func() time.Time { return now }
+
+			for _, texts := range tt.requests {
+				embeddings, err := c.Embed(context.Background(), texts)
+				if err != nil {
+					t.Fatalf("Embed(%v) error = %v", texts, err)
+				}
+				for i, text := range texts {
+					if len(embeddings[i]) != 1 || embeddings[i][0] != float32(len(text)) {
+						t.Errorf("Embed(%v)[%d] = %v, want [%d]", texts, i, embeddings[i], len(text))
+					}
+				}
+				now = now.Add(tt.advance)
+			}
+
+			if got := next.callCount(); got != tt.wantCalls {
+				t.Errorf("upstream calls = %d, want %d", got, tt.wantCalls)
+			}
+			if got := c.Stats(); got != tt.wantStats {
+				t.Errorf("Stats() = %+v, want %+v", got, tt.wantStats)
+			}
+		})
+	}
+}
+
+func TestCachedEmbedder_Coalesce(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		texts     []string
+		maxBatch  int
+		wantCalls int
+	}{
+		{name: "concurrent texts share a call", texts: []string{"a", "bb", "ccc", "bb"}, maxBatch: 32, wantCalls: 1},
+		{name: "full batches are sent early", texts: []string{"a", "bb", "ccc", "dddd"}, maxBatch: 2, wantCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			next := &countingEmbedder{}
+			c := NewCachedEmbedder(next,
+				WithBatchWindow(time.Second),
+				WithMaxBatch(tt.maxBatch),
+			)
+
+			var wg sync.WaitGroup
+			errs := make([]error, len(tt.texts))
+			for i, text := range tt.texts {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					embeddings, err := c.Embed(context.Background(), []string{text})
+					if err == nil && embeddings[0][0] != float32(len(text)) {
+						err = fmt.Errorf("got %v for %q", embeddings[0], text)
+					}
+					errs[i] = err
+				}()
+			}
+
+			done := make(chan struct{})
+			go func() {
+				wg.Wait()
+				close(done)
+			}()
+			if tt.maxBatch < len(tt.texts) {
+				// Full batches must not wait for the window.
+				select {
+				case <-done:
+				case <-time.After(500 * time.Millisecond):
+					t.Fatal("full batches were not sent before the window elapsed")
+				}
+			}
+			<-done
+
+			for _, err := range errs {
+				if err != nil {
+					t.Errorf("Embed() error = %v", err)
+				}
+			}
+			if got := next.callCount(); got != tt.wantCalls {
+				t.Errorf("upstream calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// deadlineEmbedder records the deadline of the first call.
+type deadlineEmbedder struct {
+	deadline chan time.Time
+}
+
+func (e deadlineEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	deadline, _ := ctx.Deadline()
+	e.deadline <- deadline
+	return make([][]float32, len(texts)), nil
+}
+
+func (deadlineEmbedder) Dimensions() int {
+	return 1
+}
+
+func TestCachedEmbedder_CoalesceDeadline(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	tests := []struct {
+		name      string
+		deadlines []time.Duration
+		want      time.Duration
+	}{
+		{name: "latest caller deadline", deadlines: []time.Duration{time.Minute, 2 * time.Minute}, want: 2 * time.Minute},
+		{name: "batch timeout without caller deadline", deadlines: []time.Duration{time.Minute, 0}, want: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			next := deadlineEmbedder{deadline: make(chan time.Time, 1)}
+			c := NewCachedEmbedder(next,
+				WithBatchWindow(50*time.Millisecond),
+				WithBatchTimeout(time.Hour),
+			)
+
+			var wg sync.WaitGroup
+			for i, d := range tt.deadlines {
+				ctx, cancel := context.Background(), context.CancelFunc(func() {})
+				if d > 0 {
+					ctx, cancel = context.WithDeadline(ctx, now.Add(d))
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer cancel()
+					_, _ = c.Embed(ctx, []string{fmt.Sprint(i)})
+				}()
+			}
+			wg.Wait()
+
+			got := (<-next.deadline).Sub(now)
+			if got < tt.want || got > tt.want+5*time.Second {
+				t.Errorf("batch deadline = now+%v, want now+%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCachedEmbedder_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("upstream error reaches every waiter", func(t *testing.T) {
+		t.Parallel()
+		errUpstream := errors.New("upstream down")
+		c := NewCachedEmbedder(&countingEmbedder{err: errUpstream}, WithBatchWindow(10*time.Millisecond))
+
+		var wg sync.WaitGroup
+		for _, text := range []string{"a", "bb"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := c.Embed(context.Background(), []string{text}); !errors.Is(err, errUpstream) {
+					t.Errorf("Embed(%q) error = %v, want %v", text, err, errUpstream)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if got := c.Stats().Entries; got != 0 {
+			t.Errorf("Stats().Entries = %d, want 0", got)
+		}
+	})
+
+	t.Run("canceled waiter returns early", func(t *testing.T) {
+		t.Parallel()
+		c := NewCachedEmbedder(&countingEmbedder{}, WithBatchWindow(time.Minute))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := c.Embed(ctx, []string{"a"}); !errors.Is(err, context.Canceled) {
+			t.Errorf("Embed() error = %v, want context.Canceled", err)
+		}
+	})
+}
@@ -1,6 +1,17 @@
 package embedding
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrDimensionMismatch is returned when the embedder produces vectors of a
+// different size than it reports.
+var ErrDimensionMismatch = errors.New("embedding dimension mismatch")
+
+// probeText is embedded to measure the vectors an embedder actually returns.
+const probeText = "dimension probe"
 
 // Embedder generates vector embeddings from text.
 type Embedder interface {
@@ -9,3 +20,20 @@ type Embedder interface {
 	// Dimensions returns the embedding vector dimension.
 	Dimensions() int
 }
+
+// CheckDimension embeds a probe text and checks the vector has the size
+// reported by e.Dimensions, returning ErrDimensionMismatch if not.
+func CheckDimension(ctx context.Context, e Embedder) error {
+	embeddings, err := e.Embed(ctx, []string{probeText})
+	if err != nil {
+		return fmt.Errorf("probe embedder: %w", err)
+	}
+	if len(embeddings) != 1 {
+		return fmt.Errorf("probe embedder: got %d embeddings for 1 text", len(embeddings))
+	}
+	if got, want := len(embeddings[0]), e.Dimensions(); got != want {
+		return fmt.Errorf("%w: embedder returned %d-dimensional vectors, configured dimension is %d",
+			ErrDimensionMismatch, got, want)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestCheckDimension(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		reply        reply
+		dim          int
+		wantErr      bool
+		wantMismatch bool
+	}{
+		{name: "matching dimension", reply: reply{status: http.StatusOK, body: okBody}, dim: 2},
+		{name: "mismatched dimension", reply: reply{status: http.StatusOK, body: okBody}, dim: 384, wantErr: true, wantMismatch: true},
+		{name: "embedder unavailable", reply: reply{status: http.StatusBadRequest}, dim: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			srv, _ := scriptedServer(t, tt.reply)
+
+			err := CheckDimension(context.Background(), NewClient(srv.URL, tt.dim))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckDimension() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := errors.Is(err, ErrDimensionMismatch); got != tt.wantMismatch {
+				t.Errorf("CheckDimension() error = %v, want ErrDimensionMismatch %v", err, tt.wantMismatch)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package embedding
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the provider while the circuit
+// breaker is open after repeated failures.
+var ErrCircuitOpen = errors.New("embedding provider circuit open")
+
+// maxErrorBody is the most of an error response body read for its message.
+const maxErrorBody = 4096
+
+// StatusError is returned when the embeddings API answers with a non-200 status.
+type StatusError struct {
+	// StatusCode is the HTTP status code.
+	StatusCode int
+	// Message is the provider's error message, empty if the body had none.
+	Message string
+	// RetryAfter is the delay requested by the Retry-After header, 0 if absent.
+	RetryAfter time.Duration
+}
+
+// Error returns the status code and provider message.
+func (e *StatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("unexpected status: %d", e.StatusCode)
+	}
+	return fmt.Sprintf("unexpected status: %d: %s", e.StatusCode, e.Message)
+}
+
+// Retryable reports whether the request may succeed if sent again: the
+// provider is rate limiting, overloaded or failing.
+func (e *StatusError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+// newStatusError reads the provider message and Retry-After delay of a failed response.
+func newStatusError(resp *http.Response, now time.Time) *StatusError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return &StatusError{
+		StatusCode: resp.StatusCode,
+		Message:    errorMessage(body),
+		RetryAfter: retryAfter(resp.Header.Get("Retry-After"), now),
+	}
+}
+
+// errorMessage extracts the message of an error body in the OpenAI
+// ({"error": {"message": ...}}), TEI ({"error": ...}) or plain
+// ({"message": ...}) format, falling back to the raw text.
+func errorMessage(body []byte) string {
+	var parsed struct {
+		Error   json.RawMessage `json:"error"`
+		Message string          `json:"message"`
+	}
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		var nested struct {
+			Message string `json:"message"`
+		}
+		var text string
+		switch {
+		case json.Unmarshal(parsed.Error, &nested) == nil && nested.Message != "":
+			return nested.Message
+		case json.Unmarshal(parsed.Error, &text) == nil && text != "":
+			return text
+		case parsed.Message != "":
+			return parsed.Message
+		}
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func retryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return max(at.Sub(now), 0)
+	}
+	return 0
+}
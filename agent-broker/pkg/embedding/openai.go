@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand/v2"
 	"net/http"
 	"time"
 )
@@ -20,6 +22,18 @@ type Client struct {
 	dim int
 	// httpClient is the HTTP client for making requests.
 	httpClient *http.Client
+	// maxRetries is the number of retries after a failed attempt.
+	maxRetries int
+	// backoff is the delay before the first retry, doubled for each next one.
+	backoff time.Duration
+	// maxBackoff caps the exponential retry delay and the Retry-After delay.
+	maxBackoff time.Duration
+	// breaker fails calls fast while the provider is down.
+	breaker *breaker
+	// now returns the current time.
+	now func() time.Time
+	// sleep waits between attempts, returning early if ctx is done.
+	sleep func(ctx context.Context, d time.Duration) error
 }
 
 // Options configures the Client.
@@ -28,6 +42,21 @@ type Options struct {
 	Model string
 	// HTTPClient is the HTTP client to use.
 	HTTPClient *http.Client
+	// MaxRetries is the number of retries after a rate limited, failed or
+	// unreachable attempt, 0 to disable retries.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry, doubled for each next
+	// one and jittered. A Retry-After header overrides it.
+	RetryBackoff time.Duration
+	// RetryMaxBackoff caps the exponential retry delay and the Retry-After
+	// delay.
+	RetryMaxBackoff time.Duration
+	// BreakerThreshold is the number of consecutive failed calls that opens
+	// the circuit breaker, 0 to disable it.
+	BreakerThreshold int
+	// BreakerCooldown is how long the open circuit fails calls fast before
+	// letting a trial call through.
+	BreakerCooldown time.Duration
 }
 
 // DefaultOptions returns sensible defaults.
@@ -37,6 +66,11 @@ func DefaultOptions() Options {
 		HTTPClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		MaxRetries:       3,
+		RetryBackoff:     200 * time.Millisecond,
+		RetryMaxBackoff:  5 * time.Second,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
 	}
 }
 
@@ -57,6 +91,30 @@ func WithHTTPClient(client *http.Client) Option {
 	}
 }
 
+// WithRetries sets the number of retries after a failed attempt.
+func WithRetries(n int) Option {
+	return func(o *Options) {
+		o.MaxRetries = n
+	}
+}
+
+// WithBackoff sets the initial and maximum retry delay.
+func WithBackoff(initial, maximum time.Duration) Option {
+	return func(o *Options) {
+		o.RetryBackoff = initial
+		o.RetryMaxBackoff = maximum
+	}
+}
+
+// WithCircuitBreaker sets the consecutive failures that open the circuit
+// breaker and how long it stays open.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(o *Options) {
+		o.BreakerThreshold = threshold
+		o.BreakerCooldown = cooldown
+	}
+}
+
 // embeddingRequest is the request body for POST /v1/embeddings.
 type embeddingRequest struct {
 	Input []string `json:"input"`
@@ -86,10 +144,24 @@ func NewClient(url string, dim int, opts ...Option) *Client {
 		model:      options.Model,
 		dim:        dim,
 		httpClient: options.HTTPClient,
+		maxRetries: options.MaxRetries,
+		backoff:    options.RetryBackoff,
+		maxBackoff: options.RetryMaxBackoff,
+		breaker: &breaker{
+			threshold: options.BreakerThreshold,
+			cooldown:  options.BreakerCooldown,
+			now:       time.Now,
+		},
+		now:   time.Now,
+		sleep: sleep,
 	}
 }
 
-// Embed generates embeddings for the given texts.
+// Embed generates embeddings for the given texts. Rate limited, failed and
+// unreachable attempts are retried with backoff until the retries run out or
+// the next retry would start past the ctx deadline, and while the provider
+// keeps failing calls fail fast with ErrCircuitOpen. Error statuses are returned as
+// *StatusError.
 func (c *Client) Embed(ctx context.Context, texts []string) ([][]float32, error) {
 	if len(texts) == 0 {
 		return [][]float32{}, nil
@@ -105,25 +177,58 @@ func (c *Client) Embed(ctx context.Context, texts []string) ([][]float32, error)
 		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
+	if !c.breaker.allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	for attempt := 0; ; attempt++ {
+		embeddings, retry, err := c.post(ctx, body)
+		switch {
+		case err == nil:
+			c.breaker.record(false)
+			return embeddings, nil
+		case ctx.Err() != nil:
+			c.breaker.abort()
+			return nil, err
+		case !retry || attempt >= c.maxRetries:
+			c.breaker.record(retry)
+			return nil, err
+		}
+
+		delay := c.retryDelay(attempt, err)
+		if deadline, ok := ctx.Deadline(); ok && c.now().Add(delay).After(deadline) {
+			c.breaker.record(retry)
+			return nil, err
+		}
+		if err := c.sleep(ctx, delay); err != nil {
+			c.breaker.abort()
+			return nil, err
+		}
+	}
+}
+
+// post sends one embeddings request and reports whether a failure is worth retrying.
+func (c *Client) post(ctx context.Context, body []byte) ([][]float32, bool, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+"/v1/embeddings", bytes.NewReader(body))
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+		return nil, false, fmt.Errorf("create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
+		return nil, true, fmt.Errorf("do request: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+		statusErr := newStatusError(resp, c.now())
+		return nil, statusErr.Retryable(), statusErr
 	}
 
 	var embResp embeddingResponse
 	if err := json.NewDecoder(resp.Body).Decode(&embResp); err != nil {
-		return nil, fmt.Errorf("decode response: %w", err)
+		return nil, false, fmt.Errorf("decode response: %w", err)
 	}
 
 	// Sort by index and extract embeddings
@@ -134,7 +239,44 @@ func (c *Client) Embed(ctx context.Context, texts []string) ([][]float32, error)
 		}
 	}
 
-	return embeddings, nil
+	return embeddings, false, nil
+}
+
+// retryDelay returns the wait before retrying after the given failed attempt:
+// the provider's Retry-After if set, otherwise exponential backoff with jitter,
+// capped at maxBackoff either way.
+func (c *Client) retryDelay(attempt int, err error) time.Duration {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+		return min(statusErr.RetryAfter, c.maxBackoff)
+	}
+
+	delay := c.backoff
+	for range attempt {
+		if delay >= c.maxBackoff {
+			break
+		}
+		delay *= 2
+	}
+	delay = min(delay, c.maxBackoff)
+	if delay <= 0 {
+		return 0
+	}
+	// Equal jitter keeps at least half the delay while spreading out retries.
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // Dimensions returns the embedding vector dimension.
@@ -0,0 +1,263 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// reply is a scripted embeddings API response.
+type reply struct {
+	status     int
+	body       string
+	retryAfter string
+}
+
+const okBody = `{"data":[{"embedding":[0.5,0.5],"index":0}]}`
+
+// scriptedServer answers embeddings requests with replies in order, repeating the last.
+func scriptedServer(t *testing.T, replies ...reply) (*httptest.Server, func() int) {
+	t.Helper()
+	var mu sync.Mutex
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		r := replies[min(requests, len(replies)-1)]
+		requests++
+		mu.Unlock()
+
+		if r.retryAfter != "" {
+			w.Header().Set("Retry-After", r.retryAfter)
+		}
+		w.WriteHeader(r.status)
+		_, _ = w.Write([]byte(r.body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+}
+
+// newTestClient returns a client that records retry delays instead of sleeping.
+func newTestClient(url string, opts ...Option) (*Client, *[]time.Duration) {
+	c := NewClient(url, 2, opts...)
+	var delays []time.Duration
+	c.sleep = func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	return c, &delays
+}
+
+func TestClient_Embed_Retry(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		replies      []reply
+		wantErr      bool
+		wantStatus   int
+		wantMessage  string
+		wantRequests int
+		wantDelays   []time.Duration
+	}{
+		{
+			name:         "success",
+			replies:      []reply{{status: http.StatusOK, body: okBody}},
+			wantRequests: 1,
+		},
+		{
+			name: "retries warm-up errors",
+			replies: []reply{
+				{status: http.StatusServiceUnavailable, body: `{"error":"Model is loading","error_type":"Unhealthy"}`},
+				{status: http.StatusTooManyRequests, body: `{"error":{"message":"rate limited"}}`},
+				{status: http.StatusOK, body: okBody},
+			},
+			wantRequests: 3,
+		},
+		{
+			name: "honors Retry-After",
+			replies: []reply{
+				{status: http.StatusTooManyRequests, retryAfter: "3"},
+				{status: http.StatusOK, body: okBody},
+			},
+			wantRequests: 2,
+			wantDelays:   []time.Duration{3 * time.Second},
+		},
+		{
+			name: "caps Retry-After at max backoff",
+			replies: []reply{
+				{status: http.StatusTooManyRequests, retryAfter: "3600"},
+				{status: http.StatusOK, body: okBody},
+			},
+			wantRequests: 2,
+			wantDelays:   []time.Duration{5 * time.Second},
+		},
+		{
+			name:         "gives up after max retries",
+			replies:      []reply{{status: http.StatusBadGateway, body: "upstream unavailable"}},
+			wantErr:      true,
+			wantStatus:   http.StatusBadGateway,
+			wantMessage:  "upstream unavailable",
+			wantRequests: 4,
+		},
+		{
+			name:         "does not retry client errors",
+			replies:      []reply{{status: http.StatusBadRequest, body: `{"message":"input too long"}`}},
+			wantErr:      true,
+			wantStatus:   http.StatusBadRequest,
+			wantMessage:  "input too long",
+			wantRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			srv, requests := scriptedServer(t, tt.replies...)
+			c, delays := newTestClient(srv.URL)
+
+			embeddings, err := c.Embed(context.Background(), []string{"hello"})
+			if tt.wantErr {
+				var statusErr *StatusError
+				if !errors.As(err, &statusErr) {
+					t.Fatalf("Embed() error = %v, want *StatusError", err)
+				}
+				if statusErr.StatusCode != tt.wantStatus || statusErr.Message != tt.wantMessage {
+					t.Errorf("StatusError = %d %q, want %d %q", statusErr.StatusCode, statusErr.Message, tt.wantStatus, tt.wantMessage)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Embed() error = %v", err)
+				}
+				if len(embeddings) != 1 || len(embeddings[0]) != 2 {
+					t.Errorf("Embed() = %v, want one 2-dimensional vector", embeddings)
+				}
+			}
+
+			if got := requests(); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+			if tt.wantDelays != nil && !slices.Equal(*delays, tt.wantDelays) {
+				t.Errorf("delays = %v, want %v", *delays, tt.wantDelays)
+			}
+		})
+	}
+}
+
+func TestClient_Embed_RetryPastDeadline(t *testing.T) {
+	t.Parallel()
+	srv, requests := scriptedServer(t, reply{status: http.StatusTooManyRequests, retryAfter: "3"})
+	c, delays := newTestClient(srv.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := c.Embed(ctx, []string{"hello"})
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Embed() error = %v, want 429 *StatusError", err)
+	}
+	if got := requests(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+	if len(*delays) != 0 {
+		t.Errorf("delays = %v, want none", *delays)
+	}
+}
+
+func TestClient_RetryDelay(t *testing.T) {
+	t.Parallel()
+
+	c := NewClient("http://localhost", 2, WithBackoff(100*time.Millisecond, time.Second))
+
+	tests := []struct {
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{attempt: 0, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		{attempt: 1, min: 100 * time.Millisecond, max: 200 * time.Millisecond},
+		{attempt: 3, min: 400 * time.Millisecond, max: 800 * time.Millisecond},
+		{attempt: 10, min: 500 * time.Millisecond, max: time.Second},
+	}
+
+	for _, tt := range tests {
+		for range 20 {
+			if d := c.retryDelay(tt.attempt, errors.New("down")); d < tt.min || d > tt.max {
+				t.Errorf("retryDelay(%d) = %v, want between %v and %v", tt.attempt, d, tt.min, tt.max)
+			}
+		}
+	}
+}
+
+func TestClient_Embed_CircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	srv, requests := scriptedServer(t,
+		reply{status: http.StatusServiceUnavailable},
+		reply{status: http.StatusServiceUnavailable},
+		reply{status: http.StatusOK, body: okBody},
+	)
+	c, _ := newTestClient(srv.URL, WithRetries(0), WithCircuitBreaker(2, time.Minute))
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.breaker.now = func() time.Time { return now }
+
+	embed := func() error {
+		_, err := c.Embed(context.Background(), []string{"hello"})
+		return err
+	}
+
+	for range 2 {
+		var statusErr *StatusError
+		if err := embed(); !errors.As(err, &statusErr) {
+			t.Fatalf("Embed() error = %v, want *StatusError", err)
+		}
+	}
+	if err := embed(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Embed() while open error = %v, want ErrCircuitOpen", err)
+	}
+	if got := requests(); got != 2 {
+		t.Errorf("requests while open = %d, want 2", got)
+	}
+
+	now = now.Add(time.Minute)
+	if err := embed(); err != nil {
+		t.Fatalf("Embed() trial error = %v", err)
+	}
+	if err := embed(); err != nil {
+		t.Fatalf("Embed() after close error = %v", err)
+	}
+	if got := requests(); got != 4 {
+		t.Errorf("requests = %d, want 4", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{header: "", want: 0},
+		{header: "3", want: 3 * time.Second},
+		{header: now.Add(90 * time.Second).Format(http.TimeFormat), want: 90 * time.Second},
+		{header: now.Add(-time.Minute).Format(http.TimeFormat), want: 0},
+		{header: "soon", want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := retryAfter(tt.header, now); got != tt.want {
+			t.Errorf("retryAfter(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
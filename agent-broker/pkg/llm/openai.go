@@ -0,0 +1,421 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// maxErrorBody is the max number of response bytes included in status errors.
+const maxErrorBody = 512
+
+// OpenAIModel is a model.LLM backed by an OpenAI-compatible chat completions API.
+// Works with OpenAI, vLLM, Ollama, llama.cpp, and other compatible providers.
+type OpenAIModel struct {
+	// url is the base URL of the chat completions API.
+	url string
+	// model is the model name sent with each request.
+	model string
+	// apiKey is the bearer token sent with each request, if set.
+	apiKey string
+	// httpClient is the HTTP client for making requests.
+	httpClient *http.Client
+}
+
+// Options configures the OpenAIModel.
+type Options struct {
+	// APIKey is the bearer token for the API. Empty for unauthenticated servers.
+	APIKey string
+	// HTTPClient is the HTTP client to use.
+	HTTPClient *http.Client
+}
+
+// DefaultOptions returns sensible defaults.
+func DefaultOptions() Options {
+	return Options{
+		APIKey: "",
+		HTTPClient: &http.Client{
+			Timeout: 120 * time.Second,
+		},
+	}
+}
+
+// Option is a functional option for OpenAIModel.
+type Option func(*Options)
+
+// WithAPIKey sets the API key.
+func WithAPIKey(key string) Option {
+	return func(o *Options) {
+		o.APIKey = key
+	}
+}
+
+// WithHTTPClient sets the HTTP client.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *Options) {
+		o.HTTPClient = client
+	}
+}
+
+// NewOpenAIModel creates a model.LLM for an OpenAI-compatible chat completions API.
+func NewOpenAIModel(url, modelName string, opts ...Option) *OpenAIModel {
+	options := DefaultOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &OpenAIModel{
+		url:        strings.TrimRight(url, "/"),
+		model:      modelName,
+		apiKey:     options.APIKey,
+		httpClient: options.HTTPClient,
+	}
+}
+
+// Name returns the model name.
+func (m *OpenAIModel) Name() string {
+	return m.model
+}
+
+// GenerateContent sends the request to POST /v1/chat/completions.
+// Streaming is not supported: when stream is true the complete response is
+// yielded as a single, final response.
+func (m *OpenAIModel) GenerateContent(ctx context.Context, req *model.LLMRequest, _ bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		resp, err := m.generate(ctx, req)
+		yield(resp, err)
+	}
+}
+
+// chatRequest is the request body for POST /v1/chat/completions.
+type chatRequest struct {
+	Model       string        `json:"model,omitempty"`
+	Messages    []chatMessage `json:"messages"`
+	Tools       []chatTool    `json:"tools,omitempty"`
+	Temperature *float32      `json:"temperature,omitempty"`
+	TopP        *float32      `json:"top_p,omitempty"`
+	MaxTokens   int32         `json:"max_tokens,omitempty"`
+	Stop        []string      `json:"stop,omitempty"`
+}
+
+// chatMessage is a single message of a conversation.
+type chatMessage struct {
+	Role       string         `json:"role"`
+	Content    string         `json:"content"`
+	ToolCalls  []chatToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+}
+
+// chatTool declares a function the model may call.
+type chatTool struct {
+	Type     string       `json:"type"`
+	Function chatFunction `json:"function"`
+}
+
+// chatFunction describes a callable function.
+type chatFunction struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parameters  any    `json:"parameters"`
+}
+
+// chatToolCall is a function call made by the model.
+type chatToolCall struct {
+	ID       string           `json:"id"`
+	Type     string           `json:"type"`
+	Function chatFunctionCall `json:"function"`
+}
+
+// chatFunctionCall holds the function name and JSON-encoded arguments.
+type chatFunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// chatResponse is the response from POST /v1/chat/completions.
+type chatResponse struct {
+	Choices []chatChoice `json:"choices"`
+	Usage   *chatUsage   `json:"usage,omitempty"`
+}
+
+// chatChoice is a single completion choice.
+type chatChoice struct {
+	Message      chatMessage `json:"message"`
+	FinishReason string      `json:"finish_reason"`
+}
+
+// chatUsage reports token usage of a completion.
+type chatUsage struct {
+	PromptTokens     int32 `json:"prompt_tokens"`
+	CompletionTokens int32 `json:"completion_tokens"`
+	TotalTokens      int32 `json:"total_tokens"`
+}
+
+// generate performs a single chat completion call.
+func (m *OpenAIModel) generate(ctx context.Context, req *model.LLMRequest) (*model.LLMResponse, error) {
+	chatReq, err := m.toChatRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(chatReq)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, m.url+"/v1/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if m.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+m.apiKey)
+	}
+
+	resp, err := m.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return nil, fmt.Errorf("unexpected status: %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+
+	var chatResp chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return toLLMResponse(&chatResp)
+}
+
+// toChatRequest converts an ADK request into a chat completions request.
+func (m *OpenAIModel) toChatRequest(req *model.LLMRequest) (*chatRequest, error) {
+	chatReq := &chatRequest{Model: m.model}
+	if req.Model != "" {
+		chatReq.Model = req.Model
+	}
+
+	if cfg := req.Config; cfg != nil {
+		if system := contentText(cfg.SystemInstruction); system != "" {
+			chatReq.Messages = append(chatReq.Messages, chatMessage{Role: "system", Content: system})
+		}
+		chatReq.Temperature = cfg.Temperature
+		chatReq.TopP = cfg.TopP
+		chatReq.MaxTokens = cfg.MaxOutputTokens
+		chatReq.Stop = cfg.StopSequences
+
+		for _, t := range cfg.Tools {
+			if t == nil {
+				continue
+			}
+			for _, decl := range t.FunctionDeclarations {
+				chatReq.Tools = append(chatReq.Tools, toChatTool(decl))
+			}
+		}
+	}
+
+	for _, content := range req.Contents {
+		messages, err := toChatMessages(content)
+		if err != nil {
+			return nil, err
+		}
+		chatReq.Messages = append(chatReq.Messages, messages...)
+	}
+
+	return chatReq, nil
+}
+
+// toChatMessages converts a single content into chat messages. Model content
+// becomes one assistant message carrying text and tool calls; function
+// responses become tool messages followed by any user text.
+func toChatMessages(content *genai.Content) ([]chatMessage, error) {
+	if content == nil {
+		return nil, nil
+	}
+
+	var (
+		text      []string
+		toolCalls []chatToolCall
+		messages  []chatMessage
+	)
+	for _, part := range content.Parts {
+		switch {
+		case part == nil || part.Thought:
+		case part.FunctionCall != nil:
+			args, err := json.Marshal(part.FunctionCall.Args)
+			if err != nil {
+				return nil, fmt.Errorf("marshal arguments of %s: %w", part.FunctionCall.Name, err)
+			}
+			toolCalls = append(toolCalls, chatToolCall{
+				ID:   part.FunctionCall.ID,
+				Type: "function",
+				Function: chatFunctionCall{
+					Name:      part.FunctionCall.Name,
+					Arguments: string(args),
+				},
+			})
+		case part.FunctionResponse != nil:
+			result, err := json.Marshal(part.FunctionResponse.Response)
+			if err != nil {
+				return nil, fmt.Errorf("marshal response of %s: %w", part.FunctionResponse.Name, err)
+			}
+			messages = append(messages, chatMessage{
+				Role:       "tool",
+				Content:    string(result),
+				ToolCallID: part.FunctionResponse.ID,
+			})
+		case part.Text != "":
+			text = append(text, part.Text)
+		}
+	}
+
+	if content.Role == genai.RoleModel {
+		if len(text) == 0 && len(toolCalls) == 0 {
+			return messages, nil
+		}
+		return append(messages, chatMessage{
+			Role:      "assistant",
+			Content:   strings.Join(text, "\n"),
+			ToolCalls: toolCalls,
+		}), nil
+	}
+
+	if len(text) > 0 {
+		messages = append(messages, chatMessage{Role: "user", Content: strings.Join(text, "\n")})
+	}
+	return messages, nil
+}
+
+// toChatTool converts a function declaration into a chat tool.
+func toChatTool(decl *genai.FunctionDeclaration) chatTool {
+	var params any = map[string]any{"type": "object", "properties": map[string]any{}}
+	switch {
+	case decl.ParametersJsonSchema != nil:
+		params = decl.ParametersJsonSchema
+	case decl.Parameters != nil:
+		params = schemaToJSON(decl.Parameters)
+	}
+
+	return chatTool{
+		Type: "function",
+		Function: chatFunction{
+			Name:        decl.Name,
+			Description: decl.Description,
+			Parameters:  params,
+		},
+	}
+}
+
+// schemaToJSON converts a genai schema into a JSON Schema object.
+// genai uses upper-case OpenAPI type names; JSON Schema expects lower case.
+func schemaToJSON(s *genai.Schema) map[string]any {
+	out := map[string]any{}
+	if s.Type != genai.TypeUnspecified {
+		out["type"] = strings.ToLower(string(s.Type))
+	}
+	if s.Description != "" {
+		out["description"] = s.Description
+	}
+	if s.Format != "" {
+		out["format"] = s.Format
+	}
+	if len(s.Enum) > 0 {
+		out["enum"] = s.Enum
+	}
+	if s.Items != nil {
+		out["items"] = schemaToJSON(s.Items)
+	}
+	if len(s.Properties) > 0 {
+		props := make(map[string]any, len(s.Properties))
+		for name, prop := range s.Properties {
+			props[name] = schemaToJSON(prop)
+		}
+		out["properties"] = props
+	}
+	if len(s.Required) > 0 {
+		out["required"] = s.Required
+	}
+	return out
+}
+
+// toLLMResponse converts the first choice of a chat response into an ADK response.
+func toLLMResponse(resp *chatResponse) (*model.LLMResponse, error) {
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("response has no choices")
+	}
+	choice := resp.Choices[0]
+
+	var parts []*genai.Part
+	if choice.Message.Content != "" {
+		parts = append(parts, genai.NewPartFromText(choice.Message.Content))
+	}
+	for _, call := range choice.Message.ToolCalls {
+		args := map[string]any{}
+		if strings.TrimSpace(call.Function.Arguments) != "" {
+			if err := json.Unmarshal([]byte(call.Function.Arguments), &args); err != nil {
+				return nil, fmt.Errorf("decode arguments of %s: %w", call.Function.Name, err)
+			}
+		}
+		parts = append(parts, &genai.Part{FunctionCall: &genai.FunctionCall{
+			ID:   call.ID,
+			Name: call.Function.Name,
+			Args: args,
+		}})
+	}
+
+	llmResp := &model.LLMResponse{
+		Content:      &genai.Content{Role: genai.RoleModel, Parts: parts},
+		FinishReason: toFinishReason(choice.FinishReason),
+		TurnComplete: true,
+	}
+	if resp.Usage != nil {
+		llmResp.UsageMetadata = &genai.GenerateContentResponseUsageMetadata{
+			PromptTokenCount:     resp.Usage.PromptTokens,
+			CandidatesTokenCount: resp.Usage.CompletionTokens,
+			TotalTokenCount:      resp.Usage.TotalTokens,
+		}
+	}
+	return llmResp, nil
+}
+
+// toFinishReason maps an OpenAI finish reason to its genai equivalent.
+func toFinishReason(reason string) genai.FinishReason {
+	switch reason {
+	case "stop", "tool_calls", "function_call":
+		return genai.FinishReasonStop
+	case "length":
+		return genai.FinishReasonMaxTokens
+	case "content_filter":
+		return genai.FinishReasonSafety
+	case "":
+		return genai.FinishReasonUnspecified
+	default:
+		return genai.FinishReasonOther
+	}
+}
+
+// contentText joins the text parts of content.
+func contentText(content *genai.Content) string {
+	if content == nil {
+		return ""
+	}
+	var text []string
+	for _, part := range content.Parts {
+		if part != nil && part.Text != "" {
+			text = append(text, part.Text)
+		}
+	}
+	return strings.Join(text, "\n")
+}
@@ -0,0 +1,183 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// chatServer serves chat completions with the responses of handle.
+func chatServer(t *testing.T, handle func(req chatRequest) map[string]any) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+
+		var req chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(handle(req))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func generate(t *testing.T, m model.LLM, req *model.LLMRequest) *model.LLMResponse {
+	t.Helper()
+	var resp *model.LLMResponse
+	for r, err := range m.GenerateContent(context.Background(), req, false) {
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+		resp = r
+	}
+	return resp
+}
+
+func TestOpenAIModel_GenerateContent(t *testing.T) {
+	t.Parallel()
+
+	t.Run("text reply", func(t *testing.T) {
+		t.Parallel()
+		server := chatServer(t, func(req chatRequest) map[string]any {
+			if req.Model != "qwen3" {
+				t.Errorf("model = %s, want qwen3", req.Model)
+			}
+			if len(req.Messages) != 2 || req.Messages[0].Role != "system" || req.Messages[1].Content != "hello" {
+				t.Errorf("unexpected messages: %+v", req.Messages)
+			}
+			return map[string]any{
+				"choices": []map[string]any{{
+					"message":       map[string]any{"role": "assistant", "content": "hi there"},
+					"finish_reason": "stop",
+				}},
+				"usage": map[string]any{"prompt_tokens": 10, "completion_tokens": 3, "total_tokens": 13},
+			}
+		})
+
+		m := NewOpenAIModel(server.URL, "qwen3")
+		resp := generate(t, m, &model.LLMRequest{
+			Contents: []*genai.Content{genai.NewContentFromText("hello", genai.RoleUser)},
+			Config: &genai.GenerateContentConfig{
+				SystemInstruction: genai.NewContentFromText("be brief", genai.RoleUser),
+			},
+		})
+
+		if resp.Content.Parts[0].Text != "hi there" {
+			t.Errorf("text = %q, want %q", resp.Content.Parts[0].Text, "hi there")
+		}
+		if resp.FinishReason != genai.FinishReasonStop {
+			t.Errorf("FinishReason = %q, want STOP", resp.FinishReason)
+		}
+		if resp.UsageMetadata == nil || resp.UsageMetadata.TotalTokenCount != 13 {
+			t.Errorf("UsageMetadata = %+v, want 13 total tokens", resp.UsageMetadata)
+		}
+	})
+
+	t.Run("tool call round trip", func(t *testing.T) {
+		t.Parallel()
+		server := chatServer(t, func(req chatRequest) map[string]any {
+			if len(req.Tools) != 1 {
+				t.Errorf("tools = %d, want 1", len(req.Tools))
+			} else {
+				fn := req.Tools[0].Function
+				params, _ := fn.Parameters.(map[string]any)
+				if fn.Name != "discover" || params["type"] != "object" {
+					t.Errorf("unexpected tool: %+v", fn)
+				}
+			}
+
+			if n := len(req.Messages); n != 3 {
+				t.Errorf("messages = %d, want 3", n)
+			} else {
+				assistant, tool := req.Messages[1], req.Messages[2]
+				if assistant.Role != "assistant" || len(assistant.ToolCalls) != 1 || assistant.ToolCalls[0].ID != "call-1" {
+					t.Errorf("unexpected assistant message: %+v", assistant)
+				}
+				if tool.Role != "tool" || tool.ToolCallID != "call-1" || tool.Content != `{"count":2}` {
+					t.Errorf("unexpected tool message: %+v", tool)
+				}
+			}
+
+			return map[string]any{
+				"choices": []map[string]any{{
+					"message": map[string]any{
+						"role": "assistant",
+						"tool_calls": []map[string]any{{
+							"id":       "call-2",
+							"type":     "function",
+							"function": map[string]any{"name": "route", "arguments": `{"query":"weather"}`},
+						}},
+					},
+					"finish_reason": "tool_calls",
+				}},
+			}
+		})
+
+		m := NewOpenAIModel(server.URL, "qwen3")
+		resp := generate(t, m, &model.LLMRequest{
+			Contents: []*genai.Content{
+				genai.NewContentFromText("find agents", genai.RoleUser),
+				{Role: genai.RoleModel, Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{
+					ID: "call-1", Name: "discover", Args: map[string]any{"query": "weather"},
+				}}}},
+				{Role: genai.RoleUser, Parts: []*genai.Part{{FunctionResponse: &genai.FunctionResponse{
+					ID: "call-1", Name: "discover", Response: map[string]any{"count": 2},
+				}}}},
+			},
+			Config: &genai.GenerateContentConfig{
+				Tools: []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{{
+					Name:        "discover",
+					Description: "Find agents",
+					Parameters: &genai.Schema{
+						Type:       genai.TypeObject,
+						Properties: map[string]*genai.Schema{"query": {Type: genai.TypeString}},
+						Required:   []string{"query"},
+					},
+				}}}},
+			},
+		})
+
+		call := resp.Content.Parts[0].FunctionCall
+		if call == nil || call.ID != "call-2" || call.Name != "route" || call.Args["query"] != "weather" {
+			t.Errorf("FunctionCall = %+v, want route(query=weather)", call)
+		}
+	})
+
+	t.Run("api key is sent as bearer token", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+				t.Errorf("Authorization = %q, want %q", got, "Bearer secret")
+			}
+			_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`))
+		}))
+		defer server.Close()
+
+		m := NewOpenAIModel(server.URL, "gpt-4o-mini", WithAPIKey("secret"))
+		generate(t, m, &model.LLMRequest{Contents: []*genai.Content{genai.NewContentFromText("hi", genai.RoleUser)}})
+	})
+
+	t.Run("server error returns error", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, "model not loaded", http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		m := NewOpenAIModel(server.URL, "qwen3")
+		for _, err := range m.GenerateContent(context.Background(), &model.LLMRequest{}, false) {
+			if err == nil {
+				t.Error("GenerateContent() expected error for 503 response")
+			}
+		}
+	})
+}
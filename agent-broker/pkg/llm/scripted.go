@@ -0,0 +1,127 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"sync"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/genai"
+)
+
+// ErrScriptExhausted is returned when a ScriptedModel is called after its last turn.
+var ErrScriptExhausted = errors.New("script exhausted")
+
+// Turn is one scripted model response.
+type Turn struct {
+	// Text is the text of the response.
+	Text string
+	// Calls are the tool calls of the response, made in order.
+	Calls []ToolCall
+}
+
+// ToolCall is a scripted call of a tool.
+type ToolCall struct {
+	// Name is the tool name.
+	Name string
+	// Args are the tool arguments.
+	Args map[string]any
+}
+
+// TextTurn returns a turn that replies with text.
+func TextTurn(text string) Turn {
+	return Turn{Text: text}
+}
+
+// CallTurn returns a turn that calls a single tool.
+func CallTurn(name string, args map[string]any) Turn {
+	return Turn{Calls: []ToolCall{{Name: name, Args: args}}}
+}
+
+// ScriptedModel is a model.LLM that replays a fixed sequence of turns, one per
+// call, regardless of the request. It records every request so tests can
+// inspect the prompts and tool results the agent sent.
+type ScriptedModel struct {
+	// mu guards turns, next and requests.
+	mu sync.Mutex
+	// turns is the script.
+	turns []Turn
+	// next is the index of the next turn to replay.
+	next int
+	// requests holds the requests received so far.
+	requests []*model.LLMRequest
+}
+
+// NewScriptedModel creates a model that replays turns in order.
+func NewScriptedModel(turns ...Turn) *ScriptedModel {
+	return &ScriptedModel{turns: turns}
+}
+
+// Name returns the model name.
+func (m *ScriptedModel) Name() string {
+	return "scripted"
+}
+
+// GenerateContent records req and yields the next turn of the script.
+func (m *ScriptedModel) GenerateContent(_ context.Context, req *model.LLMRequest, _ bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		turn, index, err := m.advance(req)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		yield(turnResponse(turn, index), nil)
+	}
+}
+
+// Requests returns the requests received so far.
+func (m *ScriptedModel) Requests() []*model.LLMRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*model.LLMRequest(nil), m.requests...)
+}
+
+// Remaining returns the number of turns not replayed yet.
+func (m *ScriptedModel) Remaining() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.turns) - m.next
+}
+
+// advance records req and returns the next turn with its index.
+func (m *ScriptedModel) advance(req *model.LLMRequest) (Turn, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests = append(m.requests, req)
+	if m.next >= len(m.turns) {
+		return Turn{}, 0, fmt.Errorf("%w after %d turns", ErrScriptExhausted, len(m.turns))
+	}
+	turn := m.turns[m.next]
+	m.next++
+	return turn, m.next - 1, nil
+}
+
+// turnResponse converts a turn into a complete model response. Tool calls get
+// IDs derived from the turn index so they are stable across runs.
+func turnResponse(turn Turn, index int) *model.LLMResponse {
+	var parts []*genai.Part
+	if turn.Text != "" {
+		parts = append(parts, genai.NewPartFromText(turn.Text))
+	}
+	for i, call := range turn.Calls {
+		parts = append(parts, &genai.Part{FunctionCall: &genai.FunctionCall{
+			ID:   fmt.Sprintf("call-%d-%d", index, i),
+			Name: call.Name,
+			Args: call.Args,
+		}})
+	}
+
+	return &model.LLMResponse{
+		Content:      &genai.Content{Role: genai.RoleModel, Parts: parts},
+		FinishReason: genai.FinishReasonStop,
+		TurnComplete: true,
+	}
+}
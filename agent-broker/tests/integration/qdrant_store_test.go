@@ -2,8 +2,11 @@ package integration_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -11,6 +14,8 @@ import (
 	"github.com/google/uuid"
 	"github.com/joho/godotenv"
 	"github.com/lunarr-ai/lunarr/agent-broker/internal/store"
+	"github.com/lunarr-ai/lunarr/agent-broker/internal/store/storetest"
+	"github.com/qdrant/go-client/qdrant"
 )
 
 var testHost string
@@ -34,7 +39,7 @@ func setupStore(t *testing.T) *store.QdrantStore {
 	s, err := store.NewQdrantStore(ctx,
 		store.WithHost(testHost),
 		store.WithCollectionName(collectionName),
-		store.WithVectorDimension(4),
+		store.WithVectorDimension(storetest.VectorDimension),
 	)
 	if err != nil {
 		t.Fatalf("failed to create QdrantStore: %v", err)
@@ -71,6 +76,13 @@ func validAgent(id string) *store.RegisteredAgent {
 	}
 }
 
+func TestQdrantStore_Conformance(t *testing.T) {
+	t.Parallel()
+	storetest.Run(t, func(t *testing.T) store.Store {
+		return setupStore(t)
+	})
+}
+
 func TestQdrantStore_CreateAgent(t *testing.T) {
 	t.Parallel()
 
@@ -98,6 +110,144 @@ func TestQdrantStore_CreateAgent(t *testing.T) {
 			t.Errorf("CreateAgent() error = %v, want ErrAlreadyExists", err)
 		}
 	})
+
+	t.Run("does not keep the create token", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		collectionName := "test_" + uuid.New().String()[:8]
+		s, err := store.NewQdrantStore(ctx,
+			store.WithHost(testHost),
+			store.WithCollectionName(collectionName),
+			store.WithVectorDimension(storetest.VectorDimension),
+		)
+		if err != nil {
+			t.Fatalf("failed to create QdrantStore: %v", err)
+		}
+		t.Cleanup(func() { _ = s.Close() })
+		client, err := qdrant.NewClient(&qdrant.Config{Host: testHost})
+		if err != nil {
+			t.Fatalf("failed to create qdrant client: %v", err)
+		}
+		t.Cleanup(func() { _ = client.Close() })
+
+		if err := s.CreateAgent(ctx, validAgent("agent-1")); err != nil {
+			t.Fatalf("CreateAgent() error = %v", err)
+		}
+		points, err := client.Scroll(ctx, &qdrant.ScrollPoints{
+			CollectionName: collectionName,
+			WithPayload:    qdrant.NewWithPayload(true),
+		})
+		if err != nil || len(points) != 1 {
+			t.Fatalf("Scroll() = %d points, %v, want 1 point", len(points), err)
+		}
+
+		if _, ok := points[0].Payload["create_token"]; ok {
+			t.Error("payload has create_token, want it removed")
+		}
+	})
+}
+
+func TestQdrantStore_ConcurrentCreate(t *testing.T) {
+	t.Parallel()
+	s := setupStore(t)
+	ctx := context.Background()
+
+	var created, duplicates atomic.Int32
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			switch err := s.CreateAgent(ctx, validAgent("agent-1")); {
+			case err == nil:
+				created.Add(1)
+			case errors.Is(err, store.ErrAlreadyExists):
+				duplicates.Add(1)
+			default:
+				t.Errorf("CreateAgent() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if created.Load() != 1 || duplicates.Load() != 7 {
+		t.Errorf("created = %d, duplicates = %d, want 1 and 7", created.Load(), duplicates.Load())
+	}
+}
+
+func TestQdrantStore_MigratesLegacyPointIDs(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	collectionName := "test_" + uuid.New().String()[:8]
+	open := func() *store.QdrantStore {
+		s, err := store.NewQdrantStore(ctx,
+			store.WithHost(testHost),
+			store.WithCollectionName(collectionName),
+			store.WithVectorDimension(storetest.VectorDimension),
+		)
+		if err != nil {
+			t.Fatalf("failed to create QdrantStore: %v", err)
+		}
+		t.Cleanup(func() { _ = s.Close() })
+		return s
+	}
+
+	client, err := qdrant.NewClient(&qdrant.Config{Host: testHost})
+	if err != nil {
+		t.Fatalf("failed to create qdrant client: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	// Move the stored point to a random ID, as earlier versions assigned them.
+	if err := open().CreateAgent(ctx, validAgent("agent-1")); err != nil {
+		t.Fatalf("CreateAgent() error = %v", err)
+	}
+	points, err := client.Scroll(ctx, &qdrant.ScrollPoints{
+		CollectionName: collectionName,
+		WithPayload:    qdrant.NewWithPayload(true),
+		WithVectors:    qdrant.NewWithVectors(true),
+	})
+	if err != nil || len(points) != 1 {
+		t.Fatalf("Scroll() = %d points, error = %v, want 1 point", len(points), err)
+	}
+	legacyID := qdrant.NewID(uuid.NewString())
+	_, err = client.Upsert(ctx, &qdrant.UpsertPoints{
+		CollectionName: collectionName,
+		Wait:           qdrant.PtrOf(true),
+		Points: []*qdrant.PointStruct{{
+			Id:      legacyID,
+			Vectors: qdrant.NewVectorsDense(points[0].Vectors.GetVectors().GetVectors()[""].GetDense().GetData()),
+			Payload: points[0].Payload,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+	_, err = client.Delete(ctx, &qdrant.DeletePoints{
+		CollectionName: collectionName,
+		Wait:           qdrant.PtrOf(true),
+		Points:         qdrant.NewPointsSelector(points[0].Id),
+	})
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	s := open()
+
+	agent, err := s.GetAgent(ctx, "agent-1")
+	if err != nil {
+		t.Fatalf("GetAgent() after migration error = %v", err)
+	}
+	if agent.Card.Name != validAgentCard().Name || len(agent.Embedding) != storetest.VectorDimension {
+		t.Errorf("GetAgent() = %+v, want card and embedding preserved", agent)
+	}
+	legacy, err := client.Get(ctx, &qdrant.GetPoints{CollectionName: collectionName, Ids: []*qdrant.PointId{legacyID}})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(legacy) != 0 {
+		t.Error("legacy point should be deleted after migration")
+	}
 }
 
 func TestQdrantStore_GetAgent(t *testing.T) {
@@ -233,6 +383,33 @@ func TestQdrantStore_ListAgents(t *testing.T) {
 	})
 }
 
+func TestQdrantStore_CountAgents(t *testing.T) {
+	t.Parallel()
+	s := setupStore(t)
+	ctx := context.Background()
+
+	agent1 := validAgent("agent-1")
+	agent1.Tags = []string{"prod", "ml"}
+	agent2 := validAgent("agent-2")
+	agent2.Tags = []string{"prod"}
+	for _, agent := range []*store.RegisteredAgent{agent1, agent2} {
+		if err := s.CreateAgent(ctx, agent); err != nil {
+			t.Fatalf("CreateAgent(%s) error = %v", agent.ID, err)
+		}
+	}
+
+	total, byTag, err := s.CountAgents(ctx)
+	if err != nil {
+		t.Fatalf("CountAgents() error = %v", err)
+	}
+	if total != 2 {
+		t.Errorf("total = %d, want 2", total)
+	}
+	if byTag["prod"] != 2 || byTag["ml"] != 1 || len(byTag) != 2 {
+		t.Errorf("byTag = %v, want map[ml:1 prod:2]", byTag)
+	}
+}
+
 func TestQdrantStore_UpdateAgent(t *testing.T) {
 	t.Parallel()
 
@@ -438,3 +615,272 @@ func TestQdrantStore_SearchAgents(t *testing.T) {
 		}
 	})
 }
+
+func TestQdrantStore_SetAgentHealth(t *testing.T) {
+	t.Parallel()
+
+	t.Run("records health and keeps embedding", func(t *testing.T) {
+		t.Parallel()
+		s := setupStore(t)
+		ctx := context.Background()
+		_ = s.CreateAgent(ctx, validAgent("agent-1"))
+
+		now := time.Now().Truncate(time.Second)
+		err := s.SetAgentHealth(ctx, "agent-1", store.AgentHealth{
+			Status:              store.HealthUnhealthy,
+			LastChecked:         now,
+			ConsecutiveFailures: 3,
+			LastError:           "connection refused",
+		})
+		if err != nil {
+			t.Fatalf("SetAgentHealth() error = %v", err)
+		}
+
+		got, _ := s.GetAgent(ctx, "agent-1")
+		if got.Health.Status != store.HealthUnhealthy {
+			t.Errorf("Health.Status = %v, want unhealthy", got.Health.Status)
+		}
+		if got.Health.ConsecutiveFailures != 3 {
+			t.Errorf("Health.ConsecutiveFailures = %d, want 3", got.Health.ConsecutiveFailures)
+		}
+		if !got.Health.LastChecked.Equal(now) {
+			t.Errorf("Health.LastChecked = %v, want %v", got.Health.LastChecked, now)
+		}
+
+		query := []float32{0.1, 0.2, 0.3, 0.4}
+		result, _ := s.SearchAgents(ctx, query, 10, store.AgentFilter{})
+		if len(result.Agents) != 1 {
+			t.Fatalf("SearchAgents() got %d agents, want 1", len(result.Agents))
+		}
+		excluded, _ := s.SearchAgents(ctx, query, 10, store.AgentFilter{ExcludeUnhealthy: true})
+		if len(excluded.Agents) != 0 {
+			t.Errorf("SearchAgents(ExcludeUnhealthy) got %d agents, want 0", len(excluded.Agents))
+		}
+	})
+
+	t.Run("non-existent returns ErrNotFound", func(t *testing.T) {
+		t.Parallel()
+		s := setupStore(t)
+
+		err := s.SetAgentHealth(context.Background(), "not-exists", store.AgentHealth{})
+		if err != store.ErrNotFound {
+			t.Errorf("SetAgentHealth() error = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestQdrantStore_DeleteExpiredAgents(t *testing.T) {
+	t.Parallel()
+	s := setupStore(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	expired := validAgent("expired")
+	expired.LeaseTTL = time.Minute
+	expired.ExpiresAt = now.Add(-time.Minute)
+	renewed := validAgent("renewed")
+	renewed.LeaseTTL = time.Minute
+	renewed.ExpiresAt = now.Add(-time.Minute)
+	_ = s.CreateAgent(ctx, expired)
+	_ = s.CreateAgent(ctx, renewed)
+	_ = s.CreateAgent(ctx, validAgent("permanent"))
+
+	if err := s.RenewAgentLease(ctx, "renewed", now.Add(time.Minute)); err != nil {
+		t.Fatalf("RenewAgentLease() error = %v", err)
+	}
+
+	ids, err := s.DeleteExpiredAgents(ctx, now)
+	if err != nil {
+		t.Fatalf("DeleteExpiredAgents() error = %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "expired" {
+		t.Errorf("DeleteExpiredAgents() = %v, want [expired]", ids)
+	}
+	if _, err := s.GetAgent(ctx, "expired"); err != store.ErrNotFound {
+		t.Errorf("GetAgent(expired) error = %v, want ErrNotFound", err)
+	}
+	got, err := s.GetAgent(ctx, "renewed")
+	if err != nil {
+		t.Fatalf("GetAgent(renewed) error = %v", err)
+	}
+	if got.LeaseTTL != time.Minute {
+		t.Errorf("LeaseTTL = %v, want %v", got.LeaseTTL, time.Minute)
+	}
+}
+
+func TestQdrantStore_EmbeddingMismatch(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	collectionName := "test_" + uuid.New().String()[:8]
+	open := func(dim uint64, model string) (*store.QdrantStore, error) {
+		return store.NewQdrantStore(ctx,
+			store.WithHost(testHost),
+			store.WithCollectionName(collectionName),
+			store.WithVectorDimension(dim),
+			store.WithEmbeddingModel(model),
+		)
+	}
+
+	s, err := open(storetest.VectorDimension, "mini")
+	if err != nil {
+		t.Fatalf("failed to create QdrantStore: %v", err)
+	}
+	_ = s.Close()
+
+	tests := []struct {
+		name         string
+		dim          uint64
+		model        string
+		wantMismatch bool
+	}{
+		{name: "same model and dimension", dim: storetest.VectorDimension, model: "mini"},
+		{name: "unconfigured model", dim: storetest.VectorDimension},
+		{name: "different dimension", dim: storetest.VectorDimension + 1, model: "mini", wantMismatch: true},
+		{name: "different model", dim: storetest.VectorDimension, model: "large", wantMismatch: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reopened, err := open(tt.dim, tt.model)
+			if err == nil {
+				_ = reopened.Close()
+			}
+			if got := errors.Is(err, store.ErrEmbeddingMismatch); got != tt.wantMismatch {
+				t.Errorf("NewQdrantStore() error = %v, want ErrEmbeddingMismatch %v", err, tt.wantMismatch)
+			}
+		})
+	}
+}
+
+func TestQdrantStore_Reindex(t *testing.T) {
+	t.Parallel()
+	s := setupStore(t)
+	ctx := context.Background()
+	_ = s.CreateAgent(ctx, validAgent("agent-1"))
+
+	target, err := s.ReindexTarget(ctx, "large", 6)
+	if err != nil {
+		t.Fatalf("ReindexTarget() error = %v", err)
+	}
+	if err := target.SaveCheckpoint(ctx, "cursor-1"); err != nil {
+		t.Fatalf("SaveCheckpoint() error = %v", err)
+	}
+
+	resumed, err := s.ReindexTarget(ctx, "large", 6)
+	if err != nil {
+		t.Fatalf("ReindexTarget() resume error = %v", err)
+	}
+	if resumed.Name() != target.Name() {
+		t.Errorf("resumed target = %s, want %s", resumed.Name(), target.Name())
+	}
+	if cursor, _ := resumed.Checkpoint(ctx); cursor != "cursor-1" {
+		t.Errorf("Checkpoint() = %q, want cursor-1", cursor)
+	}
+
+	agent := validAgent("agent-1")
+	agent.Embedding = []float32{0.1, 0.2, 0.3, 0.4, 0.5, 0.6}
+	if err := resumed.CreateAgent(ctx, agent); err != nil {
+		t.Fatalf("CreateAgent() on target error = %v", err)
+	}
+
+	previous, err := s.SwitchIndex(ctx, resumed)
+	if err != nil {
+		t.Fatalf("SwitchIndex() error = %v", err)
+	}
+	if previous == "" || previous == target.Name() {
+		t.Errorf("SwitchIndex() previous = %q, want the replaced collection", previous)
+	}
+
+	result, err := s.SearchAgents(ctx, agent.Embedding, 10, store.AgentFilter{})
+	if err != nil {
+		t.Fatalf("SearchAgents() after switch error = %v", err)
+	}
+	if len(result.Agents) != 1 || result.Agents[0].Agent.ID != "agent-1" {
+		t.Errorf("SearchAgents() after switch = %d agents, want agent-1", len(result.Agents))
+	}
+	if cursor, _ := resumed.Checkpoint(ctx); cursor != "" {
+		t.Errorf("Checkpoint() after switch = %q, want empty", cursor)
+	}
+}
+
+func TestQdrantStore_ReindexLegacyCollection(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	collectionName := "test_" + uuid.New().String()[:8]
+
+	client, err := qdrant.NewClient(&qdrant.Config{Host: testHost})
+	if err != nil {
+		t.Fatalf("failed to create qdrant client: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	// Collections created before aliases are served under their own name.
+	err = client.CreateCollection(ctx, &qdrant.CreateCollection{
+		CollectionName: collectionName,
+		VectorsConfig: qdrant.NewVectorsConfig(&qdrant.VectorParams{
+			Size:     storetest.VectorDimension,
+			Distance: qdrant.Distance_Cosine,
+		}),
+	})
+	if err != nil {
+		t.Fatalf("CreateCollection() error = %v", err)
+	}
+	s, err := store.NewQdrantStore(ctx,
+		store.WithHost(testHost),
+		store.WithCollectionName(collectionName),
+		store.WithVectorDimension(storetest.VectorDimension),
+	)
+	if err != nil {
+		t.Fatalf("failed to create QdrantStore: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	for _, id := range []string{"agent-1", "agent-2"} {
+		if err := s.CreateAgent(ctx, validAgent(id)); err != nil {
+			t.Fatalf("CreateAgent(%s) error = %v", id, err)
+		}
+	}
+
+	target, err := s.ReindexTarget(ctx, "large", 6)
+	if err != nil {
+		t.Fatalf("ReindexTarget() error = %v", err)
+	}
+	reindexed := func(id string) *store.RegisteredAgent {
+		agent := validAgent(id)
+		agent.Embedding = []float32{0.1, 0.2, 0.3, 0.4, 0.5, 0.6}
+		return agent
+	}
+	if err := target.CreateAgent(ctx, reindexed("agent-1")); err != nil {
+		t.Fatalf("CreateAgent() on target error = %v", err)
+	}
+
+	if _, err := s.SwitchIndex(ctx, target); !errors.Is(err, store.ErrIncompleteReindex) {
+		t.Fatalf("SwitchIndex() with incomplete target error = %v, want ErrIncompleteReindex", err)
+	}
+	if _, err := s.GetAgent(ctx, "agent-2"); err != nil {
+		t.Fatalf("GetAgent() after refused switch error = %v, want legacy collection served", err)
+	}
+
+	if err := target.CreateAgent(ctx, reindexed("agent-2")); err != nil {
+		t.Fatalf("CreateAgent() on target error = %v", err)
+	}
+	backup, err := s.SwitchIndex(ctx, target)
+	if err != nil {
+		t.Fatalf("SwitchIndex() error = %v", err)
+	}
+	if backup == "" || backup == collectionName || backup == target.Name() {
+		t.Fatalf("SwitchIndex() previous = %q, want a backup of the legacy collection", backup)
+	}
+
+	count, err := client.Count(ctx, &qdrant.CountPoints{CollectionName: backup, Exact: qdrant.PtrOf(true)})
+	if err != nil || count != 2 {
+		t.Errorf("backup Count() = %d, error = %v, want 2", count, err)
+	}
+	result, err := s.SearchAgents(ctx, reindexed("agent-1").Embedding, 10, store.AgentFilter{})
+	if err != nil {
+		t.Fatalf("SearchAgents() after switch error = %v", err)
+	}
+	if len(result.Agents) != 2 {
+		t.Errorf("SearchAgents() after switch = %d agents, want 2", len(result.Agents))
+	}
+}